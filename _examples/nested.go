@@ -81,13 +81,13 @@ func main() {
 
 	grp, _ := parallel.NewGroup(parallel.OrderRunners(opts.keepOrder))
 	grp.Add("", "", func(out, err io.Writer) {
-		recurs("", 0, 0, out, err)
+		recurs(grp, "", 0, 0, out, err)
 	})
 	grp.Run()
 	grp.Wait()
 }
 
-func recurs(prefix string, depth, widthIndex int, stdout, stderr io.Writer) {
+func recurs(parent *parallel.Group, prefix string, depth, widthIndex int, stdout, stderr io.Writer) {
 	prefix += fmt.Sprintf("%d.", widthIndex)
 	if depth == opts.depth {
 		time.Sleep(time.Millisecond * time.Duration(rand.Intn(250))) //  Upto 1/4s
@@ -95,12 +95,11 @@ func recurs(prefix string, depth, widthIndex int, stdout, stderr io.Writer) {
 		return
 	}
 
-	grp, _ := parallel.NewGroup(parallel.OrderRunners(opts.keepOrder),
-		parallel.WithStdout(stdout), parallel.WithStderr(stderr))
+	grp, _ := parent.NewSubGroup(stdout, stderr) // Inherits OrderRunners
 	for ix := 0; ix < opts.width; ix++ {
 		ix := ix // Pre 1.22 semantics
 		grp.Add("", "", func(out, err io.Writer) {
-			recurs(prefix, depth+1, ix, out, err)
+			recurs(grp, prefix, depth+1, ix, out, err)
 		})
 	}
 	grp.Run()
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/markdingo/parallel"
+	"github.com/markdingo/parallel/parallelcli"
 )
 
 // A vastly simplified version of the GNU parallel program which demonstrate the use of
@@ -17,10 +18,7 @@ import (
 // go build para.go
 // ./para -k wc -l ::: *.go
 
-const (
-	programName = "para"
-	magic       = ":::"
-)
+const programName = "para"
 
 type Opts struct {
 	help      bool   // -h Print usage and exit
@@ -43,7 +41,7 @@ func usage() {
 	fmt.Fprintln(os.Stderr, programName, "- execute shell command with arguments in parallel")
 	fmt.Fprintln(os.Stderr)
 	fmt.Fprintln(os.Stderr, "Usage:", programName,
-		"[-h] [gkt] [-s sep] shell-command [options] ::: arguments")
+		"[-h] [gkt] [-s sep] shell-command [options] ::: arguments [:::+ arguments] ...")
 	flag.PrintDefaults()
 	fmt.Fprintln(os.Stderr, `
 Example:
@@ -65,37 +63,14 @@ func main() {
 		return
 	}
 
-	args := flag.Args()
-	if len(args) == 0 {
-		fatal("Need to provide a 'shell-command' on the command line")
-	}
-	opts.command = append(opts.command, args[0])
-
-	args = args[1:]
-
-	// Search for our ":::" magic token. Anything prior to ":::" is part of the
-	// command and anything subsequent are args for each RunFunc.
-
-	magicSeen := -1
-	for ix, a := range args {
-		if a == magic {
-			magicSeen = ix
-			break
-		}
-	}
-	if magicSeen == -1 {
-		fatal("':::' delimiter not found after 'shell-command'")
-	}
-
-	// a0 a1 a2 ::: a4 a5
-
-	if magicSeen > 0 {
-		opts.command = append(opts.command, args[0:magicSeen]...)
+	inv, err := parallelcli.Parse(flag.Args())
+	if err != nil {
+		fatal(err.Error())
 	}
-	if magicSeen == len(args)-1 {
-		fatal("Need to provide at least one argument after ':::' delimiter")
+	if len(inv.Command) == 0 {
+		fatal("Need to provide a 'shell-command' on the command line")
 	}
-	args = args[magicSeen+1:]
+	opts.command = inv.Command
 
 	if len(opts.sep) > 0 {
 		opts.sep = opts.sep + "\n"
@@ -108,15 +83,14 @@ func main() {
 	// This usage of Group.Add uses a closure pass additional parameters to
 	// runCommand()
 
-	for _, a := range args {
-		a := a // Pre 1.22 semantics
+	tpl := parallelcli.Template(opts.command)
+	for ix, job := range inv.Jobs {
 		gt := ""
 		if opts.tag {
-			gt = a + "\t"
+			gt = strings.Join(job, " ") + "\t"
 		}
 		grp.Add(gt, gt, func(out, err io.Writer) {
-			cmd := append(opts.command, a)
-			runCommand(cmd, out, err)
+			runCommand(tpl.Expand(ix+1, job...), out, err)
 		})
 	}
 
@@ -0,0 +1,99 @@
+package parallel
+
+import (
+	"sync"
+)
+
+// ansiState is the position of an ansiStripper within an escape sequence.
+type ansiState int
+
+const (
+	ansiText      ansiState = iota // Not in an escape sequence
+	ansiEsc                        // After ESC
+	ansiEscInter                   // After ESC and intermediate bytes
+	ansiCSI                        // After ESC [
+	ansiString                     // After ESC ], P, X, ^ or _ until BEL or ST
+	ansiStringEsc                  // After ESC within a string, possibly the start of ST
+)
+
+const (
+	ansiESC = 0x1b
+	ansiBEL = 0x07
+)
+
+// ansiStripper is a writer which removes ANSI escape sequences, such as colour and cursor
+// movement, from RunFunc output. As the sequence state is retained between Writes, a
+// sequence split across Writes is still removed.
+type ansiStripper struct {
+	mu sync.Mutex
+	commonWriter
+	state ansiState
+	text  []byte // Reused to accumulate the text of each Write
+}
+
+func newANSIStripper(out writer) *ansiStripper {
+	wtr := &ansiStripper{}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+// Write returns the length of p on success as escape sequences are consumed even tho they
+// are not written downstream.
+func (wtr *ansiStripper) Write(p []byte) (n int, err error) {
+	wtr.mu.Lock()
+	defer wtr.mu.Unlock()
+
+	text := wtr.text[:0]
+	for _, b := range p {
+		switch wtr.state {
+		case ansiText:
+			if b == ansiESC {
+				wtr.state = ansiEsc
+			} else {
+				text = append(text, b)
+			}
+		case ansiEsc:
+			switch {
+			case b == '[':
+				wtr.state = ansiCSI
+			case b == ']' || b == 'P' || b == 'X' || b == '^' || b == '_':
+				wtr.state = ansiString
+			case b >= 0x20 && b <= 0x2f:
+				wtr.state = ansiEscInter
+			default: // Final byte of a two byte sequence
+				wtr.state = ansiText
+			}
+		case ansiEscInter:
+			if b < 0x20 || b > 0x2f {
+				wtr.state = ansiText
+			}
+		case ansiCSI:
+			if b >= 0x40 && b <= 0x7e {
+				wtr.state = ansiText
+			}
+		case ansiString:
+			if b == ansiBEL {
+				wtr.state = ansiText
+			} else if b == ansiESC {
+				wtr.state = ansiStringEsc
+			}
+		case ansiStringEsc:
+			if b == '\\' {
+				wtr.state = ansiText
+			} else if b != ansiESC {
+				wtr.state = ansiString
+			}
+		}
+	}
+	wtr.text = text
+	if len(text) > 0 {
+		_, err = wtr.out.Write(text)
+	}
+
+	return len(p), err
+}
+
+func (wtr *ansiStripper) close() {
+	wtr.out.close() // Pass it on. Any incomplete sequence is discarded.
+}
@@ -0,0 +1,39 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestStripANSI(t *testing.T) {
+	testCases := []struct {
+		writes []string
+		exp    string
+	}{
+		{[]string{"plain\n"}, "plain\n"},
+		{[]string{"\x1b[1;31mred\x1b[0m\n"}, "red\n"},
+		{[]string{"a\x1b[", "2", "Kb\x1b", "[Hc\n"}, "abc\n"},       // Split CSI
+		{[]string{"\x1b]0;title\x07x\x1b]8;;url\x1b\\y\n"}, "xy\n"}, // OSC with BEL and ST
+		{[]string{"\x1b(Bz\x1b7\x1b8\n"}, "z\n"},                    // Charset and save/restore
+		{[]string{"end\x1b[1"}, "end"},                              // Incomplete at close
+	}
+
+	for ix, tc := range testCases {
+		var stdout bytes.Buffer
+		grp, err := NewGroup(WithStdout(&stdout), StripANSI(true))
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		grp.Add("", "", func(out, err io.Writer) {
+			for _, w := range tc.writes {
+				out.Write([]byte(w))
+			}
+		})
+		grp.Run()
+		grp.Wait()
+		if got := stdout.String(); got != tc.exp {
+			t.Errorf("%d: Got %q Expected %q", ix, got, tc.exp)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package parallel
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+var errAsyncClosed = errors.New("parallel: asynchronous output closed")
+
+// asyncItem is a copy of the data of a single Write destined for out.
+type asyncItem struct {
+	out   io.Writer
+	data  []byte
+	flush httpFlusher // If set, flush rather than write
+}
+
+// asyncOutput implements WithAsyncOutput. Writes to each asyncWriter are copied and
+// handed to a single goroutine via a bounded channel so that the writing pipeline, which
+// holds the Group output mutex, only waits for a slow destination once the channel is
+// full. As there is a single channel for all destinations, the relative order of all
+// writes is preserved.
+//
+// A destination error is sticky. It is returned by all subsequent Writes and by finish.
+type asyncOutput struct {
+	items  chan asyncItem
+	done   chan struct{}         // Closed by the writing goroutine on exit
+	err    atomic.Pointer[error] // First destination error
+	mu     sync.RWMutex          // Held by senders and by finish while closing items
+	closed bool                  // No more Writes are accepted
+}
+
+func newAsyncOutput(depth int) *asyncOutput {
+	return &asyncOutput{items: make(chan asyncItem, depth), done: make(chan struct{})}
+}
+
+// run writes each item to its destination until items is closed. Once an error is
+// detected, all remaining items are discarded.
+func (ao *asyncOutput) run() {
+	defer close(ao.done)
+	for it := range ao.items {
+		if it.flush != nil {
+			if ao.error() == nil {
+				it.flush.Flush()
+			}
+			continue
+		}
+		if ao.error() == nil {
+			if _, err := it.out.Write(it.data); err != nil {
+				ao.err.Store(&err)
+			}
+		}
+		putBuf(it.data)
+	}
+}
+
+// finish waits for all queued writes to complete and returns the first destination
+// error, if any. Subsequent Writes return an error.
+func (ao *asyncOutput) finish() error {
+	ao.mu.Lock()
+	if !ao.closed {
+		ao.closed = true
+		close(ao.items)
+	}
+	ao.mu.Unlock()
+	<-ao.done
+
+	return ao.error()
+}
+
+func (ao *asyncOutput) error() error {
+	if p := ao.err.Load(); p != nil {
+		return *p
+	}
+
+	return nil
+}
+
+// send queues a copy of the concatenation of bufs for out, blocking if the channel is
+// full.
+func (ao *asyncOutput) send(out io.Writer, bufs ...[]byte) (int64, error) {
+	if err := ao.error(); err != nil {
+		return 0, err
+	}
+	var size int
+	for _, b := range bufs {
+		size += len(b)
+	}
+	data := getBuf(size)[:0]
+	for _, b := range bufs {
+		data = append(data, b...) // Do not retain b
+	}
+
+	ao.mu.RLock()
+	defer ao.mu.RUnlock()
+	if ao.closed {
+		putBuf(data)
+		return 0, errAsyncClosed
+	}
+	ao.items <- asyncItem{out: out, data: data}
+
+	return int64(size), nil
+}
+
+// flush queues a flush of f behind all pending writes. It is ignored once closed.
+func (ao *asyncOutput) flush(f httpFlusher) {
+	ao.mu.RLock()
+	defer ao.mu.RUnlock()
+	if !ao.closed {
+		ao.items <- asyncItem{flush: f}
+	}
+}
+
+// asyncWriter replaces a Group io.Writer with WithAsyncOutput.
+type asyncWriter struct {
+	ao  *asyncOutput
+	out io.Writer
+}
+
+func (aw *asyncWriter) Write(p []byte) (int, error) {
+	n, err := aw.ao.send(aw.out, p)
+
+	return int(n), err
+}
+
+// writeVec queues all bufs as a single write so that the destination still sees one
+// Write per vector.
+func (aw *asyncWriter) writeVec(bufs [][]byte) (int64, error) {
+	return aw.ao.send(aw.out, bufs...)
+}
+
+// asyncWriters replaces the Group io.Writers with asyncWriters for WithAsyncOutput and
+// starts the writing goroutine. Caller must hold grp.mu.
+func (grp *Group) asyncWriters() {
+	if grp.unbuffered == nil { // Otherwise WithStdoutTee has already replaced them
+		grp.unbuffered = grp.writers()
+	}
+	grp.async = newAsyncOutput(grp.asyncDepth)
+	var replaced []io.Writer
+	for _, w := range grp.writers() {
+		replaced = append(replaced, &asyncWriter{ao: grp.async, out: w})
+	}
+	grp.stdout = replaced[0]
+	grp.stderr = replaced[len(replaced)-1]
+	go grp.async.run()
+}
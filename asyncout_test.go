@@ -0,0 +1,66 @@
+package parallel
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// A writer which does not complete any Write until released
+type testGatedWriter struct {
+	testBufWriter
+	gate chan struct{}
+}
+
+func (tgw *testGatedWriter) Write(p []byte) (int, error) {
+	<-tgw.gate
+	return tgw.testBufWriter.Write(p)
+}
+
+// Runner writes should complete while the destination is stalled, up to the depth.
+func TestAsyncOutput(t *testing.T) {
+	stdout := &testGatedWriter{gate: make(chan struct{})}
+	grp, err := NewGroup(WithStdout(stdout), WithAsyncOutput(4))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	wrote := make(chan struct{})
+	grp.Add("a:", "", func(out, err io.Writer) {
+		for _, s := range []string{"1\n", "2\n", "3\n"} {
+			out.Write([]byte(s))
+		}
+		close(wrote)
+	})
+	grp.Add("b:", "", func(out, err io.Writer) { out.Write([]byte("4\n")) })
+	grp.Run()
+	<-wrote // Would deadlock if writes waited for the destination
+	close(stdout.gate)
+	if err := grp.Wait(); err != nil {
+		t.Error("Unexpected error", err)
+	}
+
+	if got := stdout.String(); got != "a:1\na:2\na:3\nb:4\n" {
+		t.Errorf("Wrong output %q", got)
+	}
+}
+
+// A destination error should be sticky and returned by Wait.
+func TestAsyncOutputError(t *testing.T) {
+	ttw := &testTruncateWriter{}
+	ttw.append("fail", 0, errors.New("Destination failed"))
+	grp, err := NewGroup(WithStdout(ttw), WithAsyncOutput(1), WithBufferedOutput(16))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("lost\n")) })
+	grp.Run()
+	err = grp.Wait()
+	if err == nil || !strings.Contains(err.Error(), "Destination failed") {
+		t.Error("Expected destination error from Wait, got", err)
+	}
+
+	if _, err := NewGroup(WithAsyncOutput(0)); err == nil {
+		t.Error("Expected error with zero depth")
+	}
+}
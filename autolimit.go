@@ -0,0 +1,103 @@
+package parallel
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// autoMaxFactor determines the default upper bound of LimitActiveRunnersAuto as a
+// multiple of runtime.NumCPU.
+const autoMaxFactor = 4
+
+// autoLimiter adjusts the number of active runners with a simple hill-climbing
+// algorithm. Each time a window of completions has been observed, the completion rate for
+// that window is compared to the rate of the previous window. If the rate improved, the
+// limit continues to move in the same direction, otherwise it reverses direction. The
+// window is the current limit so that each adjustment is based on roughly one "round" of
+// runners.
+//
+// The feeder acquires a slot prior to passing a runner to the workers and the worker
+// releases the slot once the runner completes. Acquiring in the feeder means runners are
+// still started in creation order.
+type autoLimiter struct {
+	sync.Mutex
+	cond   *sync.Cond
+	limit  int // Current maximum active runners
+	max    int // Upper bound of limit - the lower bound is 1
+	active int // Slots currently acquired
+	step   int // Direction of the next adjustment: +1 or -1
+
+	clock       Clock
+	window      int       // Completions in the current window
+	windowStart time.Time // When the current window started
+	lastRate    float64   // Completions per second of the previous window
+}
+
+func newAutoLimiter(max int, clock Clock) *autoLimiter {
+	al := &autoLimiter{limit: runtime.NumCPU(), max: max, step: 1, clock: clock,
+		windowStart: clock.Now()}
+	al.cond = sync.NewCond(&al.Mutex)
+	if al.limit > al.max {
+		al.limit = al.max
+	}
+
+	return al
+}
+
+// acquire waits until the number of active runners is below the current limit.
+func (al *autoLimiter) acquire() {
+	al.Lock()
+	defer al.Unlock()
+
+	for al.active >= al.limit {
+		al.cond.Wait()
+	}
+	al.active++
+}
+
+// release returns a slot and adjusts the limit if a complete window has been observed.
+func (al *autoLimiter) release() {
+	al.Lock()
+	defer al.Unlock()
+
+	al.active--
+	al.window++
+	if al.window >= al.limit {
+		al.adjust(since(al.clock, al.windowStart))
+		al.window = 0
+		al.windowStart = al.clock.Now()
+	}
+	al.cond.Broadcast() // Limit may have grown so wake everyone
+}
+
+// adjust moves the limit by one step based on the rate observed over the elapsed window.
+// Caller must hold the mutex.
+func (al *autoLimiter) adjust(elapsed time.Duration) {
+	if elapsed <= 0 {
+		elapsed = time.Nanosecond
+	}
+	rate := float64(al.window) / elapsed.Seconds()
+	if rate < al.lastRate {
+		al.step = -al.step
+	}
+	al.lastRate = rate
+
+	al.limit += al.step
+	switch {
+	case al.limit < 1:
+		al.limit = 1
+		al.step = 1
+	case al.limit > al.max:
+		al.limit = al.max
+		al.step = -1
+	}
+}
+
+// currentLimit is a test helper.
+func (al *autoLimiter) currentLimit() int {
+	al.Lock()
+	defer al.Unlock()
+
+	return al.limit
+}
@@ -0,0 +1,88 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAutoLimitAdjust(t *testing.T) {
+	al := newAutoLimiter(3, systemClock{})
+	al.limit = 2
+
+	al.window = 2
+	al.adjust(time.Second) // 2/s beats 0/s so keep going up
+	if al.limit != 3 || al.step != 1 {
+		t.Error("Expected increase to 3, got", al.limit, al.step)
+	}
+
+	al.window = 3
+	al.adjust(time.Second) // 3/s beats 2/s, but max reached so turn around
+	if al.limit != 3 || al.step != -1 {
+		t.Error("Expected clamp at 3 and reversal, got", al.limit, al.step)
+	}
+
+	al.window = 3
+	al.adjust(time.Second / 2) // 6/s beats 3/s so keep going down
+	if al.limit != 2 || al.step != -1 {
+		t.Error("Expected decrease to 2, got", al.limit, al.step)
+	}
+
+	al.window = 2
+	al.adjust(time.Second) // 2/s is worse than 6/s so reverse
+	if al.limit != 3 || al.step != 1 {
+		t.Error("Expected reversal to 3, got", al.limit, al.step)
+	}
+
+	al.limit = 1
+	al.step = -1
+	al.lastRate = 0
+	al.window = 1
+	al.adjust(time.Second)
+	if al.limit != 1 || al.step != 1 {
+		t.Error("Expected clamp at 1, got", al.limit, al.step)
+	}
+}
+
+// Test that active runners never exceed the auto limit bounds.
+func TestAutoLimitGroup(t *testing.T) {
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), LimitActiveRunners(2), LimitActiveRunnersAuto())
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+
+	var active, maxActive atomic.Int32
+	for ix := 0; ix < 20; ix++ {
+		grp.Add("", "", func(out, err io.Writer) {
+			n := active.Add(1)
+			for {
+				m := maxActive.Load()
+				if n <= m || maxActive.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			active.Add(-1)
+		})
+	}
+	grp.Run()
+	grp.Wait()
+
+	if maxActive.Load() > 2 {
+		t.Error("Active runners exceeded upper bound", maxActive.Load())
+	}
+	if limit := grp.auto.currentLimit(); limit < 1 || limit > 2 {
+		t.Error("Auto limit out of range", limit)
+	}
+
+	grp, err = NewGroup(LimitActiveRunnersAuto(), LimitMemoryPerRunner(10))
+	if err != nil {
+		t.Fatal("LimitActiveRunnersAuto should satisfy LimitMemoryPerRunner", err)
+	}
+	if grp.limitRunners == 0 {
+		t.Error("Expected default upper bound to be set")
+	}
+}
@@ -0,0 +1,41 @@
+package parallel
+
+import (
+	"io"
+	"sync"
+)
+
+// newTail constructs a tail which watches for broken pipes on the Group io.Writers, which
+// applies any WithOutputRateLimit and which pays any separators owed by a previous runner.
+func (grp *Group) newTail(out io.Writer, outputMu *sync.Mutex) *tail {
+	wtr := newTail(out, outputMu)
+	wtr.onErr = grp.checkPipe
+	wtr.limit = grp.rateLimit
+	wtr.prelude = grp.paySeparators
+
+	return wtr
+}
+
+// checkPipe aborts the Group if err indicates that a Group io.Writer is a broken pipe, as
+// there is no point running more RunFuncs or buffering output which can never be
+// delivered. Dispatch is stopped so pending runners are skipped with ErrBrokenPipe and the
+// context of active runners is cancelled. checkPipe is called with outputMu held, and
+// possibly grp.mu, so it relies on the cancel functions being concurrency safe.
+func (grp *Group) checkPipe(err error) {
+	if !isBrokenPipe(err) {
+		return
+	}
+	if grp.broken.CompareAndSwap(false, true) {
+		grp.stop(ErrBrokenPipe)
+		grp.cancel(ErrBrokenPipe)
+	}
+}
+
+// pipeErr returns ErrBrokenPipe if a broken pipe has been detected.
+func (grp *Group) pipeErr() error {
+	if grp.broken.Load() {
+		return ErrBrokenPipe
+	}
+
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build !plan9
+
+package parallel
+
+import (
+	"errors"
+	"io"
+	"syscall"
+)
+
+// isBrokenPipe returns true if err indicates that the reader of a pipe has gone away.
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrClosedPipe)
+}
@@ -0,0 +1,12 @@
+package parallel
+
+import (
+	"errors"
+	"io"
+)
+
+// isBrokenPipe returns true if err indicates that the reader of a pipe has gone away. As
+// plan9 has no EPIPE errno, only io.ErrClosedPipe is recognised.
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, io.ErrClosedPipe)
+}
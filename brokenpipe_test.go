@@ -0,0 +1,70 @@
+package parallel
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Once the reader goes away, pending runners should be skipped and active runners
+// cancelled rather than continuing to produce undeliverable output.
+func TestBrokenPipe(t *testing.T) {
+	r, w := io.Pipe()
+	go func() {
+		bufio.NewReader(r).ReadString('\n') // Only want one line, like head -1
+		r.Close()
+	}()
+	grp, err := NewGroup(WithStdout(w), LimitActiveRunners(1))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	var canceled bool
+	grp.AddContext("", "", func(ctx context.Context, out, err io.Writer) {
+		out.Write([]byte("first\n"))
+		if _, e := out.Write([]byte("second\n")); e == nil {
+			t.Error("Expected write to a closed pipe to fail")
+		}
+		<-ctx.Done()
+		canceled = errors.Is(context.Cause(ctx), ErrBrokenPipe)
+	})
+	for range 5 {
+		grp.Add("", "", func(out, err io.Writer) { t.Error("Runner should be skipped") })
+	}
+	grp.Run()
+	err = grp.Wait()
+
+	if !errors.Is(err, ErrBrokenPipe) {
+		t.Error("Wait should return ErrBrokenPipe, not", err)
+	}
+	if !canceled {
+		t.Error("Active runner context not cancelled with ErrBrokenPipe")
+	}
+	if errs := grp.Errors(); !errors.Is(errs[5], ErrBrokenPipe) {
+		t.Error("Pending runner not skipped with ErrBrokenPipe", errs[5])
+	}
+}
+
+// A broken pipe detected while copying with io.Copy also aborts the Group.
+func TestBrokenPipeReadFrom(t *testing.T) {
+	r, w := io.Pipe()
+	r.Close()
+	grp, err := NewGroup(WithStdout(w), LimitActiveRunners(1))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("", "", func(out, err io.Writer) {
+		io.Copy(out, struct{ io.Reader }{strings.NewReader("first\n")})
+	})
+	for range 5 {
+		grp.Add("", "", func(out, err io.Writer) { t.Error("Runner should be skipped") })
+	}
+	grp.Run()
+	err = grp.Wait()
+
+	if !errors.Is(err, ErrBrokenPipe) {
+		t.Error("Wait should return ErrBrokenPipe, not", err)
+	}
+}
@@ -0,0 +1,69 @@
+package parallel
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"sync"
+)
+
+// minSealSize is the smallest chunk worth compressing with WithCompressedBuffering. Below
+// this size the flate overhead outweighs any likely saving.
+const minSealSize = 256
+
+// flateWriters recycles flate compressors as each one allocates a substantial amount of
+// state which would otherwise be allocated for every chunk sealed.
+var flateWriters = sync.Pool{
+	New: func() any {
+		w, _ := flate.NewWriter(nil, flate.BestSpeed) // Only errors on a bad level
+		return w
+	},
+}
+
+// seal compresses the most recent chunk if compression is enabled and the chunk is no
+// longer eligible for coalescing. The chunk is only replaced if compression makes it
+// smaller. The number of bytes saved is added to buf.saved so that the queue can reduce
+// its memory usage accordingly.
+func (buf *chunkBuffer) seal() {
+	last := len(buf.chunks) - 1
+	if !buf.compress || last < 0 {
+		return
+	}
+	b := &buf.chunks[last]
+	if b.spilled || b.compressed || len(b.data) < minSealSize {
+		return
+	}
+
+	var out bytes.Buffer
+	fw := flateWriters.Get().(*flate.Writer)
+	fw.Reset(&out)
+	fw.Write(b.data) // bytes.Buffer writes cannot fail
+	fw.Close()
+	flateWriters.Put(fw)
+	if out.Len() >= len(b.data) { // Incompressible
+		return
+	}
+
+	buf.saved += uint64(len(b.data) - out.Len())
+	putBuf(b.data)
+	b.size = int64(len(b.data))
+	b.data = bytes.Clone(out.Bytes()) // Trim excess capacity
+	b.compressed = true
+}
+
+// takeSaved returns and resets the number of bytes saved by seal.
+func (buf *chunkBuffer) takeSaved() uint64 {
+	saved := buf.saved
+	buf.saved = 0
+
+	return saved
+}
+
+// writeCompressed decompresses a sealed chunk to w.
+func writeCompressed(w io.Writer, b chunk) error {
+	fr := flate.NewReader(bytes.NewReader(b.data))
+	defer fr.Close()
+	_, err := io.Copy(w, fr)
+
+	return err
+}
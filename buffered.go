@@ -0,0 +1,51 @@
+package parallel
+
+import (
+	"bufio"
+	"errors"
+)
+
+// bufferWriters replaces the Group io.Writers with bufio.Writers for WithBufferedOutput.
+// A writer shared by stdout and stderr shares a single buffer so that the relative order
+// of the two streams is preserved. Caller must hold grp.mu.
+func (grp *Group) bufferWriters() {
+	if grp.unbuffered == nil { // Otherwise async or tees have already replaced them
+		grp.unbuffered = grp.writers()
+	}
+	for _, w := range grp.writers() {
+		grp.buffered = append(grp.buffered, bufio.NewWriterSize(w, grp.bufferSize))
+	}
+	grp.stdout = grp.buffered[0]
+	grp.stderr = grp.buffered[len(grp.buffered)-1]
+}
+
+// flushBuffered flushes the WithBufferedOutput buffers under the protection of the Group
+// output mutex. Any error is recorded against rnr, if rnr is not nil, and returned.
+func (grp *Group) flushBuffered(rnr *runner) error {
+	grp.outputMu.Lock()
+	var errs []error
+	for _, b := range grp.buffered {
+		errs = append(errs, b.Flush())
+	}
+	grp.outputMu.Unlock()
+
+	err := errors.Join(errs...)
+	if err != nil && rnr != nil {
+		grp.recordWriteErr(rnr, err)
+	}
+
+	return err
+}
+
+// recordWriteErr records the first write error of a runner as a *WriteError which is
+// then included in the error returned by Wait if it is the first for the Group.
+func (grp *Group) recordWriteErr(rnr *runner, err error) {
+	if rnr.writeErr != nil {
+		return
+	}
+	grp.checkPipe(err)
+	rnr.writeErr = &WriteError{Index: rnr.index, OutTag: string(rnr.outTag), Err: err}
+	if grp.writeErr == nil {
+		grp.writeErr = rnr.writeErr
+	}
+}
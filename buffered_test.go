@@ -0,0 +1,75 @@
+package parallel
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// testCountWriter counts the number of Write calls made to it.
+type testCountWriter struct {
+	testLockedBuffer
+	writes int
+}
+
+func (tcw *testCountWriter) Write(p []byte) (int, error) {
+	tcw.writes++
+	return tcw.testLockedBuffer.Write(p)
+}
+
+func TestBufferedOutput(t *testing.T) {
+	var stdout, stderr testCountWriter
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), WithBufferedOutput(4096))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	for range 3 {
+		grp.Add("t: ", "", func(out, err io.Writer) {
+			for range 100 {
+				out.Write([]byte("tiny\n"))
+			}
+			err.Write([]byte("oops\n"))
+		})
+	}
+	grp.Run()
+	grp.Wait()
+
+	if got := len(stdout.String()); got != 3*100*len("t: tiny\n") {
+		t.Error("Wrong stdout length", got)
+	}
+	if stdout.writes > 3 { // One flush per runner boundary
+		t.Error("Expected buffering to reduce writes, got", stdout.writes)
+	}
+	if got := stderr.String(); got != "oops\noops\noops\n" {
+		t.Error("Wrong stderr", got)
+	}
+
+	if _, err := NewGroup(WithBufferedOutput(0)); err == nil {
+		t.Error("Expected error with zero size")
+	}
+}
+
+func TestBufferedOutputWriteErr(t *testing.T) {
+	diskFull := errors.New("disk full")
+	var stdout testTruncateWriter
+	stdout.append("fail", 0, diskFull)
+	grp, err := NewGroup(WithStdout(&stdout), WithBufferedOutput(4096))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("lost\n")) })
+	grp.Run()
+	err = grp.Wait()
+
+	var we *WriteError
+	if !errors.As(err, &we) || !errors.Is(err, diskFull) {
+		t.Error("Expected WriteError from Wait, got", err)
+	}
+	if strings.Count(err.Error(), diskFull.Error()) != 1 {
+		t.Error("Sticky flush error should only be reported once", err)
+	}
+	if res := grp.RunnerResult(0); res.WriteErr == nil {
+		t.Error("Expected WriteErr in result")
+	}
+}
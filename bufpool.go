@@ -0,0 +1,57 @@
+package parallel
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// Chunk data is allocated from size-classed pools to reduce GC pressure from chatty
+// runners. Each class is a power of two from 1<<minPoolShift to 1<<maxPoolShift
+// bytes. Larger allocations are not pooled as they are rare and would pin large amounts
+// of memory.
+const (
+	minPoolShift = 6  // 64 bytes
+	maxPoolShift = 16 // 64KiB - matches readFromChunkSize
+)
+
+var chunkPools [maxPoolShift - minPoolShift + 1]sync.Pool
+
+// poolClass returns the index of the smallest pool class which holds n bytes, or -1 if
+// n is too large to be pooled.
+func poolClass(n int) int {
+	if n <= 1<<minPoolShift {
+		return 0
+	}
+	shift := bits.Len(uint(n - 1)) // Round up to a power of two
+	if shift > maxPoolShift {
+		return -1
+	}
+
+	return shift - minPoolShift
+}
+
+// getBuf returns a slice of length n, possibly recycled from a pool.
+func getBuf(n int) []byte {
+	class := poolClass(n)
+	if class < 0 {
+		return make([]byte, n)
+	}
+	if p, ok := chunkPools[class].Get().(*[]byte); ok {
+		return (*p)[:n]
+	}
+
+	return make([]byte, n, 1<<(class+minPoolShift))
+}
+
+// putBuf returns a slice to its pool. Slices whose capacity does not exactly match a
+// pool class were not allocated by getBuf so they are left to the GC. Caller must not
+// use b after calling putBuf.
+func putBuf(b []byte) {
+	c := cap(b)
+	class := poolClass(c)
+	if class < 0 || c != 1<<(class+minPoolShift) {
+		return
+	}
+	b = b[:0]
+	chunkPools[class].Put(&b)
+}
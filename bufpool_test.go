@@ -0,0 +1,46 @@
+package parallel
+
+import (
+	"testing"
+)
+
+func TestPoolClass(t *testing.T) {
+	testCases := []struct{ n, class int }{
+		{0, 0}, {1, 0}, {64, 0}, {65, 1}, {128, 1}, {129, 2},
+		{1 << maxPoolShift, maxPoolShift - minPoolShift}, {1<<maxPoolShift + 1, -1},
+	}
+	for _, tc := range testCases {
+		if got := poolClass(tc.n); got != tc.class {
+			t.Error("poolClass", tc.n, "expected", tc.class, "got", got)
+		}
+	}
+}
+
+func TestGetPutBuf(t *testing.T) {
+	b := getBuf(100)
+	if len(b) != 100 || cap(b) != 128 {
+		t.Error("getBuf(100) wrong len/cap", len(b), cap(b))
+	}
+	putBuf(b)
+
+	b = getBuf(1<<maxPoolShift + 1) // Too big to pool
+	if len(b) != cap(b) {
+		t.Error("Unpooled buffer should be exact size", len(b), cap(b))
+	}
+	putBuf(b)                 // Should be ignored
+	putBuf(make([]byte, 100)) // Not a pool class so ignored
+	putBuf(nil)
+}
+
+// Test that queued data survives recycling.
+func TestPoolDrain(t *testing.T) {
+	var outBuf, errBuf testBufWriter
+	outQ, _ := newQueue(false, 0, &outBuf, &errBuf)
+	for ix := 0; ix < 100; ix++ {
+		outQ.Write([]byte("abcdefghij"))
+	}
+	outQ.foreground()
+	if outBuf.Len() != 1000 {
+		t.Error("Expected 1000 bytes after drain, got", outBuf.Len())
+	}
+}
@@ -0,0 +1,71 @@
+package parallel
+
+import (
+	"sync"
+)
+
+// capture retains a copy of all output written by a RunFunc when CaptureOutput is set. If
+// limit is non-zero, capture stops once limit bytes have been retained across both
+// streams.
+type capture struct {
+	sync.Mutex
+	limit    uint64
+	used     uint64
+	out, err []byte
+}
+
+// captureWriter copies each Write to the runner's capture then passes it on unchanged.
+type captureWriter struct {
+	commonWriter
+	cap   *capture
+	where destination
+}
+
+func newCaptureWriter(out writer, c *capture, where destination) *captureWriter {
+	wtr := &captureWriter{cap: c, where: where}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+func (wtr *captureWriter) Write(p []byte) (n int, err error) {
+	wtr.cap.retain(wtr.where, p)
+
+	return wtr.out.Write(p)
+}
+
+func (wtr *captureWriter) close() {
+	wtr.out.close() // Pass it on
+}
+
+func (c *capture) retain(where destination, p []byte) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.limit > 0 {
+		room := c.limit - c.used
+		if uint64(len(p)) > room {
+			p = p[:room]
+		}
+	}
+	c.used += uint64(len(p))
+	if where == toStdout {
+		c.out = append(c.out, p...)
+	} else {
+		c.err = append(c.err, p...)
+	}
+}
+
+// Output returns the output captured for the i'th runner added to the Group when
+// [CaptureOutput] is set, otherwise it returns nil slices. Output can only be called after
+// [Group.Wait] has returned. Detached runners also return nil slices.
+func (grp *Group) Output(i int) (stdout, stderr []byte) {
+	grp.checkState(groupIsDone)
+	rnr := grp.runners()[i]
+	c := rnr.capture
+	if c == nil || rnr.detached {
+		return nil, nil
+	}
+
+	return c.out, c.err
+}
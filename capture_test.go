@@ -0,0 +1,53 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestCaptureOutput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), CaptureOutput(true))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("a: ", "ae: ", func(out, err io.Writer) {
+		out.Write([]byte("one\n"))
+		err.Write([]byte("two\n"))
+	})
+	grp.Add("b: ", "", func(out, err io.Writer) {})
+	grp.Run()
+	grp.Wait()
+
+	o, e := grp.Output(0)
+	if string(o) != "one\n" || string(e) != "two\n" {
+		t.Errorf("Wrong captured output %q %q", o, e)
+	}
+	o, e = grp.Output(1)
+	if len(o) != 0 || len(e) != 0 {
+		t.Error("Expected no captured output", o, e)
+	}
+	if stdout.String() != "a: one\n" {
+		t.Error("Output should still be written when captured", stdout.String())
+	}
+
+	// Not capturing
+	grp, _ = NewGroup(WithStdout(&stdout))
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("x")) })
+	grp.Run()
+	grp.Wait()
+	if o, e := grp.Output(0); o != nil || e != nil {
+		t.Error("Expected nil output when not capturing")
+	}
+}
+
+func TestCaptureLimit(t *testing.T) {
+	c := &capture{limit: 5}
+	c.retain(toStdout, []byte("abc"))
+	c.retain(toStderr, []byte("def"))
+	c.retain(toStdout, []byte("ghi"))
+	if string(c.out) != "abc" || string(c.err) != "de" {
+		t.Errorf("Capture limit not applied %q %q", c.out, c.err)
+	}
+}
@@ -0,0 +1,92 @@
+package parallel
+
+import (
+	"sync"
+	"unicode/utf8"
+)
+
+// Transformer converts a stream of bytes from one form to another. It is the same method
+// set as the Transformer in golang.org/x/text/transform, so any *encoding.Decoder from
+// golang.org/x/text/encoding, such as charmap.Windows1252.NewDecoder(), satisfies it
+// without this package depending on golang.org/x/text. See [WithSourceEncoding].
+type Transformer interface {
+	Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error)
+	Reset()
+}
+
+// transcoder is a writer which converts RunFunc output to UTF-8 with a Transformer. Bytes
+// of an incomplete multi-byte sequence are held back until completed or the writer is
+// closed.
+type transcoder struct {
+	mu sync.Mutex
+	commonWriter
+	dec     Transformer
+	dst     []byte
+	partial []byte
+}
+
+func newTranscoder(out writer, dec Transformer) *transcoder {
+	wtr := &transcoder{dec: dec}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+// Write returns the length of p on success as held back bytes are consumed even tho they
+// are not yet written downstream. The first downstream error is returned.
+func (wtr *transcoder) Write(p []byte) (n int, err error) {
+	wtr.mu.Lock()
+	defer wtr.mu.Unlock()
+
+	src := p
+	if len(wtr.partial) > 0 {
+		src = append(wtr.partial, p...)
+	}
+	wtr.partial, err = wtr.transform(src, false)
+
+	return len(p), err
+}
+
+// transform writes the conversion of src downstream and returns any unconverted
+// remainder. The remainder must be retained by the caller before the next call. Caller
+// must hold the mutex.
+//
+// The golang.org/x/text sentinel errors cannot be compared without depending on that
+// module, so errors are distinguished by progress instead. As dst is sized to hold the
+// worst case conversion, a lack of progress means that src ends with an incomplete
+// sequence which is held back, or written as-is at EOF.
+func (wtr *transcoder) transform(src []byte, atEOF bool) (remainder []byte, err error) {
+	if need := len(src)*utf8.UTFMax + utf8.UTFMax; cap(wtr.dst) < need {
+		wtr.dst = make([]byte, need)
+	}
+	dst := wtr.dst[:cap(wtr.dst)]
+	for {
+		nDst, nSrc, e := wtr.dec.Transform(dst, src, atEOF)
+		if nDst > 0 {
+			if _, e := wtr.out.Write(dst[:nDst]); e != nil && err == nil {
+				err = e
+			}
+		}
+		src = src[nSrc:]
+		if e == nil || len(src) == 0 {
+			return nil, err
+		}
+		if nDst == 0 && nSrc == 0 { // No progress
+			if atEOF {
+				if _, e := wtr.out.Write(src); e != nil && err == nil {
+					err = e
+				}
+				return nil, err
+			}
+			return append([]byte(nil), src...), err
+		}
+	}
+}
+
+func (wtr *transcoder) close() {
+	wtr.mu.Lock()
+	wtr.transform(wtr.partial, true)
+	wtr.partial = nil
+	wtr.mu.Unlock()
+	wtr.out.close() // Pass it on
+}
@@ -0,0 +1,80 @@
+package parallel
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"unicode/utf8"
+)
+
+// testLatin1 decodes ISO-8859-1 which maps each byte directly to a rune.
+type testLatin1 struct{}
+
+func (testLatin1) Reset() {}
+
+func (testLatin1) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for _, b := range src {
+		if len(dst)-nDst < utf8.UTFMax {
+			return nDst, nSrc, errors.New("short dst")
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], rune(b))
+		nSrc++
+	}
+
+	return
+}
+
+// testUTF16LE decodes two byte little-endian characters, excluding surrogates, so that
+// incomplete sequences are exercised.
+type testUTF16LE struct{}
+
+func (testUTF16LE) Reset() {}
+
+func (testUTF16LE) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for len(src)-nSrc >= 2 {
+		r := rune(src[nSrc]) | rune(src[nSrc+1])<<8
+		nDst += utf8.EncodeRune(dst[nDst:], r)
+		nSrc += 2
+	}
+	if nSrc < len(src) {
+		err = errors.New("short src")
+	}
+
+	return
+}
+
+func TestSourceEncoding(t *testing.T) {
+	if _, err := NewGroup(WithSourceEncoding(nil)); err == nil {
+		t.Error("Expected error with nil function")
+	}
+
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr),
+		WithSourceEncoding(func() Transformer { return testLatin1{} }))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("a: ", "a! ", func(out, err io.Writer) {
+		out.Write([]byte("caf\xe9\n"))
+		err.Write([]byte("na\xefve\n"))
+	})
+	grp.Add("b: ", "", func(out, err io.Writer) {
+		out.Write([]byte("h\x00i"))
+		out.Write([]byte("\x00\n\x00\xe9"))
+	}, RunnerSourceEncoding(func() Transformer { return testUTF16LE{} }))
+	grp.Add("c: ", "", func(out, err io.Writer) {
+		out.Write([]byte("caf\xc3\xa9\n"))
+	}, RunnerSourceEncoding(nil))
+	grp.Run()
+	grp.Wait()
+
+	// The trailing odd byte of "b" is written as-is at EOF
+	exp := "a: café\nb: hi\nb: \xe9c: café\n"
+	if got := stdout.String(); got != exp {
+		t.Errorf("Wrong stdout %q expected %q", got, exp)
+	}
+	if got := stderr.String(); got != "a! naïve\n" {
+		t.Errorf("Wrong stderr %q", got)
+	}
+}
@@ -0,0 +1,190 @@
+package parallel
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for all timing-dependent features of a [Group], such as
+// [WithStartDelay], [WithStartRate], [WithStallWarning], [WithProgress],
+// [WithFlushInterval], [SoftLimitMemoryPerRunner], [WithRoundRobin] time slices,
+// [WithOutputRateLimit] and the durations reported for each RunFunc. The default Clock is
+// the system clock. Supply a [TestClock] with [WithClock] so that tests can control time
+// rather than rely on real sleeps.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the Clock equivalent of [time.Timer]. As with time.Timer in Go 1.23 and later,
+// no stale value is received from C after Reset or Stop returns.
+type Timer interface {
+	C() <-chan time.Time
+	Reset(d time.Duration) bool
+	Stop() bool
+}
+
+// systemClock is the default Clock which defers to the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) NewTimer(d time.Duration) Timer         { return systemTimer{time.NewTimer(d)} }
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time { return t.Timer.C }
+
+// since returns the time elapsed since t according to clock.
+func since(clock Clock, t time.Time) time.Duration {
+	return clock.Now().Sub(t)
+}
+
+// TestClock is a [Clock] which only moves when told to by [TestClock.Advance] or
+// [TestClock.Set], at which point any timers which have expired fire in order of
+// expiry. Tests typically wait for [TestClock.Waiters] to reach an expected value, which
+// indicates that the code under test is waiting on the clock, before advancing it.
+// TestClock is safe for concurrent use.
+type TestClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*testTimer // Active timers
+}
+
+// NewTestClock returns a TestClock set to start.
+func NewTestClock(start time.Time) *TestClock {
+	return &TestClock{now: start}
+}
+
+// Now returns the current time of the TestClock.
+func (tc *TestClock) Now() time.Time {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	return tc.now
+}
+
+// After is the TestClock equivalent of [time.After].
+func (tc *TestClock) After(d time.Duration) <-chan time.Time {
+	return tc.NewTimer(d).C()
+}
+
+// NewTimer is the TestClock equivalent of [time.NewTimer]. A timer with a non-positive
+// duration fires immediately.
+func (tc *TestClock) NewTimer(d time.Duration) Timer {
+	t := &testTimer{tc: tc, c: make(chan time.Time, 1)}
+	t.Reset(d)
+
+	return t
+}
+
+// Advance moves the TestClock forward by d and fires all timers which have expired.
+func (tc *TestClock) Advance(d time.Duration) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.set(tc.now.Add(d))
+}
+
+// Set moves the TestClock to t and fires all timers which have expired. Setting the
+// TestClock backwards does not fire any timers.
+func (tc *TestClock) Set(t time.Time) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.set(t)
+}
+
+// Waiters returns the number of timers, including those created by After, which have not
+// yet fired or been stopped.
+func (tc *TestClock) Waiters() int {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	return len(tc.timers)
+}
+
+// set moves the clock and fires expired timers in order of expiry. Caller must hold mu.
+func (tc *TestClock) set(now time.Time) {
+	tc.now = now
+	sort.SliceStable(tc.timers, func(i, j int) bool {
+		return tc.timers[i].when.Before(tc.timers[j].when)
+	})
+	var active []*testTimer
+	for _, t := range tc.timers {
+		if t.when.After(now) {
+			active = append(active, t)
+		} else {
+			t.fire(now)
+		}
+	}
+	tc.timers = active
+}
+
+// remove removes t from the active timers and returns true if it was active. Caller must
+// hold mu.
+func (tc *TestClock) remove(t *testTimer) bool {
+	for ix, at := range tc.timers {
+		if at == t {
+			tc.timers = append(tc.timers[:ix], tc.timers[ix+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// testTimer is the Timer returned by TestClock.
+type testTimer struct {
+	tc   *TestClock
+	c    chan time.Time // Buffered so that firing never blocks the clock
+	when time.Time
+}
+
+func (t *testTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *testTimer) Reset(d time.Duration) bool {
+	t.tc.mu.Lock()
+	defer t.tc.mu.Unlock()
+
+	active := t.tc.remove(t)
+	t.drain()
+	t.when = t.tc.now.Add(d)
+	if d <= 0 {
+		t.fire(t.tc.now)
+	} else {
+		t.tc.timers = append(t.tc.timers, t)
+	}
+
+	return active
+}
+
+func (t *testTimer) Stop() bool {
+	t.tc.mu.Lock()
+	defer t.tc.mu.Unlock()
+
+	active := t.tc.remove(t)
+	t.drain()
+
+	return active
+}
+
+func (t *testTimer) fire(now time.Time) {
+	select {
+	case t.c <- now:
+	default:
+	}
+}
+
+func (t *testTimer) drain() {
+	select {
+	case <-t.c:
+	default:
+	}
+}
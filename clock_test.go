@@ -0,0 +1,86 @@
+package parallel
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestTestClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tc := NewTestClock(start)
+	t1 := tc.NewTimer(time.Second)
+	t2 := tc.NewTimer(2 * time.Second)
+	after := tc.After(3 * time.Second)
+	if tc.Waiters() != 3 {
+		t.Error("Expected three waiters, got", tc.Waiters())
+	}
+	select {
+	case <-t1.C():
+		t.Error("Timer fired before the clock advanced")
+	default:
+	}
+
+	tc.Advance(time.Second)
+	if got := <-t1.C(); !got.Equal(start.Add(time.Second)) {
+		t.Error("Wrong fire time", got)
+	}
+	if !t2.Stop() || t2.Stop() {
+		t.Error("Stop should only report an active timer once")
+	}
+	tc.Set(start.Add(time.Hour))
+	if got := <-after; !got.Equal(start.Add(time.Hour)) {
+		t.Error("Wrong After time", got)
+	}
+	select {
+	case <-t2.C():
+		t.Error("Stopped timer fired")
+	default:
+	}
+	if tc.Waiters() != 0 || !tc.Now().Equal(start.Add(time.Hour)) {
+		t.Error("Wrong clock state", tc.Waiters(), tc.Now())
+	}
+
+	// Reset re-arms a fired timer and discards any unreceived value
+	tc.Advance(0)
+	t1.Reset(time.Minute)
+	tc.Advance(time.Minute)
+	t1.Reset(time.Minute)
+	select {
+	case <-t1.C():
+		t.Error("Reset did not discard the stale value")
+	default:
+	}
+	if t1.Reset(0) != true {
+		t.Error("Reset should report the timer was active")
+	}
+	<-t1.C() // Non-positive durations fire immediately
+}
+
+// A start rate of one per hour completes instantly once the clock is advanced.
+func TestClockStartRate(t *testing.T) {
+	tc := NewTestClock(time.Now())
+	if _, err := NewGroup(WithClock(nil)); err == nil {
+		t.Error("Expected error with nil Clock")
+	}
+	var stdout testLockedBuffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStartRate(1, time.Hour), WithClock(tc))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	for _, tag := range []string{"a", "b"} {
+		grp.Add("", "", func(out, err io.Writer) { out.Write([]byte(tag)) })
+	}
+	grp.Run()
+	for tc.Waiters() == 0 { // Until the second runner waits for its token
+		time.Sleep(time.Millisecond)
+	}
+	tc.Advance(time.Hour)
+	grp.Wait()
+	if got := stdout.String(); got != "ab" {
+		t.Errorf("Wrong output %q", got)
+	}
+	if d := grp.Stats()[1].Duration; d != 0 {
+		t.Error("RunFunc duration should come from the TestClock", d)
+	}
+}
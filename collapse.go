@@ -0,0 +1,98 @@
+package parallel
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The Collapse Pipeline consists of head and a capture writer which retains all output of
+// the runner. Nothing is written to the Group io.Writers until all runners have completed
+// at which point writeCollapsed writes each distinct output once. Any WithPipelineWriter
+// middleware precedes the capture writers.
+func (rnr *runner) buildCollapsePipeline(grp *Group) {
+	rnr.collapse = &capture{}
+	var stdout, stderr writer
+	stdout = newCaptureWriter(discard{}, rnr.collapse, toStdout)
+	stderr = newCaptureWriter(discard{}, rnr.collapse, toStderr)
+	if grp.mergeStderr {
+		stderr = stdout
+	}
+	stdout, stderr = rnr.addMiddleware(grp, stdout, stderr)
+	if grp.collapseCR { // Always buffered
+		stdout = newCRCollapser(stdout, nil, grp.delim)
+		stderr = newCRCollapser(stderr, nil, grp.delim)
+	}
+
+	rnr.buildHeads(grp, stdout, stderr)
+}
+
+// collapsed is a set of runners which produced byte-identical output.
+type collapsed struct {
+	out, err         []byte
+	outTags, errTags []string
+}
+
+// writeCollapsed groups runners with byte-identical stdout and stderr output and writes
+// each group once, in order of first appearance, tagged with the combined tags of all
+// runners in the group. Runners which produced no output are not written at all. Caller
+// must hold grp.mu.
+func (grp *Group) writeCollapsed() {
+	var groups []*collapsed
+	index := make(map[string]*collapsed)
+	for _, rnr := range grp.all {
+		c := rnr.collapse
+		if c == nil || len(c.out) == 0 && len(c.err) == 0 {
+			continue
+		}
+		key := strconv.Itoa(len(c.out)) + ":" + string(c.out) + string(c.err)
+		g := index[key]
+		if g == nil {
+			g = &collapsed{out: c.out, err: c.err}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		g.outTags = append(g.outTags, string(rnr.outTag))
+		g.errTags = append(g.errTags, string(rnr.errTag))
+	}
+
+	stdout := grp.newTail(grp.stdout, &grp.outputMu)
+	stderr := grp.newTail(grp.stderr, &grp.outputMu)
+	for _, g := range groups {
+		if len(g.out) > 0 {
+			newTagger(stdout, []byte(combineTags(g.outTags)), grp.delim).Write(g.out)
+		}
+		if len(g.err) > 0 {
+			newTagger(stderr, []byte(combineTags(g.errTags)), grp.delim).Write(g.err)
+		}
+	}
+}
+
+// combineTags joins the tags into a single tag which lists each of them. Any trailing
+// separator common to all tags, such as ": " or "\t", is removed from each tag and
+// appended once to the result, thus "host1: " and "host2: " combine to "host1,host2: ".
+func combineTags(tags []string) string {
+	suffix := tags[0]
+	for _, tag := range tags[1:] {
+		for !strings.HasSuffix(tag, suffix) {
+			suffix = suffix[1:]
+		}
+	}
+	// Only retain the trailing punctuation and space of the common suffix
+	trim := strings.TrimRightFunc(suffix, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	suffix = suffix[len(trim):]
+
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if name := strings.TrimSuffix(tag, suffix); len(name) > 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+
+	return strings.Join(names, ",") + suffix
+}
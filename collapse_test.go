@@ -0,0 +1,66 @@
+package parallel
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCollapse(t *testing.T) {
+	var stdout, stderr testLockedBuffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), WithCollapse(true))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	add := func(tag, out, errOut string) {
+		grp.Add(tag+": ", tag+"! ", func(o, e io.Writer) {
+			o.Write([]byte(out))
+			e.Write([]byte(errOut))
+		})
+	}
+	add("h1", "up 3 days\nok\n", "")
+	add("h2", "up 1 day\n", "")
+	add("h3", "up 3 days\nok\n", "")
+	add("h4", "up 1 day\n", "warn\n")
+	add("h5", "", "")
+	add("h6", "up 3 days\nok\n", "")
+	grp.Run()
+	grp.Wait()
+
+	exp := "h1,h3,h6: up 3 days\nh1,h3,h6: ok\nh2: up 1 day\nh4: up 1 day\n"
+	if got := stdout.String(); got != exp {
+		t.Errorf("Wrong stdout\nGot %q\nExp %q", got, exp)
+	}
+	if got := stderr.String(); got != "h4! warn\n" {
+		t.Errorf("Wrong stderr %q", got)
+	}
+}
+
+func TestCombineTags(t *testing.T) {
+	for _, tc := range []struct {
+		tags []string
+		exp  string
+	}{
+		{[]string{"a: "}, "a: "},
+		{[]string{"a\t", "b\t"}, "a,b\t"},
+		{[]string{"host1: ", "host2: "}, "host1,host2: "},
+		{[]string{"x", "y"}, "x,y"},
+		{[]string{"", ""}, ""},
+		{[]string{"a:", "b "}, "a:,b "},
+	} {
+		if got := combineTags(tc.tags); got != tc.exp {
+			t.Errorf("%q: got %q, expected %q", tc.tags, got, tc.exp)
+		}
+	}
+}
+
+func TestCollapseConflicts(t *testing.T) {
+	_, err := NewGroup(WithCollapse(true), LimitMemoryPerRunner(10), LimitActiveRunners(1))
+	if !errors.Is(err, ErrCollapseWithMemoryLimit) {
+		t.Error("Expected ErrCollapseWithMemoryLimit, got", err)
+	}
+	_, err = NewGroup(WithCollapse(true), WithStdoutSeparator("--\n"))
+	if !errors.Is(err, ErrCollapseWithSeparators) {
+		t.Error("Expected ErrCollapseWithSeparators, got", err)
+	}
+}
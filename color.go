@@ -0,0 +1,45 @@
+package parallel
+
+import (
+	"bytes"
+)
+
+// Red, green, yellow, blue, magenta and cyan. Black and white are excluded as one or the
+// other is likely to be invisible on the terminal background.
+var defaultTagColors = []string{"31", "32", "33", "34", "35", "36"}
+
+// tags returns the tags which the tagger writes for rnr, colored if WithTagColors applies.
+// The runner tags themselves remain plain as they also identify the runner in the job log,
+// RunnerInfo, recordings and the like.
+func (grp *Group) tags(rnr *runner) (outTag, errTag []byte) {
+	outTag, errTag = rnr.outTag, rnr.errTag
+	if grp.colorOut {
+		outTag = colorTag(outTag, grp.tagColors, rnr.index)
+	}
+	if grp.colorErr {
+		errTag = colorTag(errTag, grp.tagColors, rnr.index)
+	}
+
+	return
+}
+
+// colorTag wraps the non-whitespace prefix of tag in the ANSI escape sequence of the
+// palette entry selected by index. Trailing whitespace is left uncolored so that column
+// alignment created by tabs is not disturbed. An empty tag remains empty.
+func colorTag(tag []byte, palette []string, index int) []byte {
+	body := bytes.TrimRight(tag, " \t")
+	if len(body) == 0 || len(palette) == 0 {
+		return tag
+	}
+
+	sgr := palette[index%len(palette)]
+	colored := make([]byte, 0, len(tag)+len(sgr)+7)
+	colored = append(colored, "\x1b["...)
+	colored = append(colored, sgr...)
+	colored = append(colored, 'm')
+	colored = append(colored, body...)
+	colored = append(colored, "\x1b[0m"...)
+	colored = append(colored, tag[len(body):]...)
+
+	return colored
+}
@@ -0,0 +1,119 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestColorTag(t *testing.T) {
+	palette := []string{"31", "1;32"}
+	type testCase struct {
+		tag    string
+		index  int
+		expect string
+	}
+
+	testCases := []testCase{
+		{"host1\t", 0, "\x1b[31mhost1\x1b[0m\t"},
+		{"host2: ", 1, "\x1b[1;32mhost2:\x1b[0m "},
+		{"host3", 2, "\x1b[31mhost3\x1b[0m"}, // Palette cycles
+		{"", 0, ""},
+		{"\t", 0, "\t"},
+	}
+
+	for ix, tc := range testCases {
+		got := string(colorTag([]byte(tc.tag), palette, tc.index))
+		if got != tc.expect {
+			t.Errorf("%d: Expected %q, got %q", ix, tc.expect, got)
+		}
+	}
+}
+
+// Colors should be automatically disabled when output is not a terminal.
+func TestColorNotTerminal(t *testing.T) {
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithTagColors())
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	if len(grp.tagColors) != len(defaultTagColors) {
+		t.Error("WithTagColors() should set the default palette")
+	}
+	grp.Add("tag\t", "", func(out, err io.Writer) { out.Write([]byte("line\n")) })
+	grp.Run()
+	grp.Wait()
+	if stdout.String() != "tag\tline\n" {
+		t.Errorf("Colors should be disabled for non-terminals, got %q", stdout.String())
+	}
+
+	if IsTerminal(&stdout) {
+		t.Error("bytes.Buffer should not be a terminal")
+	}
+	f, err := os.CreateTemp(t.TempDir(), "tty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if IsTerminal(f) {
+		t.Error("Regular file should not be a terminal")
+	}
+}
+
+func TestColorMode(t *testing.T) {
+	type testCase struct {
+		mode   TTYMode
+		expect string
+	}
+	testCases := []testCase{
+		{TTYAuto, "tag\tline\n"},
+		{TTYAlways, "\x1b[31mtag\x1b[0m\tline\n"},
+		{TTYNever, "tag\tline\n"},
+	}
+
+	for ix, tc := range testCases {
+		var stdout bytes.Buffer
+		grp, err := NewGroup(WithStdout(&stdout), WithTagColors(), WithColorMode(tc.mode))
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		grp.Add("tag\t", "", func(out, err io.Writer) { out.Write([]byte("line\n")) })
+		grp.Run()
+		grp.Wait()
+		if got := stdout.String(); got != tc.expect {
+			t.Errorf("%d: Expected %q, got %q", ix, tc.expect, got)
+		}
+	}
+}
+
+// Colors are only applied to the written tags, not to the tags which identify the runner
+// elsewhere, otherwise a colored run could never be resumed.
+func TestColorPlainTags(t *testing.T) {
+	var stdout, jobLog bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithTagColors(), WithColorMode(TTYAlways),
+		WithJobLog(&jobLog))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("tag\t", "", func(out, err io.Writer) { out.Write([]byte("line\n")) })
+	grp.Run()
+	grp.Wait()
+
+	if got := stdout.String(); got != "\x1b[31mtag\x1b[0m\tline\n" {
+		t.Errorf("Expected colored output, got %q", got)
+	}
+	if got := grp.RunnerResult(0).OutTag; got != "tag\t" {
+		t.Errorf("Expected plain RunnerResult tag, got %q", got)
+	}
+	if strings.Contains(jobLog.String(), "\x1b") || !strings.Contains(jobLog.String(), "\ttag\t") {
+		t.Errorf("Expected plain job log tag, got %q", jobLog.String())
+	}
+
+	grp, _ = NewGroup(WithStdout(&stdout), WithTagColors(), WithColorMode(TTYAlways),
+		WithResume(&jobLog))
+	grp.Add("tag\t", "", func(out, err io.Writer) { t.Error("Runner should be resumed") })
+	grp.Run()
+	grp.Wait()
+}
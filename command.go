@@ -0,0 +1,81 @@
+package parallel
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"strconv"
+)
+
+// AddCommand is a variant of [Group.AddContextErr] which runs an external command rather
+// than a RunFunc. It removes the boilerplate otherwise needed to connect an [exec.Cmd] to
+// the Group. The cmd.Stdout and cmd.Stderr fields are replaced with the runner's
+// io.Writers, the command is started and waited on, and the error, if any, is recorded
+// against the runner. A command which exits with a non-zero status records an
+// [*exec.ExitError]. The exit code of the command is available via
+// [RunnerResult].ExitCode.
+//
+// The job slot of the runner, as described by [Slot], is set in the PARALLEL_SLOT
+// environment variable of the command.
+//
+// If [WithCommandPTY] or [RunnerPTY] is set, the command output is written to a
+// pseudo-terminal rather than to pipes.
+//
+// If the runner context is cancelled, such as by [Group.Cancel] or by cancelling the
+// [Group.RunContext] context, the command is killed. Commands created with
+// [exec.CommandContext] can use cmd.Cancel for more graceful termination. As with all
+// RunFuncs, cmd must not be shared with any other runner.
+func (grp *Group) AddCommand(outTag, errTag string, cmd *exec.Cmd, opts ...RunnerOption) {
+	var usePTY bool
+	opts = append(opts, runnerOption(func(rnr *runner) { rnr.cmd = cmd; usePTY = rnr.pty }))
+	grp.add(outTag, errTag,
+		func(ctx context.Context, stdout, stderr io.Writer) error {
+			return runCommand(ctx, cmd, usePTY, stdout, stderr)
+		}, opts)
+}
+
+// runCommand runs cmd to completion with its output connected to stdout and stderr, or
+// to a pseudo-terminal which is copied to stdout if usePTY is set. cmd is killed if ctx
+// is done before it exits.
+func runCommand(ctx context.Context, cmd *exec.Cmd, usePTY bool, stdout, stderr io.Writer) error {
+	if slot := Slot(ctx); slot > 0 {
+		cmd.Env = append(cmd.Environ(), "PARALLEL_SLOT="+strconv.Itoa(slot))
+	}
+	var copied chan struct{} // Closed once all pseudo-terminal output is copied
+	if usePTY {
+		master, err := startPTY(cmd)
+		if err != nil {
+			return err
+		}
+		defer master.Close()
+		copied = make(chan struct{})
+		go func() {
+			io.Copy(stdout, master) // Ends with EIO once the command closes the terminal
+			close(copied)
+		}()
+	} else {
+		cmd.Stdout, cmd.Stderr = stdout, stderr
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+	}
+	stop := context.AfterFunc(ctx, func() { cmd.Process.Kill() })
+	defer stop()
+
+	err := cmd.Wait()
+	if copied != nil {
+		<-copied
+	}
+
+	return err
+}
+
+// exitCode returns the exit code of the runner's command, or -1 if the runner did not
+// run a command or the command did not exit.
+func (rnr *runner) exitCode() int {
+	if rnr.cmd == nil || rnr.cmd.ProcessState == nil {
+		return -1
+	}
+
+	return rnr.cmd.ProcessState.ExitCode()
+}
@@ -0,0 +1,92 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestAddCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Requires a POSIX shell")
+	}
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.AddCommand("ok: ", "", exec.Command("sh", "-c", "echo hello; echo oops >&2"))
+	grp.AddCommand("bad: ", "", exec.Command("sh", "-c", "exit 3"))
+	grp.Add("", "", func(out, err io.Writer) {})
+	grp.Run()
+	err = grp.Wait()
+
+	var ee *exec.ExitError
+	if !errors.As(err, &ee) || ee.ExitCode() != 3 {
+		t.Error("Expected ExitError from Wait, got", err)
+	}
+	if got := stdout.String(); got != "ok: hello\n" {
+		t.Error("Wrong stdout", got)
+	}
+	if got := stderr.String(); got != "oops\n" {
+		t.Error("Wrong stderr", got)
+	}
+	for ix, exp := range []int{0, 3, -1} {
+		if got := grp.RunnerResult(ix).ExitCode; got != exp {
+			t.Error(ix, "Wrong exit code", got, "expected", exp)
+		}
+	}
+}
+
+func TestAddCommandCancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Requires a POSIX shell")
+	}
+	grp, err := NewGroup()
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	grp.AddCommand("", "", exec.Command("sleep", "10"))
+	start := time.Now()
+	grp.RunContext(ctx)
+	err = grp.Wait()
+	if time.Since(start) > 5*time.Second {
+		t.Error("Command was not killed when the context was cancelled")
+	}
+	if err == nil {
+		t.Error("Expected an error from a killed command")
+	}
+}
+
+func TestAddCommandPTY(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Pseudo-terminals are only supported on Linux")
+	}
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), WithCommandPTY(true))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	script := "if test -t 1; then echo tty; else echo pipe; fi; echo oops >&2"
+	grp.AddCommand("pty: ", "", exec.Command("sh", "-c", script))
+	grp.AddCommand("pipe: ", "", exec.Command("sh", "-c", script), RunnerPTY(false))
+	grp.Run()
+	if err = grp.Wait(); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	exp := "pty: tty\npty: oops\npipe: pipe\n" // No CRs from the terminal
+	if got := stdout.String(); got != exp {
+		t.Errorf("Wrong stdout. Got %q Expected %q", got, exp)
+	}
+	if got := stderr.String(); got != "oops\n" {
+		t.Error("Wrong stderr", got)
+	}
+}
@@ -2,7 +2,10 @@ package parallel
 
 import (
 	"bytes"
+	"strings"
 	"sync"
+	"testing"
+	"time"
 )
 
 // A writer which collects output to see what the Pipeline has let thru.
@@ -96,3 +99,15 @@ func (ttw *testTruncateWriter) getNext() writer {
 
 func (ttw *testTruncateWriter) setNext(w writer) {
 }
+
+// waitFor polls lb until it contains want or the test times out.
+func waitFor(t *testing.T, lb *testLockedBuffer, want string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(lb.String(), want) {
+		if time.Now().After(deadline) {
+			t.Errorf("Timed out waiting for %q. Got %q", want, lb.String())
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
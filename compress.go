@@ -0,0 +1,41 @@
+package parallel
+
+import (
+	"io"
+)
+
+// Compressor constructs a compressing io.WriteCloser which writes to w. Close must flush
+// all compressed data to w but must not close w. See [WithCompressor].
+type Compressor func(w io.Writer) io.WriteCloser
+
+// compressor is a writer which compresses all output of a runner stream. The compressing
+// io.WriteCloser is only created on the first Write so that runners which produce no
+// output don't produce an empty compressed member either.
+type compressor struct {
+	commonWriter
+	newZ Compressor
+	zw   io.WriteCloser
+}
+
+func newCompressor(out writer, newZ Compressor) *compressor {
+	wtr := &compressor{newZ: newZ}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+func (wtr *compressor) Write(p []byte) (n int, err error) {
+	if wtr.zw == nil {
+		wtr.zw = wtr.newZ(struct{ io.Writer }{wtr.out})
+	}
+
+	return wtr.zw.Write(p)
+}
+
+func (wtr *compressor) close() {
+	if wtr.zw != nil {
+		wtr.zw.Close()
+		wtr.zw = nil
+	}
+	wtr.out.close() // Pass it on
+}
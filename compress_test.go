@@ -0,0 +1,56 @@
+package parallel
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr),
+		WithCompression(gzip.BestSpeed))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("a: ", "", func(out, err io.Writer) { out.Write([]byte("one\ntwo\n")) })
+	grp.Add("", "", func(out, err io.Writer) {}) // No output means no member
+	grp.Add("b: ", "", func(out, err io.Writer) { out.Write([]byte("three\n")) })
+	grp.Run()
+	grp.Wait()
+
+	if stderr.Len() != 0 {
+		t.Error("Expected no stderr output, got", stderr.Len())
+	}
+
+	zr, err := gzip.NewReader(&stdout)
+	if err != nil {
+		t.Fatal("gzip.NewReader failed", err)
+	}
+	members := 0
+	var plain bytes.Buffer
+	for {
+		zr.Multistream(false)
+		io.Copy(&plain, zr)
+		members++
+		if zr.Reset(&stdout) == io.EOF {
+			break
+		}
+	}
+	if members != 2 {
+		t.Error("Expected two gzip members, got", members)
+	}
+	if plain.String() != "a: one\na: two\nb: three\n" {
+		t.Errorf("Decompressed output wrong %q", plain.String())
+	}
+
+	for _, opts := range [][]Option{{WithCompression(99)}, {WithCompressor(nil)},
+		{WithCompression(1), WithStdoutSeparator("--\n")},
+		{WithCompression(1), OrderRunners(false), Ungroup(true)}} {
+		_, err = NewGroup(opts...)
+		if err == nil {
+			t.Error("Expected error from invalid compression options")
+		}
+	}
+}
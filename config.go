@@ -1,9 +1,15 @@
 package parallel
 
 import (
+	"compress/gzip"
 	"errors"
 	"io"
+	"log/slog"
+	"math"
 	"os"
+	"runtime"
+	"sync"
+	"time"
 )
 
 // Config options are set for the NewGroup constructor. Because options are somewhat
@@ -22,15 +28,90 @@ import (
 //
 // To get the default config settings, the caller should use the newConfig constructor.
 type config struct {
-	stdout       io.Writer // Parent destination of all stdout
-	stderr       io.Writer // Parent destination of all stderr
-	outSep       []byte    // Printed to stdout between runners
-	errSep       []byte    // Printed to stderr between runners (after outSep)
-	limitMemory  uint64    // Maximum bytes buffered before stalling a background runner
-	limitRunners uint      // Maximum concurrent runners allowed to run
-	orderRunners bool      // All output is written in runner creation order
-	orderStderr  bool      // For each runner, all stdout precedes all stderr
-	passthru     bool      // Debug option: output is written as soon as it's seen
+	stdout          io.Writer // Parent destination of all stdout
+	stderr          io.Writer // Parent destination of all stderr
+	outSep          []byte    // Printed to stdout between runners
+	errSep          []byte    // Printed to stderr between runners (after outSep)
+	limitMemory     uint64    // Maximum bytes buffered before stalling a background runner
+	softMemory      uint64    // Bytes buffered before throttling a background runner
+	limitRunners    uint      // Maximum concurrent runners allowed to run
+	autoRunners     bool      // limitRunners is an upper bound for adaptive concurrency
+	cpuFactor       float64   // limitRunners is set to cpuFactor*GOMAXPROCS at Run
+	orderRunners    bool      // All output is written in runner creation order
+	orderStderr     bool      // For each runner, all stdout precedes all stderr
+	passthru        bool      // Debug option: output is written as soon as it's seen
+	ungroup         bool      // Output is tagged and written as soon as it's seen
+	openEnded       bool      // Add is allowed after Run until CloseAdd is called
+	haltPolicy      *haltPolicy
+	spillDir        string          // Directory for spilled output when limitMemory is exceeded
+	resultsDir      string          // Root of the per-runner WithResultsDir directories
+	resultsSkip     bool            // Skip runners whose results directory shows success
+	tagColors       []string        // ANSI SGR parameters cycled thru for each runner's tags
+	colorMode       TTYMode         // When tagColors are applied
+	progress        io.Writer       // Destination of periodic progress reports
+	progressMode    TTYMode         // When progress reports are written
+	jobLog          io.Writer       // Destination of per-runner completion records
+	jobLogFormat    JobLogFormat    // Encoding of jobLog
+	recording       io.Writer       // Destination of WithRecorder events
+	clock           Clock           // Source of time, normally the system clock
+	resume          map[string]bool // Tags of runners which previously succeeded
+	shardIndex      int             // Shard run by this Group - see WithShard
+	shardTotal      int             // Number of shards, zero if not sharded
+	startEvery      time.Duration   // Minimum average interval between runner starts
+	startBurst      int             // Runners which can start without waiting for startEvery
+	hooks           Hooks
+	signals         []os.Signal
+	dumpSignals     []os.Signal
+	footer          func(RunnerInfo) string
+	dedupKey        func(RunnerInfo) string
+	dedupReplay     bool
+	tracer          Tracer
+	logger          *slog.Logger
+	stallAfter      time.Duration
+	throttleDelay   time.Duration
+	stallFunc       func(StallInfo)
+	onBlocked       func(info RunnerInfo, buffered uint64)
+	onThrottled     func(info RunnerInfo, buffered uint64)
+	notify          chan<- RunnerResult
+	detachOn        bool      // Detach unfinished runners when WaitContext gives up
+	scheduler       Scheduler // Nil means the built-in equivalent of FIFOScheduler
+	orderBy         func(i, j RunnerInfo) bool
+	sectionFormat   string
+	tagSuffix       string // Appended to tags if suffixTags is set, normally "\t"
+	suffixTags      bool   // Set by WithTagSuffix
+	report          ReportFormat
+	roundRobin      bool
+	rrSlice         time.Duration
+	rrMode          TTYMode       // When roundRobin is applied
+	flushInterval   time.Duration // Period between flushes of buffered Group io.Writers
+	bufferSize      int           // Size of the buffers wrapping the Group io.Writers
+	asyncDepth      int           // Capacity of the WithAsyncOutput channel
+	outputRate      int           // Bytes per second written to the Group io.Writers
+	leak            io.Writer     // Destination of detached runner output
+	stdoutTee       []io.Writer   // Additional sinks of the final stdout stream
+	stderrTee       []io.Writer   // Additional sinks of the final stderr stream
+	lineFilter      LineFilter
+	newDecoder      func() Transformer // Default source encoding of runner output
+	pipelineWriter  PipelineWriter
+	stages          []StageFunc // In pipeline order from head to tail
+	coalesce        int         // Maximum size of a coalesced queue chunk
+	compressBuffers bool        // Compress buffered chunks of background runners
+	expectedOutput  int         // Expected output bytes per runner for preallocation
+	jsonOutput      bool        // Output lines are written as JSON objects to stdout
+	framedOutput    bool        // Output writes are written as frames to stdout
+	compressor      Compressor  // Compresses the output of each runner stream
+	captureOutput   bool        // Retain a copy of each runner's output for Output()
+	mergeStderr     bool        // Runner stderr is written to the stdout stream (2>&1)
+	discardStdout   bool        // Default for runner stdout to be discarded
+	discardStderr   bool        // Default for runner stderr to be discarded
+	combined        bool        // stdout and stderr are the same io.Writer
+	suppressRepeats bool        // Collapse consecutive identical lines
+	stripANSI       bool        // Remove ANSI escape sequences from runner output
+	collapseCR      bool        // Keep only the final state of '\r' overwritten lines
+	collapse        bool        // Write byte-identical runner output once
+	commandPTY      bool        // Default for AddCommand to allocate a pseudo-terminal
+	delim           byte        // Terminates each line of output, normally '\n'
+	closeOnFinish   bool        // Close the Group io.Writers once Wait is done with them
 }
 
 // The default config is one which makes the output appear as it would as if runners were
@@ -41,7 +122,9 @@ type config struct {
 // For those wanting to mimic the defaults for GNU parallel, consider newGNUConfig.
 func newConfig() *config {
 	return &config{stdout: os.Stdout, stderr: os.Stderr,
-		orderRunners: true}
+		orderRunners: true, coalesce: defaultCoalesceLimit,
+		progressMode: TTYAlways, rrMode: TTYAlways, delim: '\n', tagSuffix: "\t",
+		clock: systemClock{}}
 }
 
 // newGNUConfig creates a config which mimics the defaults of the GNU parallel
@@ -49,12 +132,14 @@ func newConfig() *config {
 // only.
 func newGNUConfig() *config {
 	return &config{stdout: os.Stdout, stderr: os.Stderr,
-		orderRunners: false, orderStderr: true}
+		orderRunners: false, orderStderr: true, coalesce: defaultCoalesceLimit,
+		progressMode: TTYAlways, rrMode: TTYAlways, delim: '\n', tagSuffix: "\t",
+		clock: systemClock{}}
 }
 
 // foregroundAllowed returns true if config allows runners to switch to foreground mode.
 func (cfg *config) foregroundAllowed() bool {
-	return cfg.orderRunners && !cfg.orderStderr && !cfg.passthru
+	return cfg.orderRunners && !cfg.orderStderr && !cfg.passthru && !cfg.ungroup
 }
 
 // Option functions configure a [Group] when created with [NewGroup]. Each Option is
@@ -98,6 +183,7 @@ func (o option) apply(cfg *config) error {
 func LimitActiveRunners(maxActive uint) Option {
 	f := func(cfg *config) error {
 		cfg.limitRunners = maxActive
+		cfg.cpuFactor = 0
 
 		return nil // No error possible
 	}
@@ -105,6 +191,512 @@ func LimitActiveRunners(maxActive uint) Option {
 	return option(f)
 }
 
+// LimitActiveRunnersPerCPU is identical to [LimitActiveRunners] except that the limit is
+// computed as factor times [runtime.GOMAXPROCS] when [Group.Run] is called. The computed
+// limit is rounded down with a minimum of one. For example, a factor of 1 suits
+// CPU-bound RunFuncs while a factor of 0.5 leaves half the CPUs for the rest of the
+// program. The factor must be greater than zero.
+//
+// As with [LimitActiveRunners], the last of the two options supplied to [NewGroup]
+// applies and this option satisfies the requirements of [LimitMemoryPerRunner].
+func LimitActiveRunnersPerCPU(factor float64) Option {
+	f := func(cfg *config) error {
+		if !(factor > 0) || math.IsInf(factor, 1) {
+			return errors.New("LimitActiveRunnersPerCPU requires a positive factor")
+		}
+		cfg.cpuFactor = factor
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// cpuLimit returns the LimitActiveRunnersPerCPU limit for the current GOMAXPROCS.
+func (cfg *config) cpuLimit() uint {
+	limit := uint(cfg.cpuFactor * float64(runtime.GOMAXPROCS(0)))
+	if limit < 1 {
+		limit = 1
+	}
+
+	return limit
+}
+
+// LimitActiveRunnersAuto adaptively adjusts the number of “active” RunFuncs based on the
+// observed completion rate, somewhat like the load-based job slot adjustment of GNU
+// parallel. The number of active RunFuncs starts at [runtime.NumCPU] and is periodically
+// moved up or down by one depending on whether the completion rate of the most recent set
+// of RunFuncs improved on the previous set.
+//
+// The upper bound is [LimitActiveRunners], if set, otherwise four times
+// [runtime.NumCPU]. The lower bound is one.
+//
+// LimitActiveRunnersAuto is most useful when many RunFuncs of similar cost contend for
+// resources which are hard to predict, such as remote servers or disks. As with
+// [LimitActiveRunners], this option satisfies the requirements of [LimitMemoryPerRunner].
+func LimitActiveRunnersAuto() Option {
+	f := func(cfg *config) error {
+		cfg.autoRunners = true
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithPipelineWriter inserts an application writer stage into the pipeline of every
+// runner, once for each of stdout and stderr. The pw function is called as each pipeline
+// is constructed and returns an io.Writer which receives the RunFunc output and writes it
+// to next. The stage sees untagged output after any queueing, so it may filter, rewrite
+// or measure output without concern for the other runners. If the returned io.Writer is
+// also an io.Closer, it is closed once the RunFunc output is complete so that any held
+// back data can be written.
+//
+// The returned io.Writer is only called concurrently if the RunFunc itself writes
+// concurrently.
+func WithPipelineWriter(pw PipelineWriter) Option {
+	f := func(cfg *config) error {
+		if pw == nil {
+			return errors.New("Cannot supply nil function to WithPipelineWriter")
+		}
+		cfg.pipelineWriter = pw
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithStage inserts an application [Stage] into the pipeline of every runner, once for each
+// of stdout and stderr, at the same point as [WithPipelineWriter]. Multiple WithStage
+// options may be supplied and the Stages are inserted in the order supplied such that the
+// first Stage receives the output first. Any WithPipelineWriter stage precedes all
+// WithStage stages.
+func WithStage(sf StageFunc) Option {
+	f := func(cfg *config) error {
+		if sf == nil {
+			return errors.New("Cannot supply nil function to WithStage")
+		}
+		cfg.stages = append(cfg.stages, sf)
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithSourceEncoding converts the output of every RunFunc from a legacy source encoding
+// to UTF-8 before it is tagged, so that the output of legacy tools does not corrupt the
+// combined stream. newDecoder is called for each of stdout and stderr as each runner
+// pipeline is constructed as a [Transformer] holds conversion state. With
+// golang.org/x/text/encoding/charmap, for example:
+//
+//	parallel.WithSourceEncoding(func() parallel.Transformer {
+//		return charmap.Windows1252.NewDecoder()
+//	})
+//
+// Use [RunnerSourceEncoding] to set or override the source encoding of individual runners.
+// Conversion precedes [WithLineFilter] and all application stages.
+func WithSourceEncoding(newDecoder func() Transformer) Option {
+	f := func(cfg *config) error {
+		if newDecoder == nil {
+			return errors.New("Cannot supply nil function to WithSourceEncoding")
+		}
+		cfg.newDecoder = newDecoder
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithLineFilter drops every line of RunFunc output for which keep returns false, so that
+// noisy RunFuncs can be trimmed centrally rather than each RunFunc having to honour a
+// verbosity setting. keep is called with the stream and the line, excluding the line
+// delimiter, and is called for a trailing incomplete line once the RunFunc completes.
+// [DropLinePrefix] constructs a LineFilter for the common case of level prefixes such as
+// "DEBUG:". The filter precedes any [WithPipelineWriter] and [WithStage] stages.
+//
+// keep is only called concurrently if the RunFunc itself writes concurrently.
+func WithLineFilter(keep LineFilter) Option {
+	f := func(cfg *config) error {
+		if keep == nil {
+			return errors.New("Cannot supply nil function to WithLineFilter")
+		}
+		cfg.lineFilter = keep
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithHooks sets callbacks which are invoked as each RunFunc starts, finishes and has its
+// output flushed to the Group io.Writers. Hooks are typically used to update spinners,
+// logs or metrics. OnStart and OnFinish are not called for skipped RunFuncs. See [Hooks]
+// for constraints on what hooks may do.
+func WithHooks(hooks Hooks) Option {
+	f := func(cfg *config) error {
+		cfg.hooks = hooks
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithSignalHandling installs a handler for the supplied signals, typically
+// [os.Interrupt], for the duration of [Group.Run] to [Group.Wait], much like the way GNU
+// parallel handles SIGINT. On the first signal the Group stops starting new RunFuncs,
+// lets active RunFuncs finish and writes all buffered output in the normal order. Skipped
+// RunFuncs have their Errors() entry set to [ErrInterrupted] and [Group.Wait] returns an
+// error which includes ErrInterrupted.
+//
+// On the second signal, the context of every active [RunFuncCtx] is cancelled, all
+// unfinished RunFuncs are detached as described in [WithDetach] so that Wait returns
+// without waiting for them, and the signal handler is removed so that any further signal
+// takes its default action, which normally terminates the program. Output from detached
+// RunFuncs is written to the WithDetach io.Writer, if set, otherwise it is discarded.
+func WithSignalHandling(sigs ...os.Signal) Option {
+	f := func(cfg *config) error {
+		if len(sigs) == 0 {
+			return errors.New("WithSignalHandling requires at least one signal")
+		}
+		cfg.signals = sigs
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithDumpSignal installs a handler for the supplied diagnostic signals, such as
+// syscall.SIGUSR1 or syscall.SIGQUIT, for the duration of [Group.Run] to [Group.Wait]. Each
+// time a signal is received, the state of every RunFunc whose output has not yet been
+// written is reported to the Group stderr, along with a copy of any output buffered on its
+// behalf. This helps users see what stuck background RunFuncs have produced so far.
+// Buffered output is not otherwise affected.
+func WithDumpSignal(sigs ...os.Signal) Option {
+	f := func(cfg *config) error {
+		if len(sigs) == 0 {
+			return errors.New("WithDumpSignal requires at least one signal")
+		}
+		cfg.dumpSignals = sigs
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithRunnerFooter sets a function which is called once each RunFunc's output has been
+// flushed to return a footer line which is written to the Group stdout, before any
+// separator. As the footer function is supplied with the RunFunc duration and byte
+// counts, it can produce summaries which the RunFunc itself cannot. The footer is written
+// verbatim, so it should normally end with a newline. An empty footer is not written,
+// nor are footers written for skipped RunFuncs.
+//
+// WithRunnerFooter cannot be set with [WithJSONOutput], [WithFramedOutput] or
+// [WithCompression] as the footer would corrupt the encoded output.
+func WithRunnerFooter(footer func(info RunnerInfo) string) Option {
+	f := func(cfg *config) error {
+		cfg.footer = footer
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithTracer causes a span to be created for each RunFunc which starts when the RunFunc
+// is dispatched and ends once its output has been written to the Group io.Writers. Spans
+// are children of the context supplied to [Group.RunContext] and are the parent of the
+// context supplied to each [RunFuncCtx]. Spans have attributes for the runner index, tag,
+// output byte counts, outcome and error. See [Tracer] for how to adapt OpenTelemetry.
+func WithTracer(tracer Tracer) Option {
+	f := func(cfg *config) error {
+		if tracer == nil {
+			return errors.New("Cannot supply nil Tracer to WithTracer")
+		}
+		cfg.tracer = tracer
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithLogger causes the Group to log internal events at [slog.LevelDebug], namely when
+// each RunFunc is dispatched, skipped, completed and flushed, when a runner is switched to
+// foreground, when a Write is stalled by [LimitMemoryPerRunner] and when writing buffered
+// output to the Group io.Writers fails. This is mostly useful when diagnosing why output
+// appears to be stuck. Each record includes the runner index and tag.
+func WithLogger(logger *slog.Logger) Option {
+	f := func(cfg *config) error {
+		if logger == nil {
+			return errors.New("Cannot supply nil Logger to WithLogger")
+		}
+		cfg.logger = logger
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithStallWarning calls fn whenever no RunFunc has completed for duration d while
+// [Group.Wait] is waiting, reporting which RunFuncs are still running and how much output
+// has been buffered on their behalf. This replaces a silent hang with something
+// diagnosable when a RunFunc never returns. While the stall continues, fn is called again
+// every d. The fn is called from the Wait goroutine, so Wait is stalled until fn returns.
+func WithStallWarning(d time.Duration, fn func(StallInfo)) Option {
+	f := func(cfg *config) error {
+		if d <= 0 {
+			return errors.New("WithStallWarning requires a positive duration")
+		}
+		if fn == nil {
+			return errors.New("Cannot supply nil function to WithStallWarning")
+		}
+		cfg.stallAfter = d
+		cfg.stallFunc = fn
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithNotify sends the [RunnerResult] of every runner on ch once all of its output has
+// been written to the Group io.Writers, in the same order as the output is written. ch is
+// closed once [Group.Wait] completes so that a receiving goroutine can simply range over
+// it. The send blocks the Group until it is received, so ch should be buffered or
+// serviced by a dedicated goroutine. See [RunnerNotify] for notification of individual
+// runners.
+func WithNotify(ch chan<- RunnerResult) Option {
+	f := func(cfg *config) error {
+		if ch == nil {
+			return errors.New("Cannot supply nil channel to WithNotify")
+		}
+		cfg.notify = ch
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithOnBlocked calls fn whenever a background RunFunc blocks in Write because its
+// buffered output has reached [LimitMemoryPerRunner]. The buffered argument is the number
+// of bytes of output buffered for the RunFunc at the time. This lets applications log,
+// expose metrics or reconsider their limits rather than discovering the stall by
+// accident. The fn is called from the goroutine of the blocked RunFunc, so it should
+// return promptly and must not call Group methods.
+func WithOnBlocked(fn func(info RunnerInfo, buffered uint64)) Option {
+	f := func(cfg *config) error {
+		if fn == nil {
+			return errors.New("Cannot supply nil function to WithOnBlocked")
+		}
+		cfg.onBlocked = fn
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithOnThrottled calls fn when a background RunFunc's buffered output first exceeds the
+// [SoftLimitMemoryPerRunner] watermark and its Writes start being throttled. The
+// buffered argument is the number of bytes of output buffered for the RunFunc at the
+// time. As with [WithOnBlocked], fn is called from the goroutine of the throttled RunFunc,
+// so it should return promptly and must not call Group methods.
+func WithOnThrottled(fn func(info RunnerInfo, buffered uint64)) Option {
+	f := func(cfg *config) error {
+		if fn == nil {
+			return errors.New("Cannot supply nil function to WithOnThrottled")
+		}
+		cfg.onThrottled = fn
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithScheduler replaces the default [FIFOScheduler] with a custom [Scheduler] which
+// decides the order in which runners are dispatched to workers and, with
+// OrderRunners(false), which runner is promoted to foreground. Dispatch order is most
+// useful in conjunction with [LimitActiveRunners], otherwise all runners are dispatched
+// immediately. With OrderRunners(true), the foreground runner is always the oldest
+// runner, so the Scheduler only affects dispatch order. As runners dispatched ahead of the
+// oldest runner could then block on their [LimitMemoryPerRunner] limit and hold every
+// slot, a Scheduler other than FIFOScheduler cannot be combined with OrderRunners(true)
+// and LimitMemoryPerRunner unless [WithSpillDir] is also set.
+func WithScheduler(s Scheduler) Option {
+	f := func(cfg *config) error {
+		if s == nil {
+			return errors.New("Cannot supply nil Scheduler to WithScheduler")
+		}
+		cfg.scheduler = s
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithCollapse groups runners which produce byte-identical output, much like the
+// “collapse” mode of pssh and dshbak, so that output common to many runners is written
+// once rather than once per runner. This drastically shrinks the output of commands run
+// across a fleet of hosts. Each distinct output is written, in the order of first
+// appearance, with each line tagged by the combined tags of all runners which produced
+// it. Any trailing separator common to the tags is written once so that runners tagged
+// "host1: " and "host2: " with identical output are written as "host1,host2: ". Runners
+// are only grouped if both their stdout and stderr output are identical.
+//
+// As identical output can only be identified once all runners have completed, nothing is
+// written until then, and all output is held in memory. Consequently WithCollapse cannot
+// be set with [LimitMemoryPerRunner], nor with options which write output as it is
+// produced or per runner: [Passthru], [Ungroup], [WithRoundRobin], [WithJSONOutput],
+// [WithFramedOutput], [WithReport], [WithSectionHeaders], [WithCompression],
+// [WithRunnerFooter], [WithTagColors] and separators.
+func WithCollapse(on bool) Option {
+	f := func(cfg *config) error {
+		cfg.collapse = on
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithSectionHeaders causes a header line to be written to stdout before the output block
+// of each runner, independent of any per-line tags. The header is formatted with
+// fmt.Sprintf(format, name), where name is set by [RunnerName] or, if not set, is the
+// OutTag with surrounding white space removed, and a trailing newline is appended if
+// format lacks one. For example:
+//
+//	WithSectionHeaders("===== %s =====")
+//
+// writes "===== host1 =====" before the output of a runner named "host1". The header is
+// written immediately before the first output of the runner, so runners which produce no
+// output have no header. As headers only make sense when output is grouped by runner,
+// WithSectionHeaders cannot be set with [Passthru], [Ungroup], [WithCompression],
+// [WithJSONOutput] or [WithFramedOutput].
+func WithSectionHeaders(format string) Option {
+	f := func(cfg *config) error {
+		if len(format) == 0 {
+			return errors.New("Cannot supply empty format to WithSectionHeaders")
+		}
+		cfg.sectionFormat = format
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithReport renders the output of each runner as a collapsible Markdown or HTML section
+// so that CI tools can publish a readable report of a batch run directly from the Group.
+// Each section is titled with the runner name, as described by [WithSectionHeaders], and
+// a status badge of "completed", "failed", "panicked", "timed out" or "skipped". Sections
+// of runners which did not complete successfully are expanded by default.
+//
+// All output of a runner, including stderr, is held until the RunFunc returns so that the
+// status is known when the section is written to the Group stdout. ReportHTML produces an
+// HTML fragment suitable for embedding in a page and ReportMarkdown produces
+// GitHub-flavoured Markdown. As the whole output of each runner is held in memory,
+// WithReport cannot be set with [LimitMemoryPerRunner]. Nor can it be set with
+// [Passthru], [Ungroup], [WithJSONOutput] or [WithFramedOutput].
+func WithReport(format ReportFormat) Option {
+	f := func(cfg *config) error {
+		if format < ReportNone || format > ReportHTML {
+			return errors.New("Cannot supply unknown ReportFormat to WithReport")
+		}
+		cfg.report = format
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithDetach causes [Group.WaitContext] to detach all unfinished RunFuncs when its context
+// is done, so that WaitContext returns with the Group done rather than leaving RunFuncs
+// outstanding. This accepts leaked goroutines in preference to hanging the program.
+//
+// Detached RunFuncs which have not started are skipped and active RunFuncs have their
+// context cancelled. Any output already buffered for a detached RunFunc, and all output it
+// subsequently writes, is written to leak, or discarded if leak is nil. A detached RunFunc
+// stalled by [LimitMemoryPerRunner] remains stalled. Detached runners have their Errors()
+// entry set to [ErrDetached] and no other runner details are reported as they may still
+// be changing.
+func WithDetach(leak io.Writer) Option {
+	f := func(cfg *config) error {
+		if leak == nil {
+			leak = io.Discard
+		}
+		cfg.detachOn = true
+		cfg.leak = leak
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithClock replaces the system clock used by all timing-dependent features of the Group
+// with clock. It is intended for tests which supply a [TestClock] so that they can
+// control time rather than rely on real sleeps. See [Clock].
+func WithClock(clock Clock) Option {
+	f := func(cfg *config) error {
+		if clock == nil {
+			return errors.New("Cannot supply nil Clock to WithClock")
+		}
+		cfg.clock = clock
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithStartDelay ensures that RunFuncs are started no closer together than delay, much like
+// the GNU parallel “--delay” option. This is useful when each RunFunc connects to the same
+// remote service which may be overwhelmed by a flood of simultaneous connections. A
+// delay of zero disables the delay. RunFuncs which are skipped, such as when the Group
+// context is cancelled, are not delayed.
+func WithStartDelay(delay time.Duration) Option {
+	f := func(cfg *config) error {
+		if delay < 0 {
+			return errors.New("Cannot set WithStartDelay to a negative duration")
+		}
+		cfg.startEvery = delay
+		cfg.startBurst = 1
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithStartRate limits the rate at which RunFuncs are started to n per period, with up to
+// n RunFuncs able to start at once. This is a classic token bucket which is well suited to
+// RunFuncs which call a rate-limited remote API. WithStartRate(1, delay) is equivalent to
+// [WithStartDelay](delay).
+func WithStartRate(n int, period time.Duration) Option {
+	f := func(cfg *config) error {
+		if n <= 0 || period <= 0 {
+			return errors.New("WithStartRate requires a positive count and period")
+		}
+		cfg.startEvery = period / time.Duration(n)
+		cfg.startBurst = n
+
+		return nil
+	}
+
+	return option(f)
+}
+
 // LimitMemoryPerRunner limits the number of output bytes buffered for each [RunFunc] before
 // being stalled on their Write() call. This setting is mostly of use when RunFuncs may
 // generate multiple MBytes of output, otherwise the benefits are likely to be minimal.
@@ -117,15 +709,721 @@ func LimitActiveRunners(maxActive uint) Option {
 // generates very large amounts of output which may exhaust available memory, then setting
 // LimitMemoryPerRunner and [LimitActiveRunners] to non-zero values is a good strategy.
 //
-// This limit only affects background RunFuncs as the foreground [RunFunc] writes directly
-// to the Group output io.Writers. Ultimately all background RunFuncs switched to
-// foreground mode so reaching this limit only ever temporarily stalls a [RunFunc].
+// This limit only affects background RunFuncs as the foreground [RunFunc] writes directly
+// to the Group output io.Writers. Ultimately all background RunFuncs switched to
+// foreground mode so reaching this limit only ever temporarily stalls a [RunFunc]. Use
+// [WithOnBlocked] to be notified when a RunFunc stalls and [SoftLimitMemoryPerRunner] to
+// slow a RunFunc down before it reaches this limit.
+//
+// With [OrderRunners] == false, there is no natural foreground [RunFunc], so whenever a
+// RunFunc stalls and no RunFunc is in foreground, the stalled RunFunc with the most
+// buffered output is switched to foreground. Output of other RunFuncs completing in the
+// meantime is deferred until the foreground RunFunc completes.
+//
+// LimitMemoryPerRunner cannot be set with [OrderStderr] == true as that could cause a
+// [RunFunc] to stall indefinitely, unless [WithSpillDir] is also set.
+func LimitMemoryPerRunner(limit uint64) Option {
+	f := func(cfg *config) error {
+		cfg.limitMemory = limit
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// SoftLimitMemoryPerRunner sets a soft watermark below [LimitMemoryPerRunner] for the
+// output buffered by each background [RunFunc]. Once the buffered output exceeds soft,
+// each subsequent Write returns after a delay which grows linearly from zero at the soft
+// watermark to maxDelay at the LimitMemoryPerRunner hard limit. This slows a prolific
+// RunFunc progressively rather than stopping it dead at the hard limit, which gives
+// smoother behaviour overall. Beyond the hard limit, Writes block, or spill if
+// [WithSpillDir] is set, as usual. Throttling stops as soon as the RunFunc switches to
+// foreground. Use [WithOnThrottled] to be notified when a RunFunc is first throttled.
+//
+// SoftLimitMemoryPerRunner requires LimitMemoryPerRunner to be set to a larger value.
+func SoftLimitMemoryPerRunner(soft uint64, maxDelay time.Duration) Option {
+	f := func(cfg *config) error {
+		if soft == 0 {
+			return errors.New("Cannot set SoftLimitMemoryPerRunner to zero")
+		}
+		if maxDelay <= 0 {
+			return errors.New("SoftLimitMemoryPerRunner requires a positive duration")
+		}
+		cfg.softMemory = soft
+		cfg.throttleDelay = maxDelay
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// OpenEnded allows [Group.Add] to be called after [Group.Run] so that producers can feed
+// work discovered at run-time, such as when walking a directory tree, while earlier
+// RunFuncs are already running. In an OpenEnded Group, Add and [Group.CloseAdd] can be
+// called from any goroutine, including concurrently with [Group.Wait].
+//
+// An OpenEnded Group *must* have [Group.CloseAdd] called once all RunFuncs have been
+// added, otherwise [Group.Wait] never returns. The default is false.
+func OpenEnded(setting bool) Option {
+	f := func(cfg *config) error {
+		cfg.openEnded = setting
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// OrderRunners causes output to being written in strict order of [RunFunc] addition to
+// the [Group]. If set false output is in order of runner completion. This option exists
+// to mimic the GNU parallel “--keep-order” option. The default is true (which differs
+// from the default for “--keep-order”).
+//
+// When OrderRunners is set false with [LimitMemoryPerRunner], output is still grouped by
+// RunFunc, but a stalled RunFunc may be switched to foreground ahead of RunFuncs which
+// have already completed. See [LimitMemoryPerRunner].
+func OrderRunners(setting bool) Option {
+	f := func(cfg *config) error {
+		cfg.orderRunners = setting
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// OrderBy causes output to be written in the order defined by less rather than in order of
+// [RunFunc] addition, such as alphabetically by filename. less reports whether runner i
+// sorts before runner j. The sort is stable, so runners which compare equal retain their
+// addition order. Only the Index, OutTag and ErrTag fields of [RunnerInfo] are set.
+//
+// Runners are sorted once [Group.Run] is called, after which RunFuncs are also dispatched
+// in the sorted order. As all runners must be known before sorting, OrderBy cannot be set
+// with [OpenEnded] and, as it is a variation of ordered output, it cannot be set with
+// OrderRunners(false). Methods which report on individual runners, such as
+// [Group.Errors], remain in order of addition.
+func OrderBy(less func(i, j RunnerInfo) bool) Option {
+	f := func(cfg *config) error {
+		if less == nil {
+			return errors.New("Cannot supply nil function to OrderBy")
+		}
+		cfg.orderBy = less
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// OrderStderr causes all stderr output to be written *after* all stdout output for each
+// [RunFunc]. This can result in an output stream which differs from one written by a
+// [RunFunc] run serially and writing directly to os.Stdout and os.Stderr. This option
+// exists to mimic the GNU parallel “--group” option.
+//
+// When OrderStderr is set true, [LimitMemoryPerRunner] cannot be set true as it creates
+// a situation when all runners could stall indefinitely, unless [WithSpillDir] is also
+// set.
+func OrderStderr(setting bool) Option {
+	f := func(cfg *config) error {
+		cfg.orderStderr = setting
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithRoundRobin interleaves the output of all active RunFuncs by rotating access to the
+// Group io.Writers between them. This is intended for interactive monitoring where the
+// user wants to watch all RunFuncs progress concurrently. Output is only ever written as
+// complete lines and is tagged as usual so that each line remains attributable to its
+// RunFunc, thus tags are strongly recommended.
+//
+// If slice is zero, each RunFunc with pending output writes one line in turn, otherwise
+// each RunFunc writes pending lines for up to slice before the next RunFunc has its turn.
+// Any incomplete final line is written when the RunFunc completes. Use
+// [WithRoundRobinMode] to only interleave output written to a terminal.
+//
+// As output is interleaved, WithRoundRobin cannot be set with OrderRunners(true), so
+// OrderRunners(false) must also be set. Nor can it be set with [LimitMemoryPerRunner],
+// [OrderStderr], [Passthru], [Ungroup], [WithCompression], [WithJSONOutput],
+// [WithFramedOutput], [WithReport] or [WithSectionHeaders].
+func WithRoundRobin(slice time.Duration) Option {
+	f := func(cfg *config) error {
+		if slice < 0 {
+			return errors.New("Cannot set WithRoundRobin to a negative duration")
+		}
+		cfg.roundRobin = true
+		cfg.rrSlice = slice
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithRoundRobinMode determines when [WithRoundRobin] is applied. The default is
+// [TTYAlways]. If set to [TTYAuto], output is only interleaved if the Group stdout is a
+// terminal, otherwise the output of each RunFunc is grouped as usual. This suits a user
+// watching output live while keeping redirected output readable.
+func WithRoundRobinMode(mode TTYMode) Option {
+	f := func(cfg *config) error {
+		cfg.rrMode = mode
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// Passthru is a debug setting. When set true all output is transferred more or less
+// directly to the Group io.Writers. In effect, the Group pipeline plays a very limited
+// part in managing the output stream. Tags are still applied, but as output is not
+// serialised, partial lines from concurrent RunFuncs may be intermingled.
+//
+// If this option is set true the following options cannot be set true:
+// [LimitMemoryPerRunner], [OrderStderr] and [OrderRunners].
+func Passthru(setting bool) Option {
+	f := func(cfg *config) error {
+		cfg.passthru = setting
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithTagColors causes each RunFunc's tags to be rendered in a distinct terminal color by
+// cycling thru the supplied palette in the order RunFuncs are added to the [Group]. Each
+// palette entry is an ANSI SGR parameter string such as "31" for red or "1;34" for bold
+// blue. If no palette is supplied, a default palette of six foreground colors is used.
+//
+// Coloring is automatically disabled for the Group stdout or stderr if it is not a
+// terminal, so it is safe to set this option regardless of where output is redirected.
+// This can be changed with [WithColorMode]. Any trailing whitespace in a tag, such as the
+// customary "\t", is left uncolored. Only the tags written with the output are colored;
+// tags reported elsewhere, such as in [RunnerInfo] and the [WithJobLog] job log, are not.
+func WithTagColors(palette ...string) Option {
+	f := func(cfg *config) error {
+		if len(palette) == 0 {
+			palette = defaultTagColors
+		}
+		cfg.tagColors = palette
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithColorMode determines when [WithTagColors] is applied. The default of [TTYAuto]
+// colors the tags of each Group io.Writer only if it is a terminal. [TTYAlways] colors
+// tags regardless, such as when output is piped to “less -R”, and [TTYNever] disables
+// coloring without needing to remove WithTagColors.
+func WithColorMode(mode TTYMode) Option {
+	f := func(cfg *config) error {
+		cfg.colorMode = mode
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// Ungroup causes all output to be written to the Group io.Writers as soon as it is
+// written by each [RunFunc], much like the GNU parallel “--ungroup” option. Tags and
+// separators are still applied and, unlike [Passthru], each tagged line is written
+// atomically so that tags remain meaningful even though the output of different RunFuncs
+// is intermingled. This is useful for long-running monitoring style RunFuncs where
+// liveliness matters more than grouping. Separators are written once a RunFunc returns,
+// prior to the next output of any RunFunc.
+//
+// If this option is set true the following options cannot be set true:
+// [LimitMemoryPerRunner], [OrderStderr], [OrderRunners] and [Passthru].
+func Ungroup(setting bool) Option {
+	f := func(cfg *config) error {
+		cfg.ungroup = setting
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithHalt sets the policy for halting the [Group] early based on the success or failure
+// of RunFuncs, mirroring the GNU parallel “--halt” option. A RunFunc fails if it returns
+// a non-nil error (see [Group.AddErr]) or panics. The policy string is one of:
+//
+//	never			Never halt (the default)
+//	soon,fail=N		Stop starting new RunFuncs once N RunFuncs have failed
+//	soon,fail=N%		Stop starting new RunFuncs once N% of all RunFuncs have failed
+//	now,fail=N		As for soon, and also cancel the context of active RunFuncs
+//	now,fail=N%		As for soon, and also cancel the context of active RunFuncs
+//
+// "fail" can be replaced with "success" to halt on RunFunc success, or "done" to halt on
+// RunFunc completion regardless of outcome.
+//
+// Once halted, RunFuncs which have not yet started are skipped and have [ErrHalted]
+// recorded against them. Active RunFuncs are never forcibly stopped as that is not
+// possible with goroutines; with a "now" policy only those added with
+// [Group.AddContext] are notified, via their context. [Group.Wait] returns an error
+// which includes [ErrHalted].
+func WithHalt(policy string) Option {
+	f := func(cfg *config) error {
+		hp, err := parseHaltPolicy(policy)
+		if err != nil {
+			return err
+		}
+		cfg.haltPolicy = hp
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithJobLog writes one line to w for each completed RunFunc, much like the GNU parallel
+// “--joblog” option. The first line written is a header naming the tab-separated
+// fields which are:
+//
+//	Seq        The order in which the RunFunc was added, starting at 1
+//	Tag        The stdout tag with surrounding whitespace removed
+//	Starttime  Seconds since the Unix epoch when the RunFunc was started
+//	JobRuntime Seconds the RunFunc ran for
+//	Stdout     Bytes written by the RunFunc to stdout
+//	Stderr     Bytes written by the RunFunc to stderr
+//	Error      The error returned by the RunFunc, quoted, or "-" if nil
+//
+// Lines are written in the order in which RunFunc output is transferred to the Group
+// io.Writers. Skipped RunFuncs, such as those not started due to [WithHalt], have zero
+// start and run times. Use [WithJobLogFormat] to select an alternative encoding.
+func WithJobLog(w io.Writer) Option {
+	f := func(cfg *config) error {
+		if w == nil {
+			return errors.New("Cannot supply nil io.Writer to WithJobLog")
+		}
+		cfg.jobLog = w
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithJobLogFormat selects the encoding of [WithJobLog] so that the job log can be
+// consumed by spreadsheets or log pipelines without post-processing. The formats are:
+//
+//	JobLogParallel The default GNU parallel style described by WithJobLog
+//	JobLogTSV      As JobLogParallel except that the Tag and Error fields are not quoted,
+//	               rather tab, newline, carriage return and backslash are escaped as \t,
+//	               \n, \r and \\
+//	JobLogCSV      RFC 4180 comma-separated values with the same header and fields as
+//	               JobLogParallel except that Error is empty if nil
+//	JobLogJSON     One JSON object per line with no header. The fields are named seq,
+//	               tag, starttime, jobRuntime, stdout, stderr and error, which is null if
+//	               nil
+//
+// [WithResume] accepts a job log in any of these formats.
+func WithJobLogFormat(format JobLogFormat) Option {
+	f := func(cfg *config) error {
+		if format < JobLogParallel || format > JobLogJSON {
+			return errors.New("Cannot set WithJobLogFormat to an unknown format")
+		}
+		cfg.jobLogFormat = format
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithRecorder records every Write by every RunFunc, along with when each RunFunc starts
+// and finishes, to w so that the session can later be re-emitted with [Replay] in real
+// time or accelerated. Each Write is recorded exactly as written by the RunFunc, with its
+// runner, stream and time offset, before it passes thru the rest of the pipeline. The
+// recording is a series of JSON objects, one per line. Skipped runners are not recorded.
+//
+// Recording stops at the first error writing to w. w is written concurrently by the
+// RunFuncs so it should not be one of the Group io.Writers.
+func WithRecorder(w io.Writer) Option {
+	f := func(cfg *config) error {
+		if w == nil {
+			return errors.New("Cannot supply nil io.Writer to WithRecorder")
+		}
+		cfg.recording = w
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithResume reads a job log previously written by [WithJobLog], in any [JobLogFormat],
+// and skips any RunFunc whose tag is recorded as having completed without error. This
+// allows a partially failed batch to be re-run such that only the failed or missing
+// RunFuncs are run again, much like the GNU parallel “--resume-failed” option. RunFuncs
+// are identified by their stdout tag with surrounding whitespace removed, so tags should
+// be unique within a Group. RunFuncs with an empty tag are never skipped, nor are
+// RunFuncs whose tag is escaped in a JobLogTSV job log.
+//
+// Skipped RunFuncs produce no output, no separators, no error and no job log line, so it
+// is normal to supply the same file, opened for appending, to both WithResume and
+// [WithJobLog].
+//
+// r is only read the first time the Option is applied, so the same Option can be passed
+// to [ValidateOptions] and then to NewGroup.
+func WithResume(r io.Reader) Option {
+	var once sync.Once
+	var done map[string]bool
+	var err error
+	f := func(cfg *config) error {
+		if r == nil {
+			return errors.New("Cannot supply nil io.Reader to WithResume")
+		}
+		once.Do(func() { done, err = parseJobLog(r) })
+		if err != nil {
+			return err
+		}
+		cfg.resume = done // Never modified so it can be shared
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithShard only runs the RunFuncs which belong to shard index of total shards, so that
+// the same list of RunFuncs can be split across multiple processes or machines without
+// an external coordinator. Each process adds the same RunFuncs in the same order and
+// supplies the same total with a different index, from zero to total-1. RunFuncs are
+// assigned to shards in turn in the order in which they were added, so every RunFunc is
+// run by exactly one shard and the shard sizes differ by at most one.
+//
+// RunFuncs belonging to other shards produce no output, no separators, no error and no
+// job log line, just as with [WithResume], and have an [Outcome] of Skipped.
+func WithShard(index, total int) Option {
+	f := func(cfg *config) error {
+		if total <= 0 {
+			return errors.New("WithShard requires a positive total")
+		}
+		if index < 0 || index >= total {
+			return errors.New("WithShard index must be from zero to total-1")
+		}
+		cfg.shardIndex = index
+		cfg.shardTotal = total
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// DedupRunners calls keyFn as each RunFunc is added and only runs the first RunFunc
+// started with each key, such as when the same argument appears more than once in a
+// generated list of work. RunFuncs are started in the order they were added unless a
+// [Scheduler] chooses otherwise. keyFn is called with the [RunnerInfo] available at the time of
+// addition, which excludes the error, slot and statistics. RunFuncs for which keyFn
+// returns an empty key are always run.
+//
+// By default duplicates are skipped, as reported by [Group.RunnerResult], and produce no
+// output. Set [ReplayDuplicates] to have each duplicate instead write the output of the
+// first RunFunc under its own tags.
+func DedupRunners(keyFn func(RunnerInfo) string) Option {
+	f := func(cfg *config) error {
+		if keyFn == nil {
+			return errors.New("Cannot supply nil function to DedupRunners")
+		}
+		cfg.dedupKey = keyFn
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// ReplayDuplicates causes each duplicate identified by [DedupRunners] to wait for the
+// first RunFunc with the same key to complete, then write its output and return its
+// error as if the duplicate had run it. The output is replayed through the duplicate's
+// own pipeline, so it is tagged, ordered and recorded under the duplicate. All stdout
+// is written before any stderr, and the output of each first RunFunc is retained in
+// memory until the Group is done. The default is false.
+func ReplayDuplicates(on bool) Option {
+	f := func(cfg *config) error {
+		cfg.dedupReplay = on
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithProgress periodically writes a status line to w showing how many RunFuncs have
+// completed, are active and are pending, along with the elapsed time and an estimated
+// time to completion, much like the GNU parallel “--eta” option. The status line is
+// rewritten in place with a leading carriage return so w is normally a terminal such as
+// [os.Stderr]. A final status line and newline are written when [Group.Wait] returns.
+//
+// Status lines are written while holding the same mutex used to serialise writes to the
+// Group io.Writers so they never split the output written by a RunFunc pipeline. Use
+// [WithProgressMode] to only write progress to a terminal.
+func WithProgress(w io.Writer) Option {
+	f := func(cfg *config) error {
+		if w == nil {
+			return errors.New("Cannot supply nil io.Writer to WithProgress")
+		}
+		cfg.progress = w
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithProgressMode determines when [WithProgress] is applied. The default is [TTYAlways].
+// If set to [TTYAuto], progress is only written if the WithProgress io.Writer is a
+// terminal, so that progress lines do not pollute redirected output.
+func WithProgressMode(mode TTYMode) Option {
+	f := func(cfg *config) error {
+		cfg.progressMode = mode
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithJSONOutput causes each line of RunFunc output to be written to the Group stdout as
+// a JSON object, one object per line, rather than as raw text. This allows downstream
+// programs to process the output of a Group by machine. Each object has the form:
+//
+//	{"seq":3,"tag":"host1","stream":"stdout","data":"line of output"}
+//
+// where seq is the order in which the RunFunc was added, starting at 1, tag is the outTag
+// supplied to Add with surrounding whitespace removed, stream is "stdout" or "stderr" and
+// data is the line without its trailing newline. Output from both streams is written to
+// the Group stdout and output ordering is unchanged.
+//
+// WithJSONOutput cannot be set with separators, [WithTagColors] or [Passthru] as they
+// would corrupt the JSON stream.
+func WithJSONOutput(on bool) Option {
+	f := func(cfg *config) error {
+		cfg.jsonOutput = on
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithFramedOutput causes each Write by a RunFunc to be written to the Group stdout as a
+// length-prefixed frame, allowing a wrapper program to demultiplex the combined output of
+// all RunFuncs losslessly, even when the output contains newlines or binary data. Each
+// frame consists of a header line followed by the payload:
+//
+//	<seq> <stream> <length>\n<payload>
+//
+// where seq is the order in which the RunFunc was added, starting at 1, stream is "stdout"
+// or "stderr" and length is the number of payload bytes. Tags are not written. Use
+// [NewFrameDecoder] to decode frames.
+//
+// WithFramedOutput has the same constraints as [WithJSONOutput] and cannot be set with it.
+func WithFramedOutput(on bool) Option {
+	f := func(cfg *config) error {
+		cfg.framedOutput = on
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithCompression gzip compresses the output of each RunFunc such that each RunFunc's
+// stdout and stderr land in the Group io.Writers as independent gzip members. As
+// concatenated gzip members form a valid gzip stream, this is useful when the Group
+// stdout is redirected to a large archive file. The level is as defined by
+// [compress/gzip]. Tags are compressed along with the output. RunFuncs which produce no
+// output produce no gzip member.
+//
+// As each compressed member must be contiguous, WithCompression cannot be set with
+// separators, [Ungroup] or [Passthru]. For the same reason, the Group stdout and stderr
+// should not be the same io.Writer unless [OrderStderr] is set.
+func WithCompression(level int) Option {
+	f := func(cfg *config) error {
+		_, err := gzip.NewWriterLevel(io.Discard, level)
+		if err != nil {
+			return err
+		}
+		cfg.compressor = func(w io.Writer) io.WriteCloser {
+			zw, _ := gzip.NewWriterLevel(w, level)
+			return zw
+		}
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithCompressor is identical to [WithCompression] except that the application supplies
+// the compression algorithm, such as zstd, by way of the [Compressor] function.
+func WithCompressor(c Compressor) Option {
+	f := func(cfg *config) error {
+		if c == nil {
+			return errors.New("Cannot supply nil Compressor to WithCompressor")
+		}
+		cfg.compressor = c
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// SuppressRepeats collapses consecutive identical lines written by a RunFunc to the same
+// stream into a single line followed by a syslog-style message of the form:
+//
+//	last line repeated 12 times
+//
+// The message carries the same tag as the line it refers to. As lines are compared in
+// their entirety, an incomplete line is not written until its trailing newline is written
+// or the RunFunc completes. SuppressRepeats has no effect with [WithJSONOutput] or
+// [WithFramedOutput].
+func SuppressRepeats(on bool) Option {
+	f := func(cfg *config) error {
+		cfg.suppressRepeats = on
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// StripANSI removes ANSI escape sequences, such as colours and cursor movement, from all
+// RunFunc output. Buffered and reordered output which replays cursor movement sequences
+// long after they were written can otherwise wreck the terminal. Escape sequences split
+// across Writes are still removed, but an incomplete sequence at the end of the RunFunc
+// output is discarded. Tag colours applied by [WithTagColors] are not affected.
+func StripANSI(on bool) Option {
+	f := func(cfg *config) error {
+		cfg.stripANSI = on
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// CollapseProgress keeps only the final state of each line which a RunFunc overwrites with
+// carriage returns, such as the progress lines written by wget, curl and tar, so that
+// grouped output does not contain thousands of intermediate progress frames. The final
+// state is the text following the last '\r' in the line. A '\r' immediately preceding the
+// line delimiter is retained as part of a CRLF terminator.
+//
+// Collapsing only applies while RunFunc output is buffered. Once a RunFunc is switched to
+// foreground its progress lines are written as-is so that they remain animated on the
+// terminal. CollapseProgress has no effect with [Passthru], [Ungroup] or [WithRoundRobin]
+// as their output is never buffered.
+func CollapseProgress(on bool) Option {
+	f := func(cfg *config) error {
+		cfg.collapseCR = on
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithLineDelimiter sets the byte which terminates each line of RunFunc output, replacing
+// the default of '\n'. Tagging, [SuppressRepeats], [WithJSONOutput] and [WithRoundRobin]
+// all operate on records terminated by delim, so setting it to '\x00' supports “find
+// -print0” style output end to end. A tag is prepended to each NUL-terminated record and
+// the SuppressRepeats message is also terminated by delim. Separators, section headers and
+// other presentation output are written as supplied.
+func WithLineDelimiter(delim byte) Option {
+	f := func(cfg *config) error {
+		cfg.delim = delim
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// DiscardStdout causes all RunFunc stdout output to be discarded, much like redirecting
+// to /dev/null in a shell. Discarded output bypasses the rest of the pipeline so it is
+// never buffered and does not count towards [LimitMemoryPerRunner]. Individual RunFuncs
+// can override this setting with [RunnerDiscardStdout].
+func DiscardStdout(on bool) Option {
+	f := func(cfg *config) error {
+		cfg.discardStdout = on
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// DiscardStderr is the stderr equivalent of [DiscardStdout]. Individual RunFuncs can
+// override this setting with [RunnerDiscardStderr].
+func DiscardStderr(on bool) Option {
+	f := func(cfg *config) error {
+		cfg.discardStderr = on
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithCommandPTY causes each command run by [Group.AddCommand] to have its stdout and
+// stderr connected to a newly allocated pseudo-terminal rather than to pipes. Many tools
+// only colorize their output or report progress when writing to a terminal and this
+// setting lets them behave as they would interactively. As a terminal has a single output
+// stream, all command output arrives on the runner stdout stream and is tagged with the
+// outTag. Output post-processing is disabled on the terminal so lines are not rewritten
+// with carriage returns. Individual commands can override this setting with [RunnerPTY].
+//
+// Pseudo-terminals are currently only supported on Linux. On other platforms commands
+// requiring a pseudo-terminal fail to start.
+func WithCommandPTY(on bool) Option {
+	f := func(cfg *config) error {
+		cfg.commandPTY = on
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithTagSuffix appends suffix to every non-empty outTag and errTag supplied to the Add
+// variants, such as [Group.Add], so that callers can pass bare argument strings as tags
+// and have the separator applied uniformly, much like the “--tag” option of GNU
+// parallel. Without WithTagSuffix, tags are used verbatim, apart from those of
+// [Group.AddArg] which always have a suffix appended, normally a tab. Individual runners
+// can override the suffix with [RunnerTagSuffix].
+func WithTagSuffix(suffix string) Option {
+	f := func(cfg *config) error {
+		cfg.tagSuffix = suffix
+		cfg.suffixTags = true
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// MergeStderr routes all RunFunc stderr output into its stdout stream, mimicking the shell
+// “2>&1” redirection. The relative order of stdout and stderr writes is preserved and
+// only the Group stdout io.Writer receives output. As the streams are merged, the errTag
+// supplied to Add is not used and stderr output is tagged with the outTag.
 //
-// LimitMemoryPerRunner cannot be set with [OrderStderr] == true or [OrderRunners] ==
-// false as that could cause a [RunFunc] to stall indefinitely.
-func LimitMemoryPerRunner(limit uint64) Option {
+// MergeStderr cannot be set with [OrderStderr] as there is no separate stderr stream to
+// order.
+func MergeStderr(on bool) Option {
 	f := func(cfg *config) error {
-		cfg.limitMemory = limit
+		cfg.mergeStderr = on
 
 		return nil // No error possible
 	}
@@ -133,16 +1431,17 @@ func LimitMemoryPerRunner(limit uint64) Option {
 	return option(f)
 }
 
-// OrderRunners causes output to being written in strict order of [RunFunc] addition to
-// the [Group]. If set false output is in order of runner completion. This option exists
-// to mimic the GNU parallel “--keep-order” option. The default is true (which differs
-// from the default for “--keep-order”).
+// CaptureOutput causes a copy of all output written by each RunFunc to be retained by the
+// Group so that it can be retrieved with [Group.Output] after [Group.Wait] returns. This
+// allows a program to post-process the results of each RunFunc in addition to, or instead
+// of, printing them. Output continues to be written to the Group io.Writers, so consider
+// setting them to [io.Discard] if only the captured output is wanted.
 //
-// When OrderRunners is set false, [LimitMemoryPerRunner] cannot be set non-zero as it
-// creates a situation where all RunFuncs could stall indefinitely.
-func OrderRunners(setting bool) Option {
+// If [LimitMemoryPerRunner] is set, it also limits the amount of output captured for each
+// RunFunc. Any output beyond that limit is not captured.
+func CaptureOutput(on bool) Option {
 	f := func(cfg *config) error {
-		cfg.orderRunners = setting
+		cfg.captureOutput = on
 
 		return nil // No error possible
 	}
@@ -150,16 +1449,135 @@ func OrderRunners(setting bool) Option {
 	return option(f)
 }
 
-// OrderStderr causes all stderr output to be written *after* all stdout output for each
-// [RunFunc]. This can result in an output stream which differs from one written by a
-// [RunFunc] run serially and writing directly to os.Stdout and os.Stderr. This option
-// exists to mimic the GNU parallel “--group” option.
+// defaultCoalesceLimit is large enough to absorb most chatty line-at-a-time writers
+// without wasting much memory on unused capacity.
+const defaultCoalesceLimit = 4096
+
+// WithCoalesceLimit sets the maximum size of a buffered chunk created by coalescing
+// consecutive background Writes to the same output stream. Coalescing greatly reduces
+// buffering overhead for RunFuncs which write a byte or a line at a time. Writes larger
+// than limit are never coalesced. A limit of zero disables coalescing. The default is
+// 4096.
+func WithCoalesceLimit(limit uint) Option {
+	f := func(cfg *config) error {
+		cfg.coalesce = int(limit)
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithExpectedOutputSize supplies the number of bytes of output each RunFunc is expected
+// to write. It is used to pre-size the buffers of background RunFuncs and so avoid
+// repeatedly growing them, which helps workloads with well-known output sizes, such as
+// checksumming which produces one short line per file. The estimate only affects
+// performance, not behaviour, so RunFuncs can write more or less than expected.
+func WithExpectedOutputSize(bytes int) Option {
+	f := func(cfg *config) error {
+		if bytes < 0 {
+			return errors.New("Cannot set WithExpectedOutputSize to a negative size")
+		}
+		cfg.expectedOutput = bytes
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithCompressedBuffering causes the output buffered for background RunFuncs to be
+// compressed in memory and decompressed as it is transferred to the Group io.Writers.
+// This trades CPU for a much larger effective buffer, as typical text output compresses
+// several-fold, so verbose RunFuncs are far less likely to reach [LimitMemoryPerRunner]
+// on memory-constrained hosts. Buffered output is compressed a chunk at a time once a
+// chunk can no longer be coalesced (see [WithCoalesceLimit]) and only if compression
+// makes it smaller, and only compressed sizes count towards LimitMemoryPerRunner.
+func WithCompressedBuffering(on bool) Option {
+	f := func(cfg *config) error {
+		cfg.compressBuffers = on
+
+		return nil // No error possible
+	}
+
+	return option(f)
+}
+
+// WithSpillDir causes output which would otherwise exceed [LimitMemoryPerRunner] to be
+// written to a temporary file in dir rather than stalling the [RunFunc]. This mimics the
+// way GNU parallel buffers output in temporary files. Each temporary file is removed once
+// its output has been transferred to the Group io.Writers.
 //
-// When OrderStderr is set true, [LimitMemoryPerRunner] cannot be set true as it creates
-// a situation when all runners could stall indefinitely.
-func OrderStderr(setting bool) Option {
+// As spilled output no longer stalls a RunFunc, setting WithSpillDir removes the
+// restrictions which otherwise apply to [LimitMemoryPerRunner]. Namely that
+// [LimitActiveRunners] must be set and that [OrderStderr] cannot be true and
+// [OrderRunners] cannot be false.
+//
+// If a temporary file cannot be created or written, the Write returns the error to the
+// RunFunc rather than stalling, as without the restrictions the stall might never end.
+// dir must be an existing directory, such as [os.TempDir]. The default is an empty string
+// which disables spilling.
+func WithSpillDir(dir string) Option {
 	f := func(cfg *config) error {
-		cfg.orderStderr = setting
+		if len(dir) > 0 {
+			fi, err := os.Stat(dir)
+			if err != nil {
+				return err
+			}
+			if !fi.IsDir() {
+				return errors.New("WithSpillDir is not a directory: " + dir)
+			}
+		}
+		cfg.spillDir = dir
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithResultsDir writes the output and status of each runner into its own directory under
+// dir, much like the GNU parallel “--results” option. Each runner directory is named
+// after the order in which the runner was added, starting at 1, and contains:
+//
+//	stdout      The stdout output exactly as written by the RunFunc, untagged
+//	stderr      The stderr output exactly as written by the RunFunc, untagged
+//	status.json The fields of a [JobLogJSON] job log line along with the [Outcome] and
+//	            exit code as reported by [RunnerResult]
+//
+// Output is written to the runner directory in addition to the Group io.Writers. Set
+// [DiscardStdout] and [DiscardStderr] to write the runner directories instead of the
+// combined stream. Directories, including dir itself, are created as needed and existing
+// files are replaced. Runners skipped by [WithResume] or [SkipExistingResults] leave
+// their directory untouched.
+//
+// A failure to write a runner directory does not affect the RunFunc or the Group
+// io.Writers. The first such error is included in the error returned by [Group.Wait].
+func WithResultsDir(dir string) Option {
+	f := func(cfg *config) error {
+		if len(dir) == 0 {
+			return errors.New("Cannot supply an empty directory to WithResultsDir")
+		}
+		cfg.resultsDir = dir
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// SkipExistingResults skips any RunFunc whose [WithResultsDir] runner directory contains
+// a status file recording that it previously completed without error, so that a large
+// batch can be re-run repeatedly and only the failed, unfinished or new RunFuncs are run
+// again. The status file is written last, so a runner directory left behind by an
+// interrupted run is never mistaken for a success. As runner directories are named after
+// the order of addition, RunFuncs must be added in the same order on each run.
+//
+// Skipped RunFuncs are treated just as with [WithResume]. SkipExistingResults has no
+// effect unless WithResultsDir is also set. The default is false.
+func SkipExistingResults(on bool) Option {
+	f := func(cfg *config) error {
+		cfg.resultsSkip = on
 
 		return nil // No error possible
 	}
@@ -167,15 +1585,125 @@ func OrderStderr(setting bool) Option {
 	return option(f)
 }
 
-// Passthru is a debug setting. When set true all output is transferred more or less
-// directly to the Group io.Writers. In effect, the Group pipeline plays a very limited
-// part in managing the output stream.
+// WithCombinedOutput sets both the [Group] stdout and stderr destinations to the supplied
+// io.Writer. This is an explicit declaration that the two streams share a destination
+// which allows each pipeline to use a single tail for both streams. The relative order of
+// all writes by a RunFunc to stdout and stderr is strictly preserved, thus
+// WithCombinedOutput cannot be set with [OrderStderr]. [WithStdout] and [WithStderr]
+// cannot be set after WithCombinedOutput.
+func WithCombinedOutput(wtr io.Writer) Option {
+	f := func(cfg *config) error {
+		if wtr == nil {
+			return errors.New("Cannot supply nil io.Writer to WithCombinedOutput")
+		}
+		cfg.stdout = wtr
+		cfg.stderr = wtr
+		cfg.combined = true
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithBufferedOutput wraps the Group io.Writers in a [bufio.Writer] of size bytes so that
+// the many small writes made by chatty RunFuncs are combined into far fewer writes, and
+// thus far fewer system calls, to the underlying destination. Buffered output is flushed
+// whenever a RunFunc's output is complete, whenever a RunFunc switches to foreground and
+// when [Group.Wait] returns. As a foreground RunFunc's output is otherwise held until the
+// buffer fills, consider also setting [WithFlushInterval] when writing to a terminal.
 //
-// If this option is set true the following options cannot be set true:
-// [LimitMemoryPerRunner], [OrderStderr] and [OrderRunners].
-func Passthru(setting bool) Option {
+// A failed flush is reported as a [*WriteError] against the RunFunc being flushed.
+func WithBufferedOutput(size int) Option {
 	f := func(cfg *config) error {
-		cfg.passthru = setting
+		if size <= 0 {
+			return errors.New("Cannot set WithBufferedOutput to a non-positive size")
+		}
+		cfg.bufferSize = size
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithAsyncOutput decouples writing to the Group io.Writers from the runner pipelines. Each
+// Write destined for a Group io.Writer is copied and handed to a single dedicated
+// goroutine via a channel of depth Writes, so a slow terminal or network sink does not
+// extend the time the Group output mutex is held, nor the time background runners remain
+// blocked waiting for their output to drain. Writers only wait for the destination once
+// depth Writes are outstanding. The relative order of all output is unchanged.
+//
+// As Writes complete before the destination has seen the data, a destination error is
+// only returned by subsequent Writes. All outstanding output is written before
+// [Group.Wait] returns and any destination error is included in the error it returns. If
+// [WithBufferedOutput] is also set, the buffers are flushed to the goroutine.
+func WithAsyncOutput(depth int) Option {
+	f := func(cfg *config) error {
+		if depth <= 0 {
+			return errors.New("Cannot set WithAsyncOutput to a non-positive depth")
+		}
+		cfg.asyncDepth = depth
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithOutputRateLimit throttles the combined output written to the Group io.Writers to
+// bytesPerSec, such as to keep a remote terminal usable while a chatty Group runs. Output
+// trickles out in pieces of a tenth of a second's worth. Foreground RunFuncs are slowed
+// to the rate limit while background RunFuncs continue to buffer their output in the
+// usual way, subject to [LimitMemoryPerRunner], thus absorbing the difference. The rate
+// limit also applies while buffered output is written by [Group.Wait].
+func WithOutputRateLimit(bytesPerSec int) Option {
+	f := func(cfg *config) error {
+		if bytesPerSec <= 0 {
+			return errors.New("Cannot set WithOutputRateLimit to a non-positive rate")
+		}
+		cfg.outputRate = bytesPerSec
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithFlushInterval periodically flushes any Group io.Writer which buffers its output by
+// way of a Flush() error method, such as a [bufio.Writer]. Small writes are still batched
+// but no output remains buffered for longer than d, which keeps a terminal lively. A
+// final flush occurs when [Group.Wait] returns. Flushes are serialised with all other
+// writes to the Group io.Writers so they never split the output written by a RunFunc
+// pipeline.
+//
+// A Group io.Writer which implements [net/http.Flusher], such as the http.ResponseWriter of
+// a streaming HTTP response, is also flushed every d, in addition to the flush which
+// always follows the output of each runner. This allows a Group to serialise the output
+// of RunFuncs within a web handler which serves incremental results.
+func WithFlushInterval(d time.Duration) Option {
+	f := func(cfg *config) error {
+		if d <= 0 {
+			return errors.New("Cannot set WithFlushInterval to a non-positive duration")
+		}
+		cfg.flushInterval = d
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithCloseOnFinish causes the Group stdout and stderr io.Writers to be closed once all
+// output has been written to them, if they implement io.Closer. This relieves the caller
+// of closing destinations such as files, gzip writers or network connections which
+// are only used by the Group. A writer supplied as both stdout and stderr is only closed
+// once. Any error returned by Close is included in the error returned by [Group.Wait].
+//
+// Standard outputs, such as the default os.Stdout and os.Stderr, are never closed.
+func WithCloseOnFinish(on bool) Option {
+	f := func(cfg *config) error {
+		cfg.closeOnFinish = on
 
 		return nil // No error possible
 	}
@@ -190,6 +1718,9 @@ func WithStderr(wtr io.Writer) Option {
 		if wtr == nil {
 			return errors.New("Cannot supply nil io.Writer to WithStderr")
 		}
+		if cfg.combined {
+			return errors.New("Cannot set WithStderr after WithCombinedOutput")
+		}
 		cfg.stderr = wtr
 
 		return nil // No error possible
@@ -213,12 +1744,17 @@ func WithStderrSeparator(sep string) Option {
 }
 
 // WithStdout sets the [Group] stdout destination to the supplied io.Writer replacing the
-// default of os.Stdout.
+// default of os.Stdout. If wtr implements [net/http.Flusher], such as the
+// http.ResponseWriter of a streaming HTTP response, it is flushed after the output of each
+// runner so that the HTTP client sees incremental results. See also [WithFlushInterval].
 func WithStdout(wtr io.Writer) Option {
 	f := func(cfg *config) error {
 		if wtr == nil {
 			return errors.New("Cannot supply nil io.Writer to WithStdout")
 		}
+		if cfg.combined {
+			return errors.New("Cannot set WithStdout after WithCombinedOutput")
+		}
 
 		cfg.stdout = wtr
 
@@ -229,9 +1765,55 @@ func WithStdout(wtr io.Writer) Option {
 
 }
 
+// WithStdoutTee duplicates the final, serialised stdout stream written to the Group
+// stdout io.Writer to each of the sinks, such as a log file or a network socket. Unlike
+// wrapping the Group io.Writer with [io.MultiWriter], errors are isolated per sink. A sink
+// which fails is written no more, but the Group io.Writer and all other sinks continue to
+// be written and a [*TeeError] identifying the sink is included in the error returned by
+// [Group.Wait]. Errors returned by the Group io.Writer itself are handled as usual.
+//
+// Sinks receive exactly what the Group io.Writer receives, including separators and
+// other presentation output, but are not considered when detecting a terminal. Sinks are
+// never closed by the Group. If stdout and stderr are the same io.Writer, the sinks of
+// both WithStdoutTee and [WithStderrTee] receive the one combined stream. Multiple
+// WithStdoutTee options accumulate sinks.
+func WithStdoutTee(sinks ...io.Writer) Option {
+	f := func(cfg *config) error {
+		for _, w := range sinks {
+			if w == nil {
+				return errors.New("Cannot supply nil io.Writer to WithStdoutTee")
+			}
+		}
+		cfg.stdoutTee = append(cfg.stdoutTee, sinks...)
+
+		return nil
+	}
+
+	return option(f)
+}
+
+// WithStderrTee is the stderr equivalent of [WithStdoutTee].
+func WithStderrTee(sinks ...io.Writer) Option {
+	f := func(cfg *config) error {
+		for _, w := range sinks {
+			if w == nil {
+				return errors.New("Cannot supply nil io.Writer to WithStderrTee")
+			}
+		}
+		cfg.stderrTee = append(cfg.stderrTee, sinks...)
+
+		return nil
+	}
+
+	return option(f)
+}
+
 // WithStdoutSeparator sets the separator string printed to the [Group] stdout io.Writer
 // between the output of [RunFunc]. If WithStdoutSeparator is set to a non-empty string it
 // should normally include a trailing newline. The default is an empty string.
+//
+// Separators are only written between RunFuncs which actually run, so no separator
+// follows the last RunFunc to run, even if subsequent RunFuncs are skipped.
 func WithStdoutSeparator(sep string) Option {
 	f := func(cfg *config) error {
 		cfg.outSep = []byte(sep)
@@ -242,30 +1824,236 @@ func WithStdoutSeparator(sep string) Option {
 	return option(f)
 }
 
+// ValidateOptions applies opts to a default config and checks them for conflicts in
+// exactly the same way as [NewGroup] but without constructing a Group. This allows
+// command-line programs to validate user-supplied flags up front and print a precise
+// usage error before doing any work. The returned error is the same error NewGroup would
+// return, thus conflicts can be identified with errors.Is as described in
+// [ErrMemoryLimitRequiresActiveLimit].
+func ValidateOptions(opts ...Option) error {
+	return newConfig().applyOptions(opts...)
+}
+
+// applyOptions applies opts to cfg, derives any dependent defaults and checks that the
+// result is internally consistent.
+func (cfg *config) applyOptions(opts ...Option) error {
+	for _, opt := range opts {
+		err := opt.apply(cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The adaptive upper bound defaults to a multiple of NumCPU
+	if cfg.autoRunners && cfg.limitRunners == 0 && cfg.cpuFactor == 0 {
+		cfg.limitRunners = uint(runtime.NumCPU() * autoMaxFactor)
+	}
+
+	return cfg.checkConflicts() // Make sure config is internally consistent
+}
+
 // Check that none of the config options conflict with each other and that none of them
 // could cause a runner to stall indefinitely.
 func (cfg *config) checkConflicts() error {
-	if cfg.limitMemory > 0 {
-		if cfg.limitRunners == 0 {
-			return errors.New("Must set LimitActiveRunners when LimitMemoryPerRunner is set")
-		}
-		if !cfg.orderRunners {
-			return errors.New("Cannot set LimitMemoryPerRunner with OrderRunners(false)")
+	if cfg.softMemory > 0 && cfg.softMemory >= cfg.limitMemory {
+		return ErrSoftLimitNotBelowLimit
+	}
+
+	if cfg.limitMemory > 0 && len(cfg.spillDir) == 0 {
+		if cfg.limitRunners == 0 && cfg.cpuFactor == 0 {
+			return ErrMemoryLimitRequiresActiveLimit
 		}
 		if cfg.orderStderr {
-			return errors.New("Cannot set LimitMemoryPerRunner with OrderStderr(true)")
+			return ErrMemoryLimitWithOrderStderr
+		}
+		// A Scheduler can dispatch later runners which then block in background and hold
+		// every slot so the foreground runner never starts.
+		_, fifo := cfg.scheduler.(FIFOScheduler)
+		if cfg.orderRunners && cfg.scheduler != nil && !fifo {
+			return ErrSchedulerWithMemoryLimit
 		}
 	}
 
 	if cfg.passthru {
 		if cfg.limitMemory > 0 {
-			return errors.New("Cannot set LimitMemoryPerRunner with Passthru(true)")
+			return ErrMemoryLimitWithPassthru
+		}
+		if cfg.orderRunners {
+			return ErrOrderRunnersWithPassthru
+		}
+		if cfg.orderStderr {
+			return ErrOrderStderrWithPassthru
+		}
+		if len(cfg.sectionFormat) > 0 {
+			return ErrSectionHeadersWithPassthru
+		}
+	}
+
+	if cfg.report != ReportNone {
+		if cfg.limitMemory > 0 {
+			return ErrReportWithMemoryLimit
+		}
+		if cfg.passthru {
+			return ErrReportWithPassthru
+		}
+		if cfg.ungroup {
+			return ErrReportWithUngroup
+		}
+		if cfg.jsonOutput {
+			return ErrReportWithJSONOutput
+		}
+		if cfg.framedOutput {
+			return ErrReportWithFramedOutput
+		}
+	}
+
+	if cfg.roundRobin {
+		if cfg.orderRunners {
+			return ErrRoundRobinWithOrderRunners
+		}
+		if cfg.limitMemory > 0 {
+			return ErrRoundRobinWithMemoryLimit
+		}
+		if cfg.orderStderr {
+			return ErrRoundRobinWithOrderStderr
+		}
+		if cfg.passthru {
+			return ErrRoundRobinWithPassthru
+		}
+		if cfg.ungroup {
+			return ErrRoundRobinWithUngroup
+		}
+		if cfg.compressor != nil {
+			return ErrRoundRobinWithCompression
+		}
+		if cfg.jsonOutput {
+			return ErrRoundRobinWithJSONOutput
+		}
+		if cfg.framedOutput {
+			return ErrRoundRobinWithFramedOutput
+		}
+		if cfg.report != ReportNone {
+			return ErrRoundRobinWithReport
+		}
+		if len(cfg.sectionFormat) > 0 {
+			return ErrRoundRobinWithSectionHeaders
+		}
+	}
+
+	if cfg.collapse {
+		if cfg.limitMemory > 0 {
+			return ErrCollapseWithMemoryLimit
+		}
+		if cfg.passthru {
+			return ErrCollapseWithPassthru
+		}
+		if cfg.ungroup {
+			return ErrCollapseWithUngroup
+		}
+		if cfg.roundRobin {
+			return ErrCollapseWithRoundRobin
+		}
+		if cfg.jsonOutput {
+			return ErrCollapseWithJSONOutput
+		}
+		if cfg.framedOutput {
+			return ErrCollapseWithFramedOutput
+		}
+		if cfg.report != ReportNone {
+			return ErrCollapseWithReport
+		}
+		if len(cfg.sectionFormat) > 0 {
+			return ErrCollapseWithSectionHeaders
+		}
+		if cfg.compressor != nil {
+			return ErrCollapseWithCompression
+		}
+		if cfg.footer != nil {
+			return ErrCollapseWithFooter
+		}
+		if len(cfg.tagColors) > 0 {
+			return ErrCollapseWithTagColors
+		}
+		if len(cfg.outSep) > 0 || len(cfg.errSep) > 0 {
+			return ErrCollapseWithSeparators
+		}
+	}
+
+	if cfg.orderBy != nil {
+		if !cfg.orderRunners {
+			return ErrOrderByWithoutOrderRunners
+		}
+		if cfg.openEnded {
+			return ErrOrderByWithOpenEnded
+		}
+	}
+
+	if cfg.combined && cfg.orderStderr {
+		return ErrOrderStderrWithCombinedOutput
+	}
+
+	if cfg.mergeStderr && cfg.orderStderr {
+		return ErrOrderStderrWithMergeStderr
+	}
+
+	if cfg.compressor != nil {
+		if len(cfg.outSep) > 0 || len(cfg.errSep) > 0 {
+			return ErrSeparatorsWithCompression
+		}
+		if cfg.ungroup {
+			return ErrUngroupWithCompression
+		}
+		if cfg.passthru {
+			return ErrPassthruWithCompression
+		}
+		if cfg.footer != nil {
+			return ErrFooterWithCompression
+		}
+		if len(cfg.sectionFormat) > 0 {
+			return ErrSectionHeadersWithCompression
+		}
+	}
+
+	if cfg.jsonOutput && cfg.framedOutput {
+		return ErrFramedOutputWithJSONOutput
+	}
+	if cfg.jsonOutput || cfg.framedOutput {
+		errs := jsonOutputConflicts
+		if cfg.framedOutput {
+			errs = framedOutputConflicts
+		}
+		if len(cfg.outSep) > 0 || len(cfg.errSep) > 0 {
+			return errs.separators
+		}
+		if len(cfg.tagColors) > 0 {
+			return errs.tagColors
+		}
+		if cfg.passthru {
+			return errs.passthru
+		}
+		if cfg.footer != nil {
+			return errs.footer
+		}
+		if len(cfg.sectionFormat) > 0 {
+			return errs.sectionHeaders
+		}
+	}
+
+	if cfg.ungroup {
+		if cfg.limitMemory > 0 {
+			return ErrMemoryLimitWithUngroup
 		}
 		if cfg.orderRunners {
-			return errors.New("Cannot set OrderRunners with Passthru(true)")
+			return ErrOrderRunnersWithUngroup
 		}
 		if cfg.orderStderr {
-			return errors.New("Cannot set OrderStderr with Passthru(true)")
+			return ErrOrderStderrWithUngroup
+		}
+		if cfg.passthru {
+			return ErrPassthruWithUngroup
+		}
+		if len(cfg.sectionFormat) > 0 {
+			return ErrSectionHeadersWithUngroup
 		}
 	}
 
@@ -2,7 +2,12 @@ package parallel
 
 import (
 	"bytes"
+	"errors"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -119,10 +124,10 @@ func TestConfigConflicts(t *testing.T) {
 	testCases := []testCase{
 		/* 0 */ {0, 0, false, false, false, ""},
 		/* 1 */ {100, 0, false, false, false, "Must set LimitActiveRunners"},
-		/* 2 */ {100, 1, false, false, false, "LimitMemoryPerRunner with OrderRunners"},
+		/* 2 */ {100, 1, false, false, false, ""},
 		/* 3 */ {100, 1, true, false, false, ""},
 		/* 4 */ {100, 1, true, true, false, "LimitMemoryPerRunner with OrderStderr"},
-		/* 5 */ {100, 1, false, true, false, "LimitMemoryPerRunner with OrderRunners"},
+		/* 5 */ {100, 1, false, true, false, "LimitMemoryPerRunner with OrderStderr"},
 		/* 6 */ {100, 1, true, false, true, "LimitMemoryPerRunner with Passthru"},
 		/* 7 */ {100, 1, true, false, true, "LimitMemoryPerRunner with Passthru"},
 		/* 8 */ {0, 0, true, false, true, "OrderRunners with Passthru"},
@@ -154,6 +159,81 @@ func TestConfigConflicts(t *testing.T) {
 	}
 }
 
+// Conflicts can be identified with errors.Is
+func TestConfigConflictSentinels(t *testing.T) {
+	type testCase struct {
+		opts []Option
+		err  error
+	}
+	testCases := []testCase{
+		{[]Option{LimitMemoryPerRunner(100)}, ErrMemoryLimitRequiresActiveLimit},
+		{[]Option{Passthru(true)}, ErrOrderRunnersWithPassthru},
+		{[]Option{WithJSONOutput(true), WithStdoutSeparator("-")}, ErrSeparatorsWithJSONOutput},
+		{[]Option{WithFramedOutput(true), WithTagColors()}, ErrTagColorsWithFramedOutput},
+		{[]Option{WithJSONOutput(true), WithFramedOutput(true)}, ErrFramedOutputWithJSONOutput},
+	}
+	for ix, tc := range testCases {
+		_, err := NewGroup(tc.opts...)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("Case %d: Expected %v, got %v", ix, tc.err, err)
+		}
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	if err := ValidateOptions(); err != nil {
+		t.Error("Unexpected error with default options", err)
+	}
+	if err := ValidateOptions(LimitMemoryPerRunner(100), LimitActiveRunners(2)); err != nil {
+		t.Error("Unexpected error with valid options", err)
+	}
+	err := ValidateOptions(LimitMemoryPerRunner(100))
+	if !errors.Is(err, ErrMemoryLimitRequiresActiveLimit) {
+		t.Error("Expected conflict error, got", err)
+	}
+	if err := ValidateOptions(WithStdout(nil)); err == nil {
+		t.Error("Expected option error with nil io.Writer")
+	}
+}
+
+// WithSpillDir removes the stall restrictions on LimitMemoryPerRunner
+func TestConfigSpillDir(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewGroup(LimitMemoryPerRunner(100), OrderRunners(false), OrderStderr(true),
+		WithSpillDir(dir))
+	if err != nil {
+		t.Error("Unexpected error with WithSpillDir", err)
+	}
+
+	_, err = NewGroup(WithSpillDir(dir + "/does-not-exist"))
+	if err == nil {
+		t.Error("Expected error with non-existent WithSpillDir")
+	}
+}
+
+// A failed spill returns the error to the RunFunc rather than stalling it forever.
+func TestConfigSpillDirError(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "spill")
+	os.Mkdir(dir, 0o755)
+	grp, err := NewGroup(WithStdout(io.Discard), WithStderr(io.Discard),
+		LimitMemoryPerRunner(10), OrderStderr(true), WithSpillDir(dir))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	os.Remove(dir) // So the spill file cannot be created
+
+	var writeErr error
+	grp.Add("", "", func(out, err io.Writer) {
+		err.Write([]byte("0123456789")) // Fits in memory
+		_, writeErr = err.Write([]byte("abcdefghij"))
+	})
+	grp.Run()
+	grp.Wait()
+	if writeErr == nil {
+		t.Error("Expected spill error from Write")
+	}
+}
+
 func TestConfigNilWriters(t *testing.T) {
 	cfg := &config{}
 	err := WithStdout(os.Stdout).apply(cfg)
@@ -174,3 +254,46 @@ func TestConfigNilWriters(t *testing.T) {
 		t.Error("Expected error setting WithStderr(nil)", err)
 	}
 }
+
+func TestConfigOpenEnded(t *testing.T) {
+	cfg := &config{}
+	OpenEnded(true).apply(cfg)
+	if !cfg.openEnded {
+		t.Error("openEnded not set")
+	}
+}
+
+func TestConfigPerCPU(t *testing.T) {
+	grp, err := NewGroup(LimitMemoryPerRunner(100), LimitActiveRunnersPerCPU(0.5))
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if grp.limitRunners != 0 {
+		t.Error("LimitActiveRunnersPerCPU should not set the limit until Run", grp.limitRunners)
+	}
+	grp.Run()
+	grp.Wait()
+	expect := uint(runtime.GOMAXPROCS(0) / 2)
+	if expect == 0 {
+		expect = 1
+	}
+	if grp.limitRunners != expect {
+		t.Error("Expected limit of", expect, "got", grp.limitRunners)
+	}
+
+	grp, _ = NewGroup(LimitActiveRunnersPerCPU(2), LimitActiveRunners(3))
+	if grp.cpuFactor != 0 || grp.limitRunners != 3 {
+		t.Error("LimitActiveRunners should override LimitActiveRunnersPerCPU")
+	}
+	cfg := &config{cpuFactor: 0.0001}
+	if cfg.cpuLimit() != 1 {
+		t.Error("Expected minimum limit of one, got", cfg.cpuLimit())
+	}
+
+	for _, factor := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		_, err = NewGroup(LimitActiveRunnersPerCPU(factor))
+		if err == nil {
+			t.Error("Expected error from factor", factor)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package parallel
+
+import "errors"
+
+// Option conflict errors are returned by [NewGroup] and [ValidateOptions] when the
+// supplied options cannot be used together. Callers can identify a specific conflict with
+// errors.Is, eg:
+//
+//	_, err := parallel.NewGroup(opts...)
+//	if errors.Is(err, parallel.ErrMemoryLimitRequiresActiveLimit) {
+//		opts = append(opts, parallel.LimitActiveRunners(uint(runtime.NumCPU())))
+//	}
+var (
+	ErrSoftLimitNotBelowLimit         = errors.New("SoftLimitMemoryPerRunner must be less than LimitMemoryPerRunner")
+	ErrMemoryLimitRequiresActiveLimit = errors.New("Must set LimitActiveRunners when LimitMemoryPerRunner is set")
+	ErrMemoryLimitWithOrderStderr     = errors.New("Cannot set LimitMemoryPerRunner with OrderStderr(true)")
+	ErrSchedulerWithMemoryLimit       = errors.New("Cannot set WithScheduler with LimitMemoryPerRunner and OrderRunners(true)")
+	ErrMemoryLimitWithPassthru        = errors.New("Cannot set LimitMemoryPerRunner with Passthru(true)")
+	ErrOrderRunnersWithPassthru       = errors.New("Cannot set OrderRunners with Passthru(true)")
+	ErrOrderStderrWithPassthru        = errors.New("Cannot set OrderStderr with Passthru(true)")
+	ErrSectionHeadersWithPassthru     = errors.New("Cannot set WithSectionHeaders with Passthru(true)")
+	ErrReportWithMemoryLimit          = errors.New("Cannot set WithReport with LimitMemoryPerRunner")
+	ErrReportWithPassthru             = errors.New("Cannot set WithReport with Passthru(true)")
+	ErrReportWithUngroup              = errors.New("Cannot set WithReport with Ungroup(true)")
+	ErrReportWithJSONOutput           = errors.New("Cannot set WithReport with WithJSONOutput(true)")
+	ErrReportWithFramedOutput         = errors.New("Cannot set WithReport with WithFramedOutput(true)")
+	ErrRoundRobinWithOrderRunners     = errors.New("Cannot set WithRoundRobin with OrderRunners(true)")
+	ErrRoundRobinWithMemoryLimit      = errors.New("Cannot set WithRoundRobin with LimitMemoryPerRunner")
+	ErrRoundRobinWithOrderStderr      = errors.New("Cannot set WithRoundRobin with OrderStderr(true)")
+	ErrRoundRobinWithPassthru         = errors.New("Cannot set WithRoundRobin with Passthru(true)")
+	ErrRoundRobinWithUngroup          = errors.New("Cannot set WithRoundRobin with Ungroup(true)")
+	ErrRoundRobinWithCompression      = errors.New("Cannot set WithRoundRobin with WithCompression")
+	ErrRoundRobinWithJSONOutput       = errors.New("Cannot set WithRoundRobin with WithJSONOutput(true)")
+	ErrRoundRobinWithFramedOutput     = errors.New("Cannot set WithRoundRobin with WithFramedOutput(true)")
+	ErrRoundRobinWithReport           = errors.New("Cannot set WithRoundRobin with WithReport")
+	ErrRoundRobinWithSectionHeaders   = errors.New("Cannot set WithRoundRobin with WithSectionHeaders")
+	ErrOrderByWithoutOrderRunners     = errors.New("Cannot set OrderBy with OrderRunners(false)")
+	ErrOrderByWithOpenEnded           = errors.New("Cannot set OrderBy with OpenEnded(true)")
+	ErrOrderStderrWithCombinedOutput  = errors.New("Cannot set OrderStderr with WithCombinedOutput")
+	ErrOrderStderrWithMergeStderr     = errors.New("Cannot set OrderStderr with MergeStderr(true)")
+	ErrSeparatorsWithCompression      = errors.New("Cannot set separators with WithCompression")
+	ErrUngroupWithCompression         = errors.New("Cannot set Ungroup with WithCompression")
+	ErrPassthruWithCompression        = errors.New("Cannot set Passthru with WithCompression")
+	ErrFooterWithCompression          = errors.New("Cannot set WithRunnerFooter with WithCompression")
+	ErrSectionHeadersWithCompression  = errors.New("Cannot set WithSectionHeaders with WithCompression")
+	ErrSeparatorsWithJSONOutput       = errors.New("Cannot set separators with WithJSONOutput(true)")
+	ErrTagColorsWithJSONOutput        = errors.New("Cannot set WithTagColors with WithJSONOutput(true)")
+	ErrPassthruWithJSONOutput         = errors.New("Cannot set Passthru with WithJSONOutput(true)")
+	ErrFooterWithJSONOutput           = errors.New("Cannot set WithRunnerFooter with WithJSONOutput(true)")
+	ErrSectionHeadersWithJSONOutput   = errors.New("Cannot set WithSectionHeaders with WithJSONOutput(true)")
+	ErrSeparatorsWithFramedOutput     = errors.New("Cannot set separators with WithFramedOutput(true)")
+	ErrTagColorsWithFramedOutput      = errors.New("Cannot set WithTagColors with WithFramedOutput(true)")
+	ErrPassthruWithFramedOutput       = errors.New("Cannot set Passthru with WithFramedOutput(true)")
+	ErrFooterWithFramedOutput         = errors.New("Cannot set WithRunnerFooter with WithFramedOutput(true)")
+	ErrSectionHeadersWithFramedOutput = errors.New("Cannot set WithSectionHeaders with WithFramedOutput(true)")
+	ErrFramedOutputWithJSONOutput     = errors.New("Cannot set WithFramedOutput with WithJSONOutput(true)")
+	ErrMemoryLimitWithUngroup         = errors.New("Cannot set LimitMemoryPerRunner with Ungroup(true)")
+	ErrOrderRunnersWithUngroup        = errors.New("Cannot set OrderRunners with Ungroup(true)")
+	ErrOrderStderrWithUngroup         = errors.New("Cannot set OrderStderr with Ungroup(true)")
+	ErrPassthruWithUngroup            = errors.New("Cannot set Passthru with Ungroup(true)")
+	ErrSectionHeadersWithUngroup      = errors.New("Cannot set WithSectionHeaders with Ungroup(true)")
+	ErrCollapseWithMemoryLimit        = errors.New("Cannot set WithCollapse with LimitMemoryPerRunner")
+	ErrCollapseWithPassthru           = errors.New("Cannot set WithCollapse with Passthru(true)")
+	ErrCollapseWithUngroup            = errors.New("Cannot set WithCollapse with Ungroup(true)")
+	ErrCollapseWithRoundRobin         = errors.New("Cannot set WithCollapse with WithRoundRobin")
+	ErrCollapseWithJSONOutput         = errors.New("Cannot set WithCollapse with WithJSONOutput(true)")
+	ErrCollapseWithFramedOutput       = errors.New("Cannot set WithCollapse with WithFramedOutput(true)")
+	ErrCollapseWithReport             = errors.New("Cannot set WithCollapse with WithReport")
+	ErrCollapseWithSectionHeaders     = errors.New("Cannot set WithCollapse with WithSectionHeaders")
+	ErrCollapseWithCompression        = errors.New("Cannot set WithCollapse with WithCompression")
+	ErrCollapseWithFooter             = errors.New("Cannot set WithCollapse with WithRunnerFooter")
+	ErrCollapseWithTagColors          = errors.New("Cannot set WithCollapse with WithTagColors")
+	ErrCollapseWithSeparators         = errors.New("Cannot set WithCollapse with separators")
+)
+
+// encoderConflicts contains the conflict errors common to both output encoders.
+type encoderConflicts struct {
+	separators, tagColors, passthru, footer, sectionHeaders error
+}
+
+var (
+	jsonOutputConflicts = encoderConflicts{ErrSeparatorsWithJSONOutput,
+		ErrTagColorsWithJSONOutput, ErrPassthruWithJSONOutput, ErrFooterWithJSONOutput,
+		ErrSectionHeadersWithJSONOutput}
+	framedOutputConflicts = encoderConflicts{ErrSeparatorsWithFramedOutput,
+		ErrTagColorsWithFramedOutput, ErrPassthruWithFramedOutput,
+		ErrFooterWithFramedOutput, ErrSectionHeadersWithFramedOutput}
+)
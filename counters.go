@@ -0,0 +1,70 @@
+package parallel
+
+import "time"
+
+// Counters contains the pipeline throughput counters of a runner or of the whole Group as
+// returned by [Group.Snapshot]. Counters only ever increase.
+type Counters struct {
+	Writes  int64 // Write calls made by the RunFunc
+	Queued  int64 // Bytes buffered while the runner was in the background
+	Drained int64 // Buffered bytes transferred to the Group io.Writers
+	Blocks  int64 // Write calls blocked by LimitMemoryPerRunner
+}
+
+// RunnerCounters contains the Counters of a single runner.
+type RunnerCounters struct {
+	Index  int    // Order in which the runner was added, starting at zero
+	OutTag string // As supplied to Add
+	Counters
+}
+
+// Snapshot contains the Counters of each runner in the order in which they were added to
+// the Group together with the Group-wide totals.
+type Snapshot struct {
+	Taken   time.Time // When the Snapshot was taken
+	Group   Counters  // Sum of all runner Counters
+	Runners []RunnerCounters
+}
+
+// Snapshot returns the current throughput counters of each runner and of the Group as a
+// whole. Snapshot can be called at any time from any goroutine and is cheap enough to be
+// sampled periodically so that the difference between two Snapshots measures pipeline
+// throughput over that interval. As with [Group.Metrics], the values are gathered from
+// concurrently changing state so they are not necessarily mutually consistent.
+func (grp *Group) Snapshot() Snapshot {
+	grp.mu.Lock()
+	defer grp.mu.Unlock()
+
+	snap := Snapshot{Taken: grp.clock.Now(), Runners: make([]RunnerCounters, 0, len(grp.all))}
+	for _, rnr := range grp.all {
+		rc := RunnerCounters{Index: rnr.index, OutTag: string(rnr.outTag),
+			Counters: rnr.counters()}
+		snap.Group.add(rc.Counters)
+		snap.Runners = append(snap.Runners, rc)
+	}
+
+	return snap
+}
+
+func (c *Counters) add(o Counters) {
+	c.Writes += o.Writes
+	c.Queued += o.Queued
+	c.Drained += o.Drained
+	c.Blocks += o.Blocks
+}
+
+// counters returns the current counters of the runner. It is concurrency safe and returns
+// zero counters if the pipeline has not yet been built.
+func (rnr *runner) counters() (c Counters) {
+	if rnr.stdout == nil {
+		return
+	}
+	c.Writes = rnr.stdout.(*head).writes.Load() + rnr.stderr.(*head).writes.Load()
+	if rnr.queue != nil {
+		c.Queued = rnr.queue.cq.queued.Load()
+		c.Drained = rnr.queue.cq.drained.Load()
+		c.Blocks = rnr.queue.cq.blocks.Load()
+	}
+
+	return
+}
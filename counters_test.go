@@ -0,0 +1,71 @@
+package parallel
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	grp, err := NewGroup(WithStdout(io.Discard), LimitActiveRunners(2),
+		LimitMemoryPerRunner(4))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	grp.Add("a", "", func(out, err io.Writer) { <-release })
+	grp.Add("b", "", func(out, err io.Writer) {
+		out.Write([]byte("abc"))
+		err.Write([]byte("defgh")) // Over the limit so blocks
+	})
+
+	if snap := grp.Snapshot(); snap.Group != (Counters{}) || len(snap.Runners) != 2 {
+		t.Error("Wrong snapshot before Run", snap)
+	}
+	grp.Run()
+	for grp.Snapshot().Group.Blocks == 0 { // Wait for runner b to block
+	}
+	close(release)
+	grp.Wait()
+
+	snap := grp.Snapshot()
+	exp := Counters{Writes: 2, Queued: 3, Drained: 3, Blocks: 1}
+	if rc := snap.Runners[1]; rc.Counters != exp || rc.OutTag != "b" || rc.Index != 1 {
+		t.Error("Wrong runner counters", rc)
+	}
+	if snap.Runners[0].Counters != (Counters{}) {
+		t.Error("Expected zero counters for a", snap.Runners[0])
+	}
+	if snap.Group != exp {
+		t.Error("Wrong group counters", snap.Group)
+	}
+	if snap.Taken.IsZero() {
+		t.Error("Taken not set")
+	}
+}
+
+// Output copied with io.Copy is counted the same as Writes so that Queued and Drained
+// agree once the runner completes.
+func TestSnapshotReadFrom(t *testing.T) {
+	grp, err := NewGroup(WithStdout(io.Discard))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	copied := make(chan struct{})
+	src := strings.Repeat("x", readFromChunkSize-1) // Large enough to be queued as-is
+	grp.Add("a", "", func(out, err io.Writer) { <-release })
+	grp.Add("b", "", func(out, err io.Writer) {
+		io.Copy(out, struct{ io.Reader }{strings.NewReader(src)})
+		close(copied)
+	})
+	grp.Run()
+	<-copied // While b is still in background
+	close(release)
+	grp.Wait()
+
+	exp := Counters{Writes: 1, Queued: int64(len(src)), Drained: int64(len(src))}
+	if got := grp.Snapshot().Runners[1].Counters; got != exp {
+		t.Error("Wrong runner counters", got, "expected", exp)
+	}
+}
@@ -0,0 +1,98 @@
+package parallel
+
+import (
+	"bytes"
+	"sync"
+)
+
+// crCollapser is a writer which keeps only the final state of lines overwritten with
+// carriage returns, such as the progress lines of wget, curl and tar. The final state is
+// taken to be the text following the last '\r' within the line, ignoring a '\r' which
+// immediately precedes the line delimiter as that is part of a CRLF terminator.
+//
+// Collapsing only applies while output is buffered. Once live returns true, any held back
+// line is written as-is and subsequent output passes straight thru so that progress
+// animation remains visible on the terminal. A nil live means output is always buffered.
+type crCollapser struct {
+	mu sync.Mutex
+	commonWriter
+	live    func() bool
+	delim   byte   // Line delimiter - see WithLineDelimiter
+	partial []byte // Incomplete line, trimmed of all but its current state
+	lines   []byte // Reused to accumulate the collapsed lines of each Write
+}
+
+func newCRCollapser(out writer, live func() bool, delim byte) *crCollapser {
+	wtr := &crCollapser{live: live, delim: delim}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+// Write returns the length of p on success as overwritten output is consumed even tho it
+// is not written downstream. The first downstream error is returned.
+func (wtr *crCollapser) Write(p []byte) (n int, err error) {
+	wtr.mu.Lock()
+	defer wtr.mu.Unlock()
+
+	if wtr.live != nil && wtr.live() {
+		if len(wtr.partial) > 0 {
+			_, err = wtr.out.Write(wtr.partial)
+			wtr.partial = nil
+		}
+		if _, e := wtr.out.Write(p); e != nil && err == nil {
+			err = e
+		}
+		return len(p), err
+	}
+
+	wtr.partial = append(wtr.partial, p...)
+	lines := wtr.lines[:0]
+	for {
+		ix := bytes.IndexByte(wtr.partial, wtr.delim)
+		if ix < 0 {
+			break
+		}
+		lines = append(lines, finalState(wtr.partial[:ix])...)
+		lines = append(lines, wtr.delim)
+		wtr.partial = wtr.partial[ix+1:]
+	}
+	wtr.lines = lines
+	if len(lines) > 0 {
+		_, err = wtr.out.Write(lines)
+	}
+
+	// Discard overwritten states of the incomplete line so that a long-running progress
+	// line does not accumulate. A trailing '\r' is retained as it may be part of CRLF.
+	if len(wtr.partial) > 1 {
+		if ix := bytes.LastIndexByte(wtr.partial[:len(wtr.partial)-1], '\r'); ix >= 0 {
+			wtr.partial = wtr.partial[ix+1:]
+		}
+	}
+
+	return len(p), err
+}
+
+// finalState returns the text of line following the last '\r', excluding any '\r' which
+// terminates line.
+func finalState(line []byte) []byte {
+	end := len(line)
+	if end > 0 && line[end-1] == '\r' { // CRLF
+		end--
+	}
+	if ix := bytes.LastIndexByte(line[:end], '\r'); ix >= 0 {
+		return line[ix+1:]
+	}
+
+	return line
+}
+
+func (wtr *crCollapser) close() {
+	wtr.mu.Lock()
+	if len(wtr.partial) > 0 {
+		wtr.out.Write(finalState(wtr.partial))
+		wtr.partial = nil
+	}
+	wtr.mu.Unlock()
+	wtr.out.close() // Pass it on
+}
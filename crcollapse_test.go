@@ -0,0 +1,55 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestCollapseProgress(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), CollapseProgress(true))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+
+	// The first runner stays in foreground until the second has written everything so
+	// only the output of the second is buffered.
+	written := make(chan struct{})
+	grp.Add("a: ", "", func(out, err io.Writer) {
+		out.Write([]byte("\r10%\r20%"))
+		<-written
+		out.Write([]byte("\r100%\n"))
+	})
+	grp.Add("b: ", "b! ", func(out, err io.Writer) {
+		defer close(written)
+		out.Write([]byte("start\n\r 10%"))
+		for range 1000 {
+			out.Write([]byte("\r 50%"))
+		}
+		out.Write([]byte("\r100%\n"))
+		out.Write([]byte("dos\r\n"))
+		out.Write([]byte("x\ry\r"))
+		out.Write([]byte("\n\rdone"))
+		err.Write([]byte("e1\re2\n"))
+	})
+	grp.Run()
+	grp.Wait()
+
+	exp := "a: \r10%\r20%\r100%\nb: start\nb: 100%\nb: dos\r\nb: y\r\nb: done"
+	if got := stdout.String(); got != exp {
+		t.Errorf("Wrong stdout\nGot %q\nExp %q", got, exp)
+	}
+	if got := stderr.String(); got != "b! e2\n" {
+		t.Errorf("Wrong stderr %q", got)
+	}
+}
+
+func TestFinalState(t *testing.T) {
+	for in, exp := range map[string]string{"": "", "abc": "abc", "a\rb": "b", "a\r": "a\r",
+		"a\rb\r": "b\r", "\r": "\r", "a\r\r": "\r"} {
+		if got := string(finalState([]byte(in))); got != exp {
+			t.Errorf("%q: Got %q Expected %q", in, got, exp)
+		}
+	}
+}
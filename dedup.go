@@ -0,0 +1,88 @@
+package parallel
+
+import (
+	"context"
+	"io"
+)
+
+// dedupRunner determines whether rnr, which is about to be fed to a worker, is a
+// duplicate of an earlier runner according to DedupRunners. The first runner fed with
+// each key becomes the original, so a duplicate never waits on an original which has yet
+// to reach a worker, regardless of the Scheduler. With ReplayDuplicates the original is
+// prepared to capture its output for the duplicates. Runners with an empty key and those
+// skipped by WithShard, WithResume or SkipExistingResults are never originals. Caller must hold
+// grp.mu.
+func (grp *Group) dedupRunner(rnr *runner) {
+	if grp.dedupFirst == nil || len(rnr.dedupKey) == 0 || !grp.inShard(rnr) ||
+		grp.resumable(rnr) {
+		return
+	}
+	if orig := grp.dedupFirst[rnr.dedupKey]; orig != nil {
+		rnr.dedupOrig = orig
+		return
+	}
+	grp.dedupFirst[rnr.dedupKey] = rnr
+	if grp.dedupReplay {
+		rnr.dedupCap = &capture{}
+		rnr.dedupDone = make(chan struct{})
+	}
+}
+
+// finished releases any duplicates waiting to replay the output of rnr. It is called
+// once the runner is done with, whether it ran or not.
+func (rnr *runner) finished() {
+	if rnr.dedupDone != nil {
+		close(rnr.dedupDone)
+	}
+}
+
+// replayOriginal returns a runFunc which waits for orig to complete then writes the
+// output captured from orig and returns its error.
+func replayOriginal(orig *runner) runFunc {
+	return func(ctx context.Context, stdout, stderr io.Writer) error {
+		select {
+		case <-orig.dedupDone:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+		c := orig.dedupCap
+		c.Lock()
+		defer c.Unlock()
+		if len(c.out) > 0 {
+			stdout.Write(c.out)
+		}
+		if len(c.err) > 0 {
+			stderr.Write(c.err)
+		}
+
+		return orig.err
+	}
+}
+
+// dedupWriter copies each Write to the runner's dedup capture, if the runner is an
+// original whose output is to be replayed, then passes it on unchanged. The capture is
+// set before the RunFunc is called so it never changes while Writes are in progress.
+type dedupWriter struct {
+	commonWriter
+	rnr   *runner
+	where destination
+}
+
+func newDedupWriter(out writer, rnr *runner, where destination) *dedupWriter {
+	wtr := &dedupWriter{rnr: rnr, where: where}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+func (wtr *dedupWriter) Write(p []byte) (n int, err error) {
+	if c := wtr.rnr.dedupCap; c != nil {
+		c.retain(wtr.where, p)
+	}
+
+	return wtr.out.Write(p)
+}
+
+func (wtr *dedupWriter) close() {
+	wtr.out.close() // Pass it on
+}
@@ -0,0 +1,74 @@
+package parallel
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDedupRunners(t *testing.T) {
+	if _, err := NewGroup(DedupRunners(nil)); err == nil {
+		t.Error("Expected error with nil function")
+	}
+
+	byName := func(info RunnerInfo) string { return info.Name }
+	keys := []string{"a", "b", "a", "", "", "b", "a"}
+	failed := errors.New("failed")
+
+	for _, replay := range []bool{false, true} {
+		var stdout, stderr bytes.Buffer
+		var ran atomic.Int32
+		grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr),
+			DedupRunners(byName), ReplayDuplicates(replay))
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		for ix, key := range keys {
+			tag := string(rune('0'+ix)) + " "
+			grp.AddErr(tag, tag, func(out, err io.Writer) error {
+				ran.Add(1)
+				out.Write([]byte(key + "\n"))
+				if key == "b" {
+					err.Write([]byte("oops\n"))
+					return failed
+				}
+				return nil
+			}, RunnerName(key))
+		}
+		grp.Run()
+		err = grp.Wait()
+		if ran.Load() != 4 {
+			t.Error(replay, "Expected four RunFuncs to run, not", ran.Load())
+		}
+
+		var wantOut, wantErr string
+		var wantErrors int
+		if replay {
+			wantOut = "0 a\n1 b\n2 a\n3 \n4 \n5 b\n6 a\n"
+			wantErr = "1 oops\n5 oops\n"
+			wantErrors = 2
+		} else {
+			wantOut = "0 a\n1 b\n3 \n4 \n"
+			wantErr = "1 oops\n"
+			wantErrors = 1
+		}
+		if stdout.String() != wantOut {
+			t.Errorf("%t: stdout mismatch\nGot:  %q\nWant: %q", replay, stdout.String(), wantOut)
+		}
+		if stderr.String() != wantErr {
+			t.Errorf("%t: stderr mismatch\nGot:  %q\nWant: %q", replay, stderr.String(), wantErr)
+		}
+		if got := strings.Count(err.Error(), "failed"); got != wantErrors {
+			t.Error(replay, "Expected", wantErrors, "errors, not", got)
+		}
+		for _, ix := range []int{2, 5, 6} {
+			res := grp.RunnerResult(ix)
+			if replay == (res.Outcome == Skipped) {
+				t.Error(replay, ix, "Wrong outcome", res.Outcome)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package parallel
+
+import (
+	"io"
+	"sync"
+)
+
+// leak is the destination of all output written by detached runners. It is shared by all
+// detached runners so it serialises access to the leak io.Writer.
+type leak struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lk *leak) Write(p []byte) (int, error) {
+	lk.mu.Lock()
+	defer lk.mu.Unlock()
+
+	return lk.w.Write(p)
+}
+
+// detach abandons all runners which are not yet complete so that [Group.Wait] can return
+// while their RunFuncs continue to run. Runners which have completed are flushed as normal
+// while all other runners have any buffered output and all future output redirected to
+// the WithDetach io.Writer, if set. Pending runners are skipped and the contexts of active
+// runners are cancelled. Caller must hold grp.mu.
+func (grp *Group) detach() {
+	select {
+	case <-grp.detached:
+		return // Already detached by Cancel or a signal
+	default:
+	}
+	grp.stop(ErrDetached)
+	grp.cancel(ErrDetached)
+	lk := &leak{w: grp.leak}
+	if lk.w == nil { // Detached by WithSignalHandling without WithDetach
+		lk.w = io.Discard
+	}
+	for ix := grp.front; ix < len(grp.all); ix++ {
+		rnr := grp.all[ix]
+		switch {
+		case rnr.removed:
+		case rnr.canClose:
+			grp.closePrintRemove(rnr)
+		default:
+			rnr.detach(lk)
+			rnr.removed = true
+			grp.live--
+		}
+	}
+	grp.front = len(grp.all)
+	grp.elected = nil
+	grp.deferred = nil
+	grp.closeAdd()
+	close(grp.detached) // Wake Wait and release workers
+}
+
+// detach redirects the runner output to lk, including any output already buffered. A
+// Write blocked by LimitMemoryPerRunner remains blocked. Caller must hold grp.mu.
+func (rnr *runner) detach(lk *leak) {
+	rnr.detached = true
+	rnr.stdout.(*head).leak.Store(lk)
+	rnr.stderr.(*head).leak.Store(lk)
+	if rnr.queue != nil {
+		cq := rnr.queue.cq
+		cq.Lock()
+		cq.buf.drain(cq.orderStderr, lk, lk)
+		cq.Unlock()
+	}
+}
@@ -0,0 +1,129 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testLockedBuffer struct {
+	sync.Mutex
+	bytes.Buffer
+}
+
+func (lb *testLockedBuffer) Write(p []byte) (int, error) {
+	lb.Lock()
+	defer lb.Unlock()
+
+	return lb.Buffer.Write(p)
+}
+
+func (lb *testLockedBuffer) String() string {
+	lb.Lock()
+	defer lb.Unlock()
+
+	return lb.Buffer.String()
+}
+
+func TestDetach(t *testing.T) {
+	var stdout, leaked testLockedBuffer
+	grp, err := NewGroup(WithStdout(&stdout), WithDetach(&leaked), LimitActiveRunners(1))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("quick\n")) })
+	grp.Add("", "", func(out, err io.Writer) {
+		out.Write([]byte("stuck\n"))
+		<-release
+		out.Write([]byte("late\n"))
+		close(finished)
+	})
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("never\n")) })
+	grp.Run()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = grp.WaitContext(ctx)
+	var ue *UnfinishedError
+	if !errors.As(err, &ue) || len(ue.Runners) != 2 {
+		t.Fatal("Expected UnfinishedError with two runners, got", err)
+	}
+
+	select {
+	case <-grp.Done():
+	default:
+		t.Error("Group should be done once WaitContext returns")
+	}
+	if err = grp.Wait(); !errors.Is(err, ErrDetached) {
+		t.Error("Expected Wait to return ErrDetached, got", err)
+	}
+	errs := grp.Errors()
+	if errs[0] != nil || errs[1] != ErrDetached || errs[2] != ErrDetached {
+		t.Error("Wrong runner errors", errs)
+	}
+	if res := grp.RunnerResult(1); res.Outcome != Detached || res.Outcome.String() != "detached" {
+		t.Error("Wrong outcome for detached runner", res)
+	}
+
+	close(release) // Detached runner writes after Wait has returned
+	<-finished
+	if got := stdout.String(); got != "quick\nstuck\n" { // stuck was in foreground
+		t.Error("Wrong Group output", got)
+	}
+	if got := leaked.String(); got != "late\n" {
+		t.Error("Wrong leak output", got)
+	}
+	if strings.Contains(leaked.String()+stdout.String(), "never") {
+		t.Error("Pending runner should not have been called")
+	}
+}
+
+func TestDetachDiscard(t *testing.T) {
+	grp, err := NewGroup(WithStdout(io.Discard), WithDetach(nil))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	defer close(release)
+	grp.Add("", "", func(out, err io.Writer) { <-release; out.Write([]byte("late\n")) })
+	grp.Run()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err = grp.WaitContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Error("Expected Canceled, got", err)
+	}
+}
+
+func TestDetachBuffered(t *testing.T) {
+	var leaked testLockedBuffer
+	grp, err := NewGroup(WithStdout(io.Discard), WithDetach(&leaked))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	defer close(release)
+	written := make(chan struct{})
+	grp.Add("", "", func(out, err io.Writer) { <-release })
+	grp.Add("", "", func(out, err io.Writer) {
+		out.Write([]byte("buffered\n"))
+		close(written)
+		<-release
+	})
+	grp.Run()
+	<-written
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	grp.WaitContext(ctx)
+	if got := leaked.String(); got != "buffered\n" {
+		t.Error("Expected buffered output to be leaked, got", got)
+	}
+}
@@ -95,32 +95,39 @@ then examine "out.missed" after running your test suite.
 
 # Timeouts and error returns
 
-Unlike [GNU parallel] this package does not support detecting [RunFunc] timeouts or
-errors, nor does it offer retry attempts or job resumption. Firstly because this adds a
+A [RunFuncErr] added with [Group.AddErr] can return an error which is recorded against
+that runner. All such errors are aggregated into the error returned by [Group.Wait] and
+are individually available, in the order the runners were added, via [Group.Errors].
+
+A [RunFunc] which panics does not take down the whole program. The panic is recovered and
+recorded as a [PanicError] against that runner, any output written prior to the panic is
+still transferred, and the remaining RunFuncs continue to progress.
+
+Beyond that, unlike [GNU parallel] this package does not support detecting [RunFunc]
+timeouts, nor does it offer retry attempts or job resumption. Firstly because this adds a
 lot of opinionated complexity to the API and secondly because such features designed to
 best suit individual applications can be readily added via a closure or a struct function.
 
-As one example, if an application wants their [RunFunc] to stop the whole [Group] on error
-somewhat like [x/sync/errgroup], one approach is to create a “terminate” channel which is
-populated on error. Each [RunFunc] monitors this channel and terminates immediately if it
-is written to.
+If an application wants the whole [Group] to stop on error somewhat like
+[x/sync/errgroup], the [WithHalt] option stops starting new RunFuncs once a failure
+threshold is reached and, with a "now" policy, cancels the context supplied to each active
+[RunFuncCtx] or [RunFuncCtxErr]:
 
-	group := parallel.NewGroup()
-	terminate := make(chan any, len(os.Args))
+	group, _ := parallel.NewGroup(parallel.WithHalt("now,fail=1"))
 
 	for _, arg := range os.Args {
 	    argCopy := arg
-	    group.Add("", "",
-	         func(stdout, stderr io.Writer) {
-	            err := handleArg(terminate, argCopy, stdout, stderr)
-	            if err != nil {
-	                terminate <- any
-	            }
+	    group.AddContextErr("", "",
+	         func(ctx context.Context, stdout, stderr io.Writer) error {
+	            return handleArg(ctx, argCopy, stdout, stderr)
 	         })
 	}
 
 	group.Run()
-	group.Wait()
+	err := group.Wait()
+
+Code already structured around [x/sync/errgroup] can instead use an [ErrGroup], which
+mimics the errgroup API while serialising output.
 
 # Concurrency
 
@@ -136,9 +143,10 @@ The parallel package achieves most of its functionality via a “Pipeline” ass
 to each [RunFunc]. Output is steered thru writers in the pipeline based on the Group
 config options. Specific features are handled by different writers such as “head” and
 “tagger”. The theory being that new writers which implement future functionality can
-easily slot into the pipeline.
+easily slot into the pipeline. Applications can slot in their own writers with
+[WithPipelineWriter] or reusable [Stage] implementations with [WithStage].
 
-There are currently two types of Pipelines: Queue and Passthru.
+There are currently three types of Pipelines: Queue, Ungroup and Passthru.
 
 # Queue Pipeline
 
@@ -176,10 +184,35 @@ When a [RunFunc] becomes a candidate for foreground output (because it has perco
 the front of the queue with OrderRunners(true)), the “queue” buffered output is written to
 the Group io.Writers and the Queue Pipeline is switched to "foreground" mode.
 
+# Ungroup Pipeline
+
+The Ungroup Pipeline is created when the Group is constructed with Ungroup(true). It has no
+queue so all output is written to the Group io.Writers as soon as it is written by the
+[RunFunc]. Tags and separators are still applied.
+
+	    RunFunc
+	(stdout,   stderr)
+	   v         v
+	   |         |
+	  head      head        Adapts io.Writer to parallel.writer
+	   |         |
+	serialiser serialiser   Serialises Group output access for each Write
+	   |         |
+	 tagger    tagger       Prefix each line with 'tag' if set
+	   |         |
+	  tail      tail        Adapts parallel.writer to io.Writer
+	   |         |
+	 Group     Group
+	 stdout    stderr
+	   |         |
+	   v         v
+
 # Passthru Pipeline
 
 Passthru is a skeletal pipeline intended as a diagnostic tool which bypasses most of the
 “parallel” functionality. It is created when the Group is constructed with Passthru(true).
+Tags are still applied so that each line can be attributed to a [RunFunc], but as each
+Write is not serialised, tagged lines from concurrent RunFuncs may be intermingled.
 
 	    RunFunc
 	(stdout,   stderr)
@@ -187,6 +220,8 @@ Passthru is a skeletal pipeline intended as a diagnostic tool which bypasses mos
 	   |	     |
 	  head	    head	Adapts io.Writer to parallel.writer
 	   |	     |
+	 tagger	   tagger	Prefix each line with 'tag' if set
+	   |	     |
 	  tail	    tail	Serialises Group output access
 	   |	     |		Adapts parallel.writer to io.Writer
 	   |	     |
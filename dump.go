@@ -0,0 +1,103 @@
+package parallel
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+// dumper implements WithDumpSignal. Each signal causes the state of every runner not yet
+// removed, along with any output buffered by their queues, to be written to the Group
+// stderr.
+type dumper struct {
+	sigs     chan os.Signal
+	done     chan struct{}
+	finished chan struct{}
+}
+
+func newDumper(sigs []os.Signal) *dumper {
+	d := &dumper{sigs: make(chan os.Signal, 1), done: make(chan struct{}),
+		finished: make(chan struct{})}
+	signal.Notify(d.sigs, sigs...)
+
+	return d
+}
+
+// run is the dumper goroutine. It exits once finish is called.
+func (d *dumper) run(grp *Group) {
+	defer close(d.finished)
+	defer signal.Stop(d.sigs)
+
+	for {
+		select {
+		case <-d.sigs:
+			grp.dump()
+		case <-d.done:
+			return
+		}
+	}
+}
+
+// finish stops signal handling and waits for the run goroutine to exit.
+func (d *dumper) finish() {
+	close(d.done)
+	<-d.finished
+}
+
+// dump writes the state of the Group to stderr. The report is assembled while holding
+// grp.mu so that it is a consistent snapshot, but it is written after grp.mu is released
+// so that Wait is not stalled by a slow stderr.
+func (grp *Group) dump() {
+	var buf bytes.Buffer
+	grp.mu.Lock()
+	fmt.Fprintf(&buf, "parallel: dump: %d runners, %d not yet written\n", len(grp.all),
+		grp.live)
+	for ix := grp.front; ix < len(grp.all); ix++ {
+		rnr := grp.all[ix]
+		if rnr.removed {
+			continue
+		}
+		state := "active"
+		switch {
+		case !rnr.fed:
+			state = "pending"
+		case rnr.canClose:
+			state = "complete"
+		}
+		fmt.Fprintf(&buf, "parallel: runner %d %q: %s", rnr.index, rnr.outTag, state)
+		if rnr.queue == nil {
+			buf.WriteString("\n")
+			continue
+		}
+		rnr.queue.cq.dump(&buf)
+	}
+	grp.mu.Unlock()
+
+	grp.outputMu.Lock()
+	defer grp.outputMu.Unlock()
+	grp.stderr.Write(buf.Bytes())
+	for _, b := range grp.buffered { // Diagnostics are of no use if held back
+		b.Flush()
+	}
+}
+
+// dump writes the queue state and a copy of all buffered output to w. The buffered output
+// is left untouched.
+func (cq *commonQueue) dump(w io.Writer) {
+	cq.Lock()
+	defer cq.Unlock()
+
+	var outLen, errLen int64
+	for _, b := range cq.buf.chunks {
+		l := b.len()
+		if b.where == toStdout {
+			outLen += l
+		} else {
+			errLen += l
+		}
+	}
+	fmt.Fprintf(w, ", %s, buffered stdout %d stderr %d\n", cq.state, outLen, errLen)
+	cq.buf.transfer(w, w)
+}
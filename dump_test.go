@@ -0,0 +1,53 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDumpSignal(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), LimitActiveRunners(2),
+		WithDumpSignal(os.Interrupt))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	written := make(chan struct{})
+	grp.Add("a: ", "", func(out, err io.Writer) { <-release })
+	grp.Add("b: ", "", func(out, err io.Writer) {
+		out.Write([]byte("background\n"))
+		err.Write([]byte("oops\n"))
+		close(written)
+	})
+	grp.Add("c: ", "", func(out, err io.Writer) {})
+	grp.Run()
+
+	<-written
+	grp.dump()
+	dump := stderr.String()
+	for _, want := range []string{
+		"parallel: dump: 3 runners, 3 not yet written\n",
+		"parallel: runner 0 \"a: \": active, foreground, buffered stdout 0 stderr 0\n",
+		"parallel: runner 1 \"b: \": ",
+		"buffered stdout 11 stderr 5\nbackground\noops\n",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("Dump missing %q in %q", want, dump)
+		}
+	}
+
+	close(release)
+	grp.Wait()
+	if stdout.String() != "b: background\n" {
+		t.Errorf("Buffered output should be unaffected by dump, got %q", stdout.String())
+	}
+
+	_, err = NewGroup(WithDumpSignal())
+	if err == nil {
+		t.Error("Expected error from WithDumpSignal with no signals")
+	}
+}
@@ -0,0 +1,80 @@
+package parallel
+
+// Foreground election allows LimitMemoryPerRunner with OrderRunners(false). Without
+// ordering there is no natural front runner to switch to foreground, so if every active
+// runner reaches its memory limit, they would all stall indefinitely. Instead, whenever a
+// runner blocks and no runner is in foreground, the blocked runner with the most buffered
+// output is elected to foreground. It then writes directly to the Group io.Writers until
+// it completes. Runners which complete in the meantime are deferred until the elected
+// runner completes so that their output is not intermingled.
+
+// electForeground returns true if config requires foreground election.
+func (cfg *config) electForeground() bool {
+	return !cfg.orderRunners && (cfg.limitMemory > 0 || cfg.scheduler != nil) &&
+		!cfg.orderStderr && !cfg.passthru && !cfg.ungroup
+}
+
+// notifyBlocked wakes Wait to consider an election. The send never blocks as one pending
+// notification is as good as many.
+func (grp *Group) notifyBlocked() {
+	select {
+	case grp.blocked <- struct{}{}:
+	default:
+	}
+}
+
+// elect switches the blocked runner with the most buffered output to foreground if no
+// runner is currently elected. If WithScheduler is set, the Scheduler chooses instead.
+// Caller must hold grp.mu.
+func (grp *Group) elect() {
+	if grp.elected != nil || !grp.electForeground() {
+		return
+	}
+
+	if grp.scheduler != nil {
+		if best := grp.scheduleForeground(); best != nil {
+			grp.elected = best
+			grp.switchToForeground(best)
+		}
+		return
+	}
+
+	var best *runner
+	var most uint64
+	for ix := grp.front; ix < len(grp.all); ix++ {
+		rnr := grp.all[ix]
+		if rnr.removed || rnr.canClose || rnr.queue == nil {
+			continue
+		}
+		used, blocked := rnr.queue.cq.usage()
+		if blocked && (best == nil || used > most) {
+			best, most = rnr, used
+		}
+	}
+	if best != nil {
+		grp.elected = best
+		grp.switchToForeground(best)
+	}
+}
+
+// closeUnordered closes a completed runner with OrderRunners(false). If another runner
+// is elected, closing is deferred until the elected runner completes. Caller must hold
+// grp.mu.
+func (grp *Group) closeUnordered(rnr *runner) {
+	if grp.elected != nil && grp.elected != rnr {
+		grp.deferred = append(grp.deferred, rnr)
+		return
+	}
+
+	grp.closePrintRemove(rnr)
+	if grp.elected == rnr {
+		grp.elected = nil
+		for _, d := range grp.deferred { // In order of completion
+			grp.closePrintRemove(d)
+		}
+		grp.deferred = nil
+		grp.elect() // Others may have blocked in the meantime
+	} else if grp.scheduler != nil {
+		grp.elect() // The completion may change the Scheduler's choice
+	}
+}
@@ -0,0 +1,64 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// With OrderRunners(false) and LimitMemoryPerRunner, every active runner can exceed its
+// limit. Election must ensure they all progress and that output is still grouped.
+func TestGroupElection(t *testing.T) {
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), OrderRunners(false), LimitActiveRunners(3),
+		LimitMemoryPerRunner(10))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	if !grp.electForeground() {
+		t.Fatal("Expected electForeground to be true")
+	}
+
+	tags := []string{"a", "b", "c", "d", "e", "f"}
+	for _, tag := range tags {
+		tag := tag
+		grp.Add(tag+": ", "", func(out, err io.Writer) {
+			for i := 0; i < 5; i++ {
+				out.Write([]byte("0123456789\n"))
+			}
+		})
+	}
+	grp.Add("z: ", "", func(out, err io.Writer) { out.Write([]byte("small\n")) })
+	grp.Run()
+
+	done := make(chan struct{})
+	go func() {
+		grp.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Group stalled with OrderRunners(false) and LimitMemoryPerRunner")
+	}
+
+	// Each runner's output must be contiguous
+	lines := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+	if len(lines) != len(tags)*5+1 {
+		t.Fatal("Wrong number of output lines", len(lines), stdout.String())
+	}
+	seen := make(map[string]bool)
+	prev := ""
+	for _, line := range lines {
+		tag, _, _ := strings.Cut(line, ": ")
+		if tag != prev {
+			if seen[tag] {
+				t.Fatal("Output of", tag, "is not contiguous", stdout.String())
+			}
+			seen[tag] = true
+			prev = tag
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package parallel
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// ErrGroup adapts a [Group] to the semantics of [x/sync/errgroup] so that code already
+// structured around errgroup can adopt output serialisation with minimal change. As with
+// errgroup, functions are started as soon as they are passed to [ErrGroup.Go], the
+// context returned by [NewErrGroup] is cancelled as soon as any function returns a
+// non-nil error, and [ErrGroup.Wait] returns the first such error.
+//
+// The main difference is that functions are supplied with stdout and stderr io.Writers
+// which must be used for all output, just as with a [RunFuncErr]. Functions which have not
+// been started when the context is cancelled are skipped. An ErrGroup cannot be reused
+// once Wait has returned.
+//
+// [x/sync/errgroup]: https://pkg.go.dev/golang.org/x/sync/errgroup
+type ErrGroup struct {
+	grp    *Group
+	cancel context.CancelCauseFunc
+
+	mu  sync.Mutex
+	err error // First error returned by a function
+}
+
+// NewErrGroup returns a new ErrGroup and an associated context derived from ctx, much like
+// errgroup.WithContext. The opts are passed to [NewGroup] with [OpenEnded] implicitly set
+// true. The errgroup SetLimit equivalent is the [LimitActiveRunners] option. An error is
+// only returned if the opts are invalid.
+func NewErrGroup(ctx context.Context, opts ...Option) (*ErrGroup, context.Context, error) {
+	grp, err := NewGroup(append(opts, OpenEnded(true))...)
+	if err != nil {
+		return nil, nil, err
+	}
+	eg := &ErrGroup{grp: grp}
+	ctx, eg.cancel = context.WithCancelCause(ctx)
+	grp.RunContext(ctx)
+
+	return eg, ctx, nil
+}
+
+// Go calls the given function in a new goroutine, subject to any [LimitActiveRunners]
+// constraint. The first call to return a non-nil error cancels the ErrGroup context. Go
+// can be called concurrently and must not be called after [ErrGroup.Wait].
+func (eg *ErrGroup) Go(f RunFuncErr) {
+	eg.GoTagged("", "", f)
+}
+
+// GoTagged is identical to [ErrGroup.Go] except that the outTag and errTag are applied
+// as described in [Group.Add].
+func (eg *ErrGroup) GoTagged(outTag, errTag string, f RunFuncErr) {
+	eg.grp.AddErr(outTag, errTag, func(stdout, stderr io.Writer) error {
+		err := f(stdout, stderr)
+		if err != nil {
+			eg.setErr(err)
+		}
+		return err
+	})
+}
+
+func (eg *ErrGroup) setErr(err error) {
+	eg.mu.Lock()
+	defer eg.mu.Unlock()
+
+	if eg.err == nil {
+		eg.err = err
+		eg.cancel(err)
+	}
+}
+
+// Wait blocks until all functions started by [ErrGroup.Go] have returned and their output
+// has been written, then returns the first non-nil error, if any. If no function returned
+// an error, any other error reported by [Group.Wait], such as a [PanicError], is
+// returned instead.
+func (eg *ErrGroup) Wait() error {
+	eg.grp.CloseAdd()
+	err := eg.grp.Wait()
+	eg.cancel(nil)
+
+	eg.mu.Lock()
+	defer eg.mu.Unlock()
+	if eg.err != nil {
+		return eg.err
+	}
+
+	return err
+}
@@ -0,0 +1,67 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestErrGroup(t *testing.T) {
+	var stdout bytes.Buffer
+	eg, ctx, err := NewErrGroup(context.Background(), WithStdout(&stdout))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	for _, s := range []string{"one\n", "two\n", "three\n"} {
+		s := s
+		eg.GoTagged("t: ", "", func(out, err io.Writer) error {
+			out.Write([]byte(s))
+			return nil
+		})
+	}
+	if err = eg.Wait(); err != nil {
+		t.Error("Unexpected Wait error", err)
+	}
+	if stdout.String() != "t: one\nt: two\nt: three\n" {
+		t.Errorf("Output wrong %q", stdout.String())
+	}
+	if ctx.Err() == nil {
+		t.Error("Expected context to be cancelled after Wait")
+	}
+
+	// First error cancels the context and is returned by Wait
+	failed := errors.New("failed")
+	eg, ctx, err = NewErrGroup(context.Background(), WithStdout(io.Discard),
+		LimitActiveRunners(1))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	eg.Go(func(out, err io.Writer) error { return failed })
+	eg.Go(func(out, err io.Writer) error {
+		<-ctx.Done()
+		return errors.New("second")
+	})
+	if err = eg.Wait(); err != failed {
+		t.Error("Expected first error from Wait, got", err)
+	}
+	if context.Cause(ctx) != failed {
+		t.Error("Expected context cause to be first error, got", context.Cause(ctx))
+	}
+
+	eg, _, err = NewErrGroup(context.Background(), WithStderr(io.Discard))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	eg.Go(func(out, err io.Writer) error { panic("boom") })
+	var pe *PanicError
+	if err = eg.Wait(); !errors.As(err, &pe) {
+		t.Error("Expected PanicError from Wait, got", err)
+	}
+
+	_, _, err = NewErrGroup(context.Background(), LimitMemoryPerRunner(1))
+	if err == nil {
+		t.Error("Expected error from invalid options")
+	}
+}
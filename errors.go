@@ -0,0 +1,76 @@
+package parallel
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrHalted is recorded against runners which were skipped because the [WithHalt]
+// threshold was reached. It is also included in the error returned by [Group.Wait] once a
+// Group has halted.
+var ErrHalted = errors.New("parallel: Group halted")
+
+// ErrInterrupted is recorded against runners which were skipped because a
+// [WithSignalHandling] signal was received. It is also included in the error returned by
+// [Group.Wait] once a Group has been interrupted.
+var ErrInterrupted = errors.New("parallel: Group interrupted")
+
+// ErrCanceled is recorded against runners which were skipped because [Group.Cancel] was
+// called. It is also included in the error returned by [Group.Wait] once a Group has been
+// cancelled.
+var ErrCanceled = errors.New("parallel: Group canceled")
+
+// ErrDetached is recorded against runners which were abandoned because of [WithDetach].
+// It is also included in the error returned by [Group.Wait] once runners are detached.
+var ErrDetached = errors.New("parallel: runner detached")
+
+// ErrBrokenPipe is recorded against runners which were skipped because a Group
+// io.Writer returned a broken pipe error, such as when output is piped to head(1). It is
+// also included in the error returned by [Group.Wait] once a broken pipe is detected.
+var ErrBrokenPipe = errors.New("parallel: Group output broken pipe")
+
+// UnfinishedError is returned by [Group.WaitContext] when its context is done before all
+// RunFuncs have completed.
+type UnfinishedError struct {
+	Err     error        // The context error or cause
+	Runners []RunnerInfo // Runners whose output has not yet been written, in creation order
+}
+
+func (ue *UnfinishedError) Error() string {
+	return fmt.Sprintf("parallel: %d runners unfinished: %v", len(ue.Runners), ue.Err)
+}
+
+func (ue *UnfinishedError) Unwrap() error {
+	return ue.Err
+}
+
+// WriteError records a failure to write the buffered output of a runner to a Group
+// io.Writer, such as when the disk is full or a pipe is closed. Once a write fails, the
+// remaining output of that runner stream is discarded. The WriteError of each affected
+// runner is available from [RunnerResult] and the first is included in the error returned
+// by [Group.Wait].
+type WriteError struct {
+	Index  int    // Order in which the runner was added, starting at zero
+	OutTag string // As supplied to Add
+	Err    error  // As returned by the Group io.Writer
+}
+
+func (we *WriteError) Error() string {
+	return fmt.Sprintf("parallel: runner %d output write failed: %v", we.Index, we.Err)
+}
+
+func (we *WriteError) Unwrap() error {
+	return we.Err
+}
+
+// PanicError is recorded against a runner when its RunFunc panics. The panic is recovered
+// by the Group so that the remaining RunFuncs continue to progress and any output written
+// prior to the panic is still transferred to the Group io.Writers.
+type PanicError struct {
+	Value any    // As returned by recover()
+	Stack []byte // As returned by [runtime/debug.Stack] at the time of recovery
+}
+
+func (pe *PanicError) Error() string {
+	return fmt.Sprintf("parallel: RunFunc panic: %v", pe.Value)
+}
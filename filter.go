@@ -0,0 +1,80 @@
+package parallel
+
+import (
+	"bytes"
+	"sync"
+)
+
+// LineFilter decides whether a line of RunFunc output is kept. The line excludes the line
+// delimiter. Return true to keep the line. See [WithLineFilter].
+type LineFilter func(stream Stream, line []byte) bool
+
+// DropLinePrefix returns a LineFilter which drops all lines starting with any of the
+// prefixes, such as "DEBUG:".
+func DropLinePrefix(prefixes ...string) LineFilter {
+	return func(_ Stream, line []byte) bool {
+		for _, prefix := range prefixes {
+			if bytes.HasPrefix(line, []byte(prefix)) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// lineFilter is a writer which only passes lines accepted by the LineFilter. Incomplete
+// lines are held back until they are completed or the writer is closed.
+type lineFilter struct {
+	mu sync.Mutex
+	commonWriter
+	keep    LineFilter
+	stream  Stream
+	delim   byte // Line delimiter - see WithLineDelimiter
+	partial []byte
+}
+
+func newLineFilter(out writer, keep LineFilter, stream Stream, delim byte) *lineFilter {
+	wtr := &lineFilter{keep: keep, stream: stream, delim: delim}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+// Write returns the length of p on success as dropped lines are consumed even tho they
+// are not written downstream. The first downstream error is returned.
+func (wtr *lineFilter) Write(p []byte) (n int, err error) {
+	wtr.mu.Lock()
+	defer wtr.mu.Unlock()
+
+	wtr.partial = append(wtr.partial, p...)
+	for {
+		ix := bytes.IndexByte(wtr.partial, wtr.delim)
+		if ix < 0 {
+			break
+		}
+		line := wtr.partial[:ix+1]
+		wtr.partial = wtr.partial[ix+1:]
+		if !wtr.keep(wtr.stream, line[:ix]) {
+			continue
+		}
+		if _, e := wtr.out.Write(line); e != nil && err == nil {
+			err = e
+		}
+	}
+	if len(wtr.partial) == 0 {
+		wtr.partial = nil // Release the backing array
+	}
+
+	return len(p), err
+}
+
+func (wtr *lineFilter) close() {
+	wtr.mu.Lock()
+	if len(wtr.partial) > 0 && wtr.keep(wtr.stream, wtr.partial) {
+		wtr.out.Write(wtr.partial)
+	}
+	wtr.partial = nil
+	wtr.mu.Unlock()
+	wtr.out.close() // Pass it on
+}
@@ -0,0 +1,51 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestLineFilter(t *testing.T) {
+	if _, err := NewGroup(WithLineFilter(nil)); err == nil {
+		t.Error("Expected error with nil filter")
+	}
+
+	errOnly := func(stream Stream, line []byte) bool {
+		return stream == Stdout || !bytes.HasPrefix(line, []byte("warn"))
+	}
+	testCases := []struct {
+		keep   LineFilter
+		stdout string
+		stderr string
+	}{
+		{DropLinePrefix("DEBUG:", "TRACE:"), "t: one\nt: two\nt: warn\nt: tail",
+			"t: warn\n"},
+		{errOnly, "t: one\nt: DEBUG: x\nt: two\nt: TRACE: y\nt: warn\nt: tail", ""},
+	}
+	for _, opts := range [][]Option{{}, {OrderRunners(false), Passthru(true)}} {
+		for ix, tc := range testCases {
+			var stdout, stderr bytes.Buffer
+			opts := append(opts, WithStdout(&stdout), WithStderr(&stderr),
+				WithLineFilter(tc.keep))
+			grp, err := NewGroup(opts...)
+			if err != nil {
+				t.Fatal("Unexpected setup error", err)
+			}
+			grp.Add("t: ", "t: ", func(out, err io.Writer) {
+				out.Write([]byte("one\nDEBUG: x\ntw"))
+				out.Write([]byte("o\nTRACE: y\nwarn\ntail"))
+				err.Write([]byte("warn\n"))
+			})
+			grp.Run()
+			grp.Wait()
+
+			if got := stdout.String(); got != tc.stdout {
+				t.Errorf("%d: Wrong stdout %q", ix, got)
+			}
+			if got := stderr.String(); got != tc.stderr {
+				t.Errorf("%d: Wrong stderr %q", ix, got)
+			}
+		}
+	}
+}
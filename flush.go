@@ -0,0 +1,135 @@
+package parallel
+
+import (
+	"sync"
+	"time"
+)
+
+// flusher is implemented by Group io.Writers which buffer their output, such as
+// *bufio.Writer.
+type flusher interface {
+	Flush() error
+}
+
+// httpFlusher has the same method set as http.Flusher, which is implemented by the
+// http.ResponseWriter of a streaming HTTP response, without importing net/http.
+type httpFlusher interface {
+	Flush()
+}
+
+// flusherFunc adapts a function to a flusher.
+type flusherFunc func() error
+
+func (f flusherFunc) Flush() error {
+	return f()
+}
+
+// flushTimer periodically flushes the Group io.Writers which implement flusher. Flushes
+// are made under the protection of the Group output mutex so that they never interleave
+// with a Write made by a runner pipeline.
+type flushTimer struct {
+	interval time.Duration
+	clock    Clock
+	writers  []flusher
+	outputMu *sync.Mutex
+
+	stopOnce sync.Once
+	stop     chan struct{} // Closed to stop flushing goroutine
+	done     chan struct{} // Closed by flushing goroutine on exit
+}
+
+func newFlushTimer(interval time.Duration, clock Clock, writers []flusher,
+	outputMu *sync.Mutex) *flushTimer {
+	return &flushTimer{interval: interval, clock: clock, writers: writers,
+		outputMu: outputMu, stop: make(chan struct{}), done: make(chan struct{})}
+}
+
+// flushers returns the Group io.Writers which implement flusher followed by a flusher of
+// the original Group io.Writers which implement httpFlusher, if any, so that buffered
+// output reaches the HTTP client.
+func (grp *Group) flushers() (flushers []flusher) {
+	for _, w := range grp.writers() {
+		if f, ok := w.(flusher); ok {
+			flushers = append(flushers, f)
+		}
+	}
+	if len(grp.httpFlush) > 0 {
+		flushers = append(flushers, flusherFunc(grp.flushHTTP))
+	}
+
+	return
+}
+
+// httpWriters returns the Group io.Writers which implement httpFlusher. It must be
+// called before the Group io.Writers are replaced by tees, async or buffered writers.
+func (grp *Group) httpWriters() (flushers []httpFlusher) {
+	for _, w := range grp.writers() {
+		if f, ok := w.(httpFlusher); ok {
+			flushers = append(flushers, f)
+		}
+	}
+
+	return
+}
+
+// flushHTTP flushes the Group io.Writers which implement httpFlusher, such as an
+// http.ResponseWriter. With WithAsyncOutput the flush is queued behind pending writes so
+// that it is made by the writing goroutine. Caller must hold grp.outputMu.
+func (grp *Group) flushHTTP() error {
+	for _, f := range grp.httpFlush {
+		if grp.async != nil {
+			grp.async.flush(f)
+		} else {
+			f.Flush()
+		}
+	}
+
+	return nil
+}
+
+// flushRunnerHTTP flushes the httpFlusher Group io.Writers at a runner boundary so that
+// an HTTP client sees the output of each runner as soon as it is complete.
+func (grp *Group) flushRunnerHTTP() {
+	grp.outputMu.Lock()
+	defer grp.outputMu.Unlock()
+
+	grp.flushHTTP()
+}
+
+// run flushes every interval until stopped at which time a final flush is made.
+func (ft *flushTimer) run() {
+	defer close(ft.done)
+	if len(ft.writers) == 0 { // Nothing to do
+		<-ft.stop
+		return
+	}
+	timer := ft.clock.NewTimer(ft.interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C():
+			ft.flush()
+			timer.Reset(ft.interval)
+		case <-ft.stop:
+			ft.flush()
+			return
+		}
+	}
+}
+
+// finish stops the flushing goroutine and waits for it to make the final flush.
+func (ft *flushTimer) finish() {
+	ft.stopOnce.Do(func() { close(ft.stop) })
+	<-ft.done
+}
+
+// flush flushes all writers. Errors are ignored as they will normally be returned by
+// the next Write.
+func (ft *flushTimer) flush() {
+	ft.outputMu.Lock()
+	defer ft.outputMu.Unlock()
+	for _, f := range ft.writers {
+		f.Flush()
+	}
+}
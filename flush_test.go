@@ -0,0 +1,32 @@
+package parallel
+
+import (
+	"bufio"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestFlushInterval(t *testing.T) {
+	var out testLockedBuffer
+	stdout := bufio.NewWriter(&out)
+	grp, err := NewGroup(WithStdout(stdout), WithFlushInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("", "", func(o, e io.Writer) {
+		o.Write([]byte("live\n"))
+		waitFor(t, &out, "live\n") // Only possible if flushed by the timer
+		o.Write([]byte("last\n"))
+	})
+	grp.Run()
+	grp.Wait()
+
+	if got := out.String(); got != "live\nlast\n" { // Final flush by Wait
+		t.Errorf("Wrong output %q", got)
+	}
+
+	if _, err := NewGroup(WithFlushInterval(0)); err == nil {
+		t.Error("Expected error with zero interval")
+	}
+}
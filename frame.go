@@ -0,0 +1,103 @@
+package parallel
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// framer is a writer which replaces the tagger when WithFramedOutput is set. Each Write is
+// encoded as a single frame and passed downstream with a single Write so that frames are
+// never split by the output of other runners.
+type framer struct {
+	commonWriter
+	header string // Everything but the length
+}
+
+func newFramer(out writer, rnr *runner, stream Stream) *framer {
+	wtr := &framer{header: fmt.Sprintf("%d %s ", rnr.index+1, stream)}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+func (wtr *framer) Write(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	frame := make([]byte, 0, len(wtr.header)+len(p)+12)
+	frame = append(frame, wtr.header...)
+	frame = strconv.AppendInt(frame, int64(len(p)), 10)
+	frame = append(frame, '\n')
+	frame = append(frame, p...)
+	_, err = wtr.out.Write(frame)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (wtr *framer) close() {
+	wtr.out.close() // Pass it on
+}
+
+// Frame is a single RunFunc Write decoded by [FrameDecoder].
+type Frame struct {
+	Seq    int    // Order in which the RunFunc was added, starting at 1
+	Stream Stream // Which stream the RunFunc wrote to
+	Data   []byte // Exactly as written by the RunFunc
+}
+
+// FrameDecoder decodes the output of a Group created with [WithFramedOutput].
+type FrameDecoder struct {
+	r *bufio.Reader
+}
+
+// NewFrameDecoder returns a FrameDecoder which reads frames from r.
+func NewFrameDecoder(r io.Reader) *FrameDecoder {
+	return &FrameDecoder{r: bufio.NewReader(r)}
+}
+
+// Next returns the next Frame. At the end of the input it returns io.EOF. A truncated
+// frame returns io.ErrUnexpectedEOF and a malformed header returns a descriptive error.
+func (fd *FrameDecoder) Next() (f Frame, err error) {
+	header, err := fd.r.ReadString('\n')
+	if err != nil {
+		if err == io.EOF && len(header) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return f, fmt.Errorf("Malformed frame header %q", header)
+	}
+	f.Seq, err = strconv.Atoi(fields[0])
+	if err != nil {
+		return f, fmt.Errorf("Malformed frame sequence %q", fields[0])
+	}
+	switch fields[1] {
+	case "stdout":
+		f.Stream = Stdout
+	case "stderr":
+		f.Stream = Stderr
+	default:
+		return f, fmt.Errorf("Malformed frame stream %q", fields[1])
+	}
+	length, err := strconv.Atoi(fields[2])
+	if err != nil || length < 0 {
+		return f, fmt.Errorf("Malformed frame length %q", fields[2])
+	}
+
+	f.Data = make([]byte, length)
+	_, err = io.ReadFull(fd.r, f.Data)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return
+}
@@ -0,0 +1,63 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFramedRoundTrip(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), WithFramedOutput(true))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("ignored", "", func(out, err io.Writer) {
+		out.Write([]byte("line1\nline2\n"))
+		err.Write([]byte("no newline"))
+	})
+	grp.Add("", "", func(out, err io.Writer) {
+		out.Write([]byte{0, 1, '\n', 2})
+		out.Write(nil) // Empty writes produce no frame
+	})
+	grp.Run()
+	grp.Wait()
+
+	if stderr.Len() != 0 {
+		t.Error("All frames should go to stdout", stderr.String())
+	}
+	expect := []Frame{
+		{1, Stdout, []byte("line1\nline2\n")},
+		{1, Stderr, []byte("no newline")},
+		{2, Stdout, []byte{0, 1, '\n', 2}},
+	}
+	fd := NewFrameDecoder(&stdout)
+	for ix, e := range expect {
+		f, err := fd.Next()
+		if err != nil {
+			t.Fatal(ix, "Unexpected decode error", err)
+		}
+		if f.Seq != e.Seq || f.Stream != e.Stream || !bytes.Equal(f.Data, e.Data) {
+			t.Error(ix, "Frame mismatch", f, e)
+		}
+	}
+	if _, err := fd.Next(); err != io.EOF {
+		t.Error("Expected EOF, got", err)
+	}
+
+	_, err = NewGroup(WithFramedOutput(true), WithJSONOutput(true))
+	if err == nil {
+		t.Error("Expected conflict between WithFramedOutput and WithJSONOutput")
+	}
+}
+
+func TestFrameDecoderErrors(t *testing.T) {
+	for _, input := range []string{"1 stdout", "1 stdout 5\nabc", "x stdout 1\na",
+		"1 other 1\na", "1 stdout -1\n", "1 stdout\n"} {
+		_, err := NewFrameDecoder(strings.NewReader(input)).Next()
+		if err == nil || err == io.EOF {
+			t.Errorf("Expected decode error for %q, got %v", input, err)
+		}
+	}
+}
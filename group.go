@@ -1,9 +1,15 @@
 package parallel
 
 import (
-	"container/list"
+	"bufio"
+	"context"
+	"errors"
 	"io"
+	"os"
+	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
 )
 
 type groupState int
@@ -39,7 +45,8 @@ func (gs groupState) String() string {
 //
 // Group has a strict calling sequence: Multiple [Group.Add] calls followed by [Group.Run]
 // followed by [Group.Wait] after which no calls to the Group are valid. Any deviation
-// from this call sequence results in a panic.
+// from this call sequence results in a panic. The exception is an [OpenEnded] Group which
+// also allows [Group.Add] calls after [Group.Run] up until [Group.CloseAdd] is called.
 //
 // A Group cannot be reused, however multiple Groups can be created and used independently
 // of each other or in fact nested. There is nothing stopping a [RunFunc] from creating a
@@ -47,15 +54,65 @@ func (gs groupState) String() string {
 //
 // A Group is not concurrency-safe and must only be accessed by a single goroutine at a
 // time. This does not imply anything about the concurrency of RunFuncs which normally are
-// run concurrently and which are supplied with concurrency-safe io.Writers.
+// run concurrently and which are supplied with concurrency-safe io.Writers. The one
+// exception is an [OpenEnded] Group which allows [Group.Add] and [Group.CloseAdd] to be
+// called concurrently with [Group.Wait].
 type Group struct {
-	state   groupState // Ensure correct calling sequences
-	runners *list.List // Appended in creation order
+	mu        sync.Mutex    // Protects everything up to the next comment
+	state     groupState    // Ensure correct calling sequences
+	all       []*runner     // Every runner in creation or OrderBy order, for Errors()
+	added     []*runner     // Every runner in creation order - only set with OrderBy
+	front     int           // Index in all of the oldest runner not yet removed
+	live      int           // Count of runners not yet removed
+	nextFeed  int           // Index in all of the oldest runner not yet fed
+	feedCond  *sync.Cond    // Signals feeder that nextFeed or addClosed changed
+	addClosed bool          // No more Add calls are valid
+	addDone   chan struct{} // Closed when addClosed is set so Wait notices
+	elected   *runner       // Foreground runner elected with OrderRunners(false)
+	deferred  []*runner     // Completed runners waiting for the elected runner
+	emitted   []*runner     // Removed runners not yet yielded by Results. Nil if unused
+	emitCond  *sync.Cond    // Signals Results and WaitN of removals or state change
+	bgWait    bool          // Done has started wait in the background
+	done      chan struct{} // Closed once wait completes
+	detached  chan struct{} // Closed once unfinished runners are detached
+	waitErr   error         // As returned by wait
+	canceled  error         // Set to ErrCanceled by Cancel
+	writeErr  error         // First runner *WriteError
+	resultErr error         // First WithResultsDir error
 
 	// Shared across all runners
 	outputMu sync.Mutex // Serialise access to config.stdout, config.stderr
+	sepOwed  bool       // Separators owed prior to the next output - protected by outputMu
 	*config
-	runnerDone chan *list.Element // Element is contained in runners LL
+	runnerDone chan *runner            // Workers write, Wait reads
+	todo       chan *runner            // Feeder writes, workers read
+	ctx        context.Context         // Parent of all runner contexts
+	cancel     context.CancelCauseFunc // Cancels ctx once Wait completes
+	dispatch   context.Context         // Runners are skipped once this is cancelled
+	stop       context.CancelCauseFunc // Cancels dispatch
+	halt       haltState               // Tracks progress towards WithHalt threshold
+	colorOut   bool                    // Color outTags as stdout is a terminal
+	colorErr   bool                    // Color errTags as stderr is a terminal
+	progress   *progress               // Only set if WithProgress is set
+	auto       *autoLimiter            // Only set if LimitActiveRunnersAuto is set
+	starter    *startLimiter           // Only set if WithStartDelay or WithStartRate are set
+	signals    *signalHandler          // Only set if WithSignalHandling is set
+	dumper     *dumper                 // Only set if WithDumpSignal is set
+	rotor      *rotor                  // Only set if WithRoundRobin is set
+	flusher    *flushTimer             // Only set if WithFlushInterval is set
+	buffered   []*bufio.Writer         // Only set if WithBufferedOutput is set
+	unbuffered []io.Writer             // Group io.Writers replaced by buffered, async or tees
+	tees       []*teeWriter            // Only set if WithStdoutTee or WithStderrTee is set
+	httpFlush  []httpFlusher           // Group io.Writers flushed at each runner boundary
+	async      *asyncOutput            // Only set if WithAsyncOutput is set
+	rateLimit  *rateLimiter            // Only set if WithOutputRateLimit is set
+	recorder   *recorder               // Only set if WithRecorder is set
+	dedupFirst map[string]*runner      // Only set if DedupRunners is set
+	blocked    chan struct{}           // Queues notify Wait when a Write blocks
+	started    atomic.Int64            // Runners taken by workers, for Metrics
+	completed  atomic.Int64            // Runners finished by workers, for Metrics
+	slots      slots                   // Job slots of active RunFuncs
+	broken     atomic.Bool             // A Group io.Writer has a broken pipe
 }
 
 // NewGroup constructs a [Group] ready for use. A [Group] must be constructed with this
@@ -79,25 +136,24 @@ type Group struct {
 // returned. Most errors are caused by Options which create the possibility that a
 // [RunFunc] could stall indefinitely. See [Option] for more details.
 func NewGroup(opts ...Option) (*Group, error) {
-	cfg := newConfig()
-
-	for _, opt := range opts {
-		err := opt.apply(cfg)
-		if err != nil {
-			return nil, err
-		}
-	}
+	return newGroup(newConfig(), opts...)
+}
 
-	// Make sure config is internally consistent
-	err := cfg.checkConflicts()
+// newGroup applies opts to cfg and creates a Group with the resulting config.
+func newGroup(cfg *config, opts ...Option) (*Group, error) {
+	err := cfg.applyOptions(opts...)
 	if err != nil {
 		return nil, err
 	}
 
 	grp := &Group{state: groupIsAdding,
-		runnerDone: make(chan *list.Element),
-		config:     cfg,
-		runners:    list.New()}
+		runnerDone: make(chan *runner),
+		addDone:    make(chan struct{}),
+		done:       make(chan struct{}),
+		detached:   make(chan struct{}),
+		config:     cfg}
+	grp.feedCond = sync.NewCond(&grp.mu)
+	grp.emitCond = sync.NewCond(&grp.mu)
 
 	return grp, nil
 }
@@ -137,6 +193,9 @@ func NewGroup(opts ...Option) (*Group, error) {
 //	group.Run()
 //	group.Wait()
 //
+// If the only reason for the closure is to pass in a context, consider using
+// [Group.AddContext] with a [RunFuncCtx] instead.
+//
 // An alternative strategy is to use a struct function which satisfies the [RunFunc]
 // signature as shown here:
 //
@@ -156,6 +215,32 @@ func NewGroup(opts ...Option) (*Group, error) {
 //	}
 type RunFunc func(stdout, stderr io.Writer)
 
+// RunFuncCtx is the context-aware variant of [RunFunc] added to a Group with
+// [Group.AddContext]. Apart from the additional context.Context parameter it has
+// identical semantics to [RunFunc].
+//
+// The supplied context is created by the Group when the RunFuncCtx is started and is
+// cancelled when the RunFuncCtx returns or when the Group completes. Long-running
+// RunFuncCtxs should monitor ctx.Done() and return promptly once it is closed.
+type RunFuncCtx func(ctx context.Context, stdout, stderr io.Writer)
+
+// RunFuncErr is the error-returning variant of [RunFunc] added to a Group with
+// [Group.AddErr]. Apart from returning an error it has identical semantics to [RunFunc].
+//
+// The returned error is recorded against the runner and is available after [Group.Wait]
+// via [Group.Errors]. All non-nil errors are also aggregated into the error returned by
+// [Group.Wait].
+type RunFuncErr func(stdout, stderr io.Writer) error
+
+// RunFuncCtxErr combines [RunFuncCtx] and [RunFuncErr] and is added to a Group with
+// [Group.AddContextErr]. It is most useful in conjunction with [WithHalt] as the returned
+// error contributes to the halt policy and the context is cancelled when a "now" policy
+// halts the Group.
+type RunFuncCtxErr func(ctx context.Context, stdout, stderr io.Writer) error
+
+// runFunc is the internal signature all public RunFunc variants are adapted to.
+type runFunc func(ctx context.Context, stdout, stderr io.Writer) error
+
 // Add appends the supplied RunFunc to the Group in anticipation of [Group.Run]. Typically
 // a [RunFunc] is implemented as either a closure or a struct function so as to pass
 // additional parameters to the underlying function. See [RunFunc] for details.
@@ -166,10 +251,171 @@ type RunFunc func(stdout, stderr io.Writer)
 //
 // The outTag and errTag strings are prepended to all output written by the RunFunc to
 // stdout and stderr respectively and help mimic the “--tag” option in GNU parallel.
-func (grp *Group) Add(outTag, errTag string, rFunc RunFunc) {
-	grp.checkState(groupIsAdding)
+//
+// Any [RunnerOption]s apply to this RunFunc only.
+//
+// Normally Add can only be called prior to [Group.Run]. If the Group is constructed with
+// [OpenEnded] set true, Add can also be called after [Group.Run], and concurrently with
+// [Group.Wait], up until [Group.CloseAdd] is called.
+func (grp *Group) Add(outTag, errTag string, rFunc RunFunc, opts ...RunnerOption) {
+	grp.add(outTag, errTag,
+		func(_ context.Context, stdout, stderr io.Writer) error {
+			rFunc(stdout, stderr)
+			return nil
+		}, opts)
+}
+
+// AddContext is identical to [Group.Add] except that the supplied [RunFuncCtx] is passed
+// a per-runner context.Context derived from the Group context. This saves each caller
+// from having to capture their own context in a closure simply to observe cancellation.
+func (grp *Group) AddContext(outTag, errTag string, rFunc RunFuncCtx, opts ...RunnerOption) {
+	grp.add(outTag, errTag,
+		func(ctx context.Context, stdout, stderr io.Writer) error {
+			rFunc(ctx, stdout, stderr)
+			return nil
+		}, opts)
+}
+
+// AddErr is identical to [Group.Add] except that the supplied [RunFuncErr] returns an
+// error which is recorded against the runner. See [Group.Errors] and [Group.Wait].
+func (grp *Group) AddErr(outTag, errTag string, rFunc RunFuncErr, opts ...RunnerOption) {
+	grp.add(outTag, errTag,
+		func(_ context.Context, stdout, stderr io.Writer) error {
+			return rFunc(stdout, stderr)
+		}, opts)
+}
+
+// AddContextErr is identical to [Group.Add] except that the supplied [RunFuncCtxErr] is
+// passed a per-runner context as described in [Group.AddContext] and returns an error as
+// described in [Group.AddErr].
+func (grp *Group) AddContextErr(outTag, errTag string, rFunc RunFuncCtxErr,
+	opts ...RunnerOption) {
+	grp.add(outTag, errTag, runFunc(rFunc), opts)
+}
+
+// AddArg adds a RunFunc which is passed arg, much as GNU parallel passes each argument to
+// a command. The arg is also used as both the outTag and errTag followed by the
+// [WithTagSuffix] suffix, which defaults to a tab for AddArg, or by the suffix supplied
+// with [RunnerTagSuffix]. As arg is passed as a parameter, AddArg avoids the need for a
+// closure and the loop-variable capture bug that comes with it in pre 1.22 Go:
+//
+//	for _, arg := range os.Args[1:] {
+//		group.AddArg(arg, process)
+//	}
+//
+// In all other respects AddArg is identical to [Group.Add].
+func (grp *Group) AddArg(arg string, fn func(arg string, stdout, stderr io.Writer),
+	opts ...RunnerOption) {
+	opts = append([]RunnerOption{runnerOption(func(rnr *runner) { rnr.suffixTags = true })},
+		opts...)
+	grp.add(arg, arg,
+		func(_ context.Context, stdout, stderr io.Writer) error {
+			fn(arg, stdout, stderr)
+			return nil
+		}, opts)
+}
+
+// add is the common implementation of all the public Add variants. If the Group is
+// already running, the new runner has its pipeline built immediately and is passed to
+// the feeder. If it is also the only live runner, it is eligible for foreground.
+func (grp *Group) add(outTag, errTag string, rFunc runFunc, opts []RunnerOption) {
+	grp.mu.Lock()
+	defer grp.mu.Unlock()
+
+	if grp.state == groupIsAdding {
+		grp.checkAdding()
+		rnr := grp.newRunner(outTag, errTag, rFunc, opts)
+		grp.all = append(grp.all, rnr)
+		grp.live++
+		return
+	}
+
+	if !grp.openEnded {
+		grp.checkState(groupIsAdding) // Panics
+	}
+	grp.checkAdding()
+	if grp.state != groupIsRunning && grp.state != groupIsWaiting {
+		grp.checkState(groupIsRunning) // Panics
+	}
+
+	rnr := grp.newRunner(outTag, errTag, rFunc, opts)
+	grp.buildPipeline(rnr, false)
+	grp.all = append(grp.all, rnr)
+	grp.live++
+	if grp.live == 1 && grp.foregroundAllowed() {
+		grp.switchToForeground(rnr)
+	}
+
+	if grp.progress != nil {
+		grp.progress.added.Add(1)
+	}
+
+	if grp.limitRunners == 0 { // One worker per runner when there is no limit
+		go grp.worker()
+	}
+	rnr.queued = grp.clock.Now()
+	grp.feedCond.Signal()
+}
+
+// newRunner constructs the next runner in the Group, applying the Group defaults and then
+// any RunnerOptions. Caller must hold grp.mu.
+func (grp *Group) newRunner(outTag, errTag string, rFunc runFunc, opts []RunnerOption) *runner {
 	rnr := newRunner(outTag, errTag, rFunc)
-	grp.runners.PushBack(rnr)
+	rnr.index = len(grp.all)
+	rnr.discardOut = grp.discardStdout
+	rnr.discardErr = grp.discardStderr
+	rnr.pty = grp.commandPTY
+	rnr.newDecoder = grp.newDecoder
+	rnr.tagSuffix, rnr.suffixTags = grp.tagSuffix, grp.suffixTags
+	for _, opt := range opts {
+		opt.applyRunner(rnr)
+	}
+	if grp.dedupKey != nil {
+		rnr.dedupKey = grp.dedupKey(rnr.info())
+	}
+	if rnr.suffixTags {
+		rnr.outTag = appendSuffix(rnr.outTag, rnr.tagSuffix)
+		rnr.errTag = appendSuffix(rnr.errTag, rnr.tagSuffix)
+	}
+
+	return rnr
+}
+
+// appendSuffix returns tag with suffix appended unless tag is empty.
+func appendSuffix(tag []byte, suffix string) []byte {
+	if len(tag) == 0 {
+		return tag
+	}
+
+	return append(tag, suffix...)
+}
+
+// CloseAdd signals that no more runners will be added to an [OpenEnded] Group. Once all
+// previously added runners have completed, [Group.Wait] returns. CloseAdd is idempotent
+// and can be called from any goroutine. It is not necessary to call CloseAdd for a Group
+// which is not [OpenEnded] as [Group.Run] implicitly closes adding.
+func (grp *Group) CloseAdd() {
+	grp.mu.Lock()
+	defer grp.mu.Unlock()
+
+	grp.closeAdd()
+}
+
+// closeAdd is the lock-free implementation of CloseAdd. Caller must hold grp.mu.
+func (grp *Group) closeAdd() {
+	if grp.addClosed {
+		return
+	}
+	grp.addClosed = true
+	close(grp.addDone)
+	grp.feedCond.Signal()
+}
+
+// checkAdding panics if adding has been closed
+func (grp *Group) checkAdding() {
+	if grp.addClosed {
+		panic("parallel.Group has closed adding: Cannot call Add after CloseAdd")
+	}
 }
 
 // Run starts each previously added [RunFunc] in a separate goroutine and transitions the
@@ -188,134 +434,643 @@ func (grp *Group) Add(outTag, errTag string, rFunc RunFunc) {
 // immediately with any outstanding RunFuncs started in the background as
 // [LimitActiveRunners] allows.
 func (grp *Group) Run() {
+	grp.RunContext(context.Background())
+}
+
+// RunContext is identical to [Group.Run] except that the Group context, from which the
+// context of every [RunFuncCtx] is derived, is derived from the supplied ctx.
+//
+// If ctx is cancelled, any RunFuncs which have not yet been started, typically because of
+// [LimitActiveRunners] constraints, are skipped and never run. Active RunFuncs are not
+// interrupted, but those added with [Group.AddContext] see their context cancelled. Once
+// all active RunFuncs return, [Group.Wait] returns with an error which includes the
+// context error. Skipped runners have their Errors() entry set to the context error.
+func (grp *Group) RunContext(ctx context.Context) {
+	grp.mu.Lock()
+	defer grp.mu.Unlock()
+
 	grp.checkState(groupIsAdding)
 	grp.state = groupIsRunning
+	grp.ctx, grp.cancel = context.WithCancelCause(ctx)
+	grp.dispatch, grp.stop = context.WithCancelCause(grp.ctx)
+	if grp.cpuFactor > 0 {
+		grp.limitRunners = grp.cpuLimit()
+	}
+	if len(grp.tagColors) > 0 { // Normally only color terminals
+		grp.colorOut = grp.colorMode.enabled(grp.stdout)
+		grp.colorErr = grp.colorMode.enabled(grp.stderr)
+	}
+	if grp.roundRobin {
+		grp.roundRobin = grp.rrMode.enabled(grp.stdout)
+	}
+	if grp.recording != nil {
+		grp.recorder = newRecorder(grp.recording, grp.clock)
+	}
+	if grp.dedupKey != nil {
+		grp.dedupFirst = make(map[string]*runner)
+	}
+	if grp.outputRate > 0 {
+		grp.rateLimit = newRateLimiter(grp.outputRate, grp.clock)
+	}
+	// Before the Group io.Writers are replaced
+	grp.httpFlush = grp.httpWriters()
+	if len(grp.stdoutTee) > 0 || len(grp.stderrTee) > 0 { // After terminal detection
+		grp.teeWriters()
+	}
+	if grp.asyncDepth > 0 { // After terminal detection as it replaces the Group writers
+		grp.asyncWriters()
+	}
+	if grp.bufferSize > 0 { // Buffers wrap any asyncWriters so Flush is cheap
+		grp.bufferWriters()
+	}
+	if grp.orderBy != nil {
+		grp.sortRunners()
+	}
+	if grp.jobLog != nil {
+		grp.writeJobLogHeader()
+	}
+	if grp.config.progress != nil && grp.progressMode.enabled(grp.config.progress) {
+		grp.progress = newProgress(grp.config.progress, grp.clock, &grp.outputMu,
+			len(grp.all))
+		go grp.progress.run()
+	}
+	if grp.autoRunners {
+		grp.auto = newAutoLimiter(int(grp.limitRunners), grp.clock)
+	}
+	if grp.startEvery > 0 {
+		grp.starter = newStartLimiter(grp.startEvery, grp.startBurst, grp.clock)
+	}
+	if len(grp.config.signals) > 0 {
+		grp.signals = newSignalHandler(grp.config.signals)
+		go grp.signals.run(grp)
+	}
+	if len(grp.config.dumpSignals) > 0 {
+		grp.dumper = newDumper(grp.config.dumpSignals)
+		go grp.dumper.run(grp)
+	}
+	if grp.electForeground() {
+		grp.blocked = make(chan struct{}, 1)
+	}
+	if grp.flushInterval > 0 {
+		grp.flusher = newFlushTimer(grp.flushInterval, grp.clock, grp.flushers(),
+			&grp.outputMu)
+		go grp.flusher.run()
+	}
+	if grp.roundRobin {
+		grp.rotor = newRotor(grp.rrSlice, grp.clock)
+		go grp.rotor.run()
+	}
+	if !grp.openEnded {
+		grp.closeAdd()
+	}
 	grp.buildPipelines()
 	grp.startRunners()
 }
 
-func (grp *Group) buildPipelines() {
-	first := true
-	for e := grp.runners.Front(); e != nil; e = e.Next() {
-		rnr := e.Value.(*runner)
+// sortRunners reorders grp.all according to OrderBy. The creation order is retained in
+// grp.added for those methods which report runners by index. Caller must hold grp.mu.
+func (grp *Group) sortRunners() {
+	grp.added = slices.Clone(grp.all)
+	slices.SortStableFunc(grp.all, func(a, b *runner) int {
+		ai, bi := a.info(), b.info()
 		switch {
-		case grp.passthru:
-			rnr.buildPassthruPipeline(grp)
-		case first && grp.foregroundAllowed(): // A max of one runner gets foreground
-			rnr.buildQueuePipeline(grp)
-			rnr.switchToForeground()
-			first = false
-		default: // The default is the queue pipeline
-			rnr.buildQueuePipeline(grp)
+		case grp.orderBy(ai, bi):
+			return -1
+		case grp.orderBy(bi, ai):
+			return 1
 		}
+		return 0
+	})
+}
+
+// runners returns all runners in creation order.
+func (grp *Group) runners() []*runner {
+	if grp.added != nil {
+		return grp.added
+	}
+
+	return grp.all
+}
+
+func (grp *Group) buildPipelines() {
+	for ix, rnr := range grp.all {
+		grp.buildPipeline(rnr, ix == 0)
+	}
+}
+
+// buildPipeline constructs the appropriate pipeline for the runner. If front is true the
+// runner is switched to foreground if config allows.
+func (grp *Group) buildPipeline(rnr *runner, front bool) {
+	switch {
+	case grp.passthru:
+		rnr.buildPassthruPipeline(grp)
+	case grp.ungroup:
+		rnr.buildUngroupPipeline(grp)
+	case grp.roundRobin:
+		rnr.buildRoundRobinPipeline(grp)
+	case grp.collapse:
+		rnr.buildCollapsePipeline(grp)
+	case front && grp.foregroundAllowed(): // A max of one runner gets foreground
+		rnr.buildQueuePipeline(grp)
+		grp.switchToForeground(rnr)
+	default: // The default is the queue pipeline
+		rnr.buildQueuePipeline(grp)
 	}
 }
 
 // startRunners feeds RunFuncs to a pool of [LimitActiveRunners] workers. The flow of each
-// *list.Element (a container for each runner) is:
+// runner is:
 //
-// startRunners() -> todo chan -> worker() -> RunFunc() -> runnerDone chan -> Wait() -> Remove
+// add() -> all -> feeder() -> todo chan -> worker() -> RunFunc() -> runnerDone chan -> Wait() -> remove
 //
-// Thus *list.Elements remains valid until processed by [Group.Wait] and removed from the
-// list. This is important as [container/List.Remove] invalidates *list.Element.
+// Runners are never actually removed from grp.all as it is retained for Errors(), instead
+// they are marked as removed and grp.front is advanced past them. Runners are normally fed
+// in creation order so the feeder merely needs to track the index of the oldest runner not
+// yet fed. A [Scheduler] may feed runners out of order, so each runner is also marked as
+// fed.
 //
 // startRunners is normally called by the same goroutine which ultimately calls
-// [Group.Wait] so it cannot stall, nor can it start goroutines which concurrently
-// reference Group as Group is not concurrency-safe.
+// [Group.Wait] so it cannot stall. The feeder goroutine only accesses grp.all and
+// grp.nextFeed under the protection of grp.mu.
 //
 // To simplify the code path, startRunners is written as if [LimitActiveRunners] is always
-// non-zero even tho it most often will be zero.
+// non-zero even tho it most often will be zero. In that case one worker is started per
+// runner, including those added later to an [OpenEnded] Group.
+//
+// Caller must hold grp.mu.
 func (grp *Group) startRunners() {
 	maxWorkers := grp.limitRunners // How many workers are started?
 	if maxWorkers == 0 {           // If no configured limit, run them all at once
-		maxWorkers = uint(grp.runners.Len())
+		maxWorkers = uint(len(grp.all))
 	}
 
-	todo := make(chan *list.Element) // Feeder writes, workers read
+	grp.todo = make(chan *runner)
 	for ; maxWorkers > 0; maxWorkers-- {
-		go worker(todo, grp.runnerDone)
+		go grp.worker()
 	}
 
-	// Copy runners to a separate container so that the feeder goroutine doesn't need
-	// concurrent access to Group. We cannot clone to another container.List as that
-	// moves the Elements over which in turn causes Elements to lose knowledge of
-	// their original List which would break Wait. Thus a good ol' slice is used as a
-	// container of RunFuncs to start.
-	runners := make([]*list.Element, 0, grp.runners.Len())
-	for e := grp.runners.Front(); e != nil; e = e.Next() {
-		runners = append(runners, e)
+	now := grp.clock.Now()
+	for _, rnr := range grp.all {
+		rnr.queued = now
 	}
 
-	// Start feeder goroutine
-	go func() {
-		for _, e := range runners {
-			todo <- e
+	go grp.feeder()
+}
+
+// feeder passes pending runners to the workers in creation order. It exits once adding is
+// closed and all pending runners have been passed on.
+func (grp *Group) feeder() {
+	for {
+		grp.mu.Lock()
+		rnr := grp.nextRunner()
+		for rnr == nil && !grp.addClosed {
+			grp.feedCond.Wait()
+			rnr = grp.nextRunner()
 		}
-		close(todo)
-	}()
+		if rnr == nil { // Must be closed
+			grp.mu.Unlock()
+			close(grp.todo)
+			return
+		}
+		grp.mu.Unlock()
+
+		if grp.auto != nil {
+			grp.auto.acquire()
+		}
+		grp.todo <- rnr
+	}
 }
 
 // Each worker accepts new work from the todo channel, runs the RunFunc then notifies the
-// completion channel. It exits when the todo channel is closed.
-func worker(todo chan *list.Element, runnerDone chan *list.Element) {
-	for e := range todo {
-		rnr := e.Value.(*runner)
-		rnr.run(e, runnerDone)
+// completion channel. It exits when the todo channel is closed. If the dispatch context
+// has been cancelled, the RunFunc is skipped but completion is still notified so that
+// [Group.Wait] sees every runner.
+//
+// Workers only access Group fields which are immutable once Run has been called, apart
+// from the halt state which has its own mutex.
+func (grp *Group) worker() {
+	for rnr := range grp.todo {
+		if grp.starter != nil {
+			grp.starter.wait(grp.dispatch)
+		}
+		grp.started.Add(1)
+		if grp.progress != nil {
+			grp.progress.started.Add(1)
+		}
+		if grp.scheduler != nil && grp.blocked != nil {
+			grp.notifyBlocked() // Let the Scheduler consider the new runner for foreground
+		}
+		ctx := grp.ctx
+		if grp.tracer != nil {
+			ctx = rnr.startSpan(grp.tracer, ctx)
+		}
+		if grp.dispatch.Err() != nil {
+			rnr.skip(context.Cause(grp.dispatch))
+			grp.debug("skip", rnr, "cause", rnr.err)
+		} else if !grp.inShard(rnr) {
+			rnr.omit()
+			grp.debug("shard", rnr)
+		} else if grp.resumable(rnr) {
+			rnr.omit()
+			grp.debug("resume", rnr)
+		} else if rnr.dedupOrig != nil && !grp.dedupReplay {
+			rnr.skip(nil)
+			grp.debug("dedup", rnr, "original", rnr.dedupOrig.index)
+		} else {
+			if rnr.dedupOrig != nil {
+				rnr.rFunc = replayOriginal(rnr.dedupOrig)
+			}
+			rnr.slot = grp.slots.acquire()
+			grp.debug("dispatch", rnr, "slot", rnr.slot)
+			grp.hooks.start(rnr)
+			if grp.recorder != nil {
+				grp.recorder.start(rnr)
+			}
+			rnr.run(context.WithValue(ctx, slotKey{}, rnr.slot), grp.clock)
+			if grp.ungroup {
+				grp.oweUngroupSeparators()
+			}
+			grp.slots.release(rnr.slot)
+			if grp.recorder != nil {
+				grp.recorder.finish(rnr)
+			}
+			grp.hooks.finish(rnr)
+			grp.debug("complete", rnr, "duration", rnr.duration, "error", rnr.err)
+			grp.checkHalt(rnr)
+		}
+		rnr.finished()
+		if grp.auto != nil {
+			grp.auto.release()
+		}
+		grp.completed.Add(1)
+		if grp.progress != nil {
+			grp.progress.completed.Add(1)
+		}
+		select {
+		case grp.runnerDone <- rnr:
+		case <-grp.detached: // Wait no longer cares about this runner
+		}
 	}
 }
 
 // Wait waits for all RunFuncs started by [Group.Run] to complete before returning. If any
 // RunFunc fails to complete, Wait will never return.
 //
+// The returned error is the aggregate, via [errors.Join], of all non-nil errors returned
+// by RunFuncs added with [Group.AddErr]. If no errors were returned, Wait returns nil. Use
+// [Group.Errors] to determine which runners failed. If buffered output could not be
+// written to a Group io.Writer, the first [*WriteError] is also included.
+//
+// If a Group io.Writer returns a broken pipe error, such as when output is piped to
+// head(1), pending RunFuncs are skipped, the context of active RunFuncs is cancelled and
+// the returned error includes [ErrBrokenPipe].
+//
 // While [Group.Run] starts all RunFuncs, it is Wait which progresses RunFuncs and
 // transitions them from background mode to foreground mode to completion, so it's
 // important that the caller not presume that RunFuncs will complete prior to calling
 // Wait, because they wont. If callers want to perform other activities between calls to
 // [Group.Run] and Wait, they may want to consider running Wait in a separate goroutine
-// which notifies them when Wait returns.
-func (grp *Group) Wait() {
-	grp.checkState(groupIsRunning)
-	grp.state = groupIsWaiting
+// which notifies them when Wait returns, or use [Group.Results] or [Group.Done] in place
+// of Wait.
+//
+// For an [OpenEnded] Group, Wait does not return until [Group.CloseAdd] has been called
+// and all runners have completed.
+func (grp *Group) Wait() error {
+	grp.mu.Lock()
+	background := grp.bgWait
+	grp.mu.Unlock()
+	if background { // Done has already started waiting
+		<-grp.done
+		return grp.waitErr
+	}
+
+	return grp.wait()
+}
+
+// Done returns a channel which is closed once all RunFuncs have completed and their output
+// has been written to the Group io.Writers. This allows callers to select on Group
+// completion alongside signals, timers and other channels rather than blocking in
+// [Group.Wait].
+//
+// If Done is called after [Group.Run] and before Wait, the work normally performed by
+// Wait is started in the background. Wait can still be called after Done is closed to
+// obtain the error which Wait would otherwise have returned.
+func (grp *Group) Done() <-chan struct{} {
+	grp.mu.Lock()
+	defer grp.mu.Unlock()
+
+	if grp.state == groupIsRunning && !grp.bgWait {
+		grp.bgWait = true
+		go grp.wait()
+	}
+
+	return grp.done
+}
+
+// WaitN returns once n runners have completed and their output has been written to the
+// Group io.Writers, or once all runners have completed if there are fewer than n. The
+// remaining RunFuncs continue in the background. This suits programs which want to show
+// the first few results immediately and finish the rest later:
+//
+//	group.Run()
+//	group.WaitN(3)
+//	fmt.Println("First three done")
+//	err := group.Wait()
+//
+// As WaitN starts the work normally performed by Wait in the background, as described in
+// [Group.Done], [Group.Wait] or [Group.Done] must still be called to wait for the
+// remaining RunFuncs and to obtain the Group error. With [OrderRunners] set, the first n
+// runners to complete are the first n added.
+func (grp *Group) WaitN(n int) {
+	grp.mu.Lock()
+	if grp.state == groupIsAdding {
+		grp.checkState(groupIsRunning) // Panics
+	}
+	grp.mu.Unlock()
+
+	grp.Done() // Start waiting in the background if need be
+
+	grp.mu.Lock()
+	defer grp.mu.Unlock()
+	for len(grp.all)-grp.live < n && grp.state != groupIsDone {
+		grp.emitCond.Wait()
+	}
+}
+
+// WaitContext is identical to [Group.Wait] except that it also returns if ctx is done
+// before all RunFuncs have completed. In that case the returned error is an
+// [*UnfinishedError] which wraps the context error and lists the runners whose output has
+// not yet been written. The Group continues in the background, so WaitContext or Wait can
+// be called again to wait for the remaining RunFuncs. WaitContext is useful for servers
+// and tests which need to bound how long they block on misbehaving RunFuncs.
+//
+// If [WithDetach] is set the unfinished runners are detached rather than left to continue,
+// so the Group is done once WaitContext returns.
+func (grp *Group) WaitContext(ctx context.Context) error {
+	grp.mu.Lock()
+	if !grp.bgWait {
+		grp.checkState(groupIsRunning) // Panics
+	}
+	grp.mu.Unlock()
+
+	done := grp.Done()
+	select {
+	case <-done:
+		return grp.waitErr
+	case <-ctx.Done():
+	}
+
+	grp.mu.Lock()
+	defer grp.mu.Unlock()
+	select {
+	case <-done: // Completion may have raced with ctx
+		return grp.waitErr
+	default:
+	}
+	ue := &UnfinishedError{Err: context.Cause(ctx)}
+	for ix := grp.front; ix < len(grp.all); ix++ {
+		if rnr := grp.all[ix]; !rnr.removed { // Err is not stable so leave it out
+			ue.Runners = append(ue.Runners, RunnerInfo{Index: rnr.index,
+				OutTag: string(rnr.outTag), ErrTag: string(rnr.errTag)})
+		}
+	}
+	if grp.detachOn {
+		grp.detach()
+		grp.mu.Unlock()
+		<-done
+		grp.mu.Lock()
+	}
+
+	return ue
+}
+
+// Cancel aborts a running Group. Pending RunFuncs are skipped, the context passed to
+// active RunFuncs is cancelled and [Group.Wait] returns as soon as the active RunFuncs
+// return, with an error which includes [ErrCanceled]. Output from completed RunFuncs is
+// still written in the usual way. If [WithDetach] is set, unfinished RunFuncs are also
+// detached so Wait returns without waiting for them. An [OpenEnded] Group still requires
+// [Group.CloseAdd] for Wait to return, but any runners subsequently added are skipped.
+//
+// Cancel can be called from any goroutine once [Group.Run] has been called and is a no-op
+// once the Group is done. Only RunFuncs which accept a context, such as those added with
+// [Group.AddContext], can observe the cancellation of active RunFuncs.
+func (grp *Group) Cancel() {
+	grp.mu.Lock()
+	defer grp.mu.Unlock()
+	if grp.state == groupIsDone {
+		return
+	}
+	if grp.state != groupIsWaiting {
+		grp.checkState(groupIsRunning) // Panics
+	}
+	grp.canceled = ErrCanceled
+	grp.stop(ErrCanceled)
+	grp.cancel(ErrCanceled)
+	if grp.detachOn {
+		grp.detach()
+	}
+}
+
+// wait implements Wait. The returned error is also saved in waitErr before done is
+// closed.
+func (grp *Group) wait() (err error) {
+	grp.transition(groupIsRunning, groupIsWaiting)
 
 	defer func() {
-		close(grp.runnerDone)
+		if grp.progress != nil {
+			grp.progress.finish()
+		}
+		if grp.signals != nil {
+			grp.signals.finish()
+		}
+		if grp.dumper != nil {
+			grp.dumper.finish()
+		}
+		if grp.rotor != nil {
+			grp.rotor.finish()
+		}
+		if grp.flusher != nil {
+			grp.flusher.finish()
+		}
+		if grp.buffered != nil {
+			e := grp.flushBuffered(nil)
+			if grp.writeErr == nil { // Otherwise e is the same sticky error
+				err = errors.Join(err, e)
+			}
+		}
+		if grp.async != nil {
+			e := grp.async.finish()
+			if !errors.Is(grp.writeErr, e) { // Otherwise already reported
+				err = errors.Join(err, e)
+			}
+		}
+		if grp.tees != nil {
+			err = errors.Join(err, grp.teeErr())
+		}
+		if grp.closeOnFinish {
+			err = errors.Join(err, grp.closeWriters())
+		}
+		if grp.notify != nil {
+			close(grp.notify)
+		}
+		grp.mu.Lock()
+		grp.cancel(nil) // Release any context resources
 		grp.state = groupIsDone
+		grp.waitErr = err
+		close(grp.done)
+		grp.emitCond.Broadcast()
+		grp.mu.Unlock()
 	}()
 
 	// This loop is the core of the package logic. It waits on completed runners and
 	// arranges the order that their output is sent to the Group io.Writers.
 	//
-	// This loop removes completed runners from the grp.runners List until Len() goes
-	// to zero. An inconvenient side-effect of removing an Element is that the Next()
-	// and Prev() values are invalidated thus loop iteration cannot rely on
-	// Element.Next(); instead it relies on List.Front.
-
-	for grp.runners.Len() > 0 { // Iterate until all runners have been removed
-		e := <-grp.runnerDone // Wait for completion
-		rnr := e.Value.(*runner)
+	// This loop removes completed runners until none remain. Removed runners are
+	// skipped over by advancing grp.front so the front runner is always found in
+	// amortised constant time.
+	//
+	// grp.mu is only released while waiting for a completion so that an OpenEnded Group
+	// can have runners added concurrently.
+
+	addDone := grp.addDone
+	stall := newStallDetector(grp.stallAfter, grp.clock)
+	defer stall.stop()
+	grp.mu.Lock()
+	defer grp.mu.Unlock()
+	for grp.live > 0 || !grp.addClosed { // Iterate until all runners have been removed
+		var rnr *runner
+		stalled := false
+		grp.mu.Unlock()
+		select {
+		case rnr = <-grp.runnerDone: // Wait for completion
+		case <-addDone: // Or for CloseAdd to be called
+			addDone = nil
+		case <-grp.blocked: // Or for a runner to block on its memory limit
+		case <-stall.c(): // Or for too long without a completion
+			stalled = true
+		case <-grp.detached: // Or for all unfinished runners to be detached
+		}
+		grp.mu.Lock()
+		if stalled {
+			grp.reportStall(stall)
+			continue
+		}
+		if rnr == nil {
+			grp.elect()
+			continue
+		}
+
+		stall.completed()
+
 		rnr.canClose = true // Mark as eligible for closing by contiguous scanning
 
 		// If OrderRunners(false) then closing and printing occurs as soon as a
-		// runner completes, otherwise it remains a candidate and the runners list
-		// is scanned from the front to close the first contiguous sequence of
-		// eligible runners. The contiguous scan handles OrderRunners(true) when
-		// runners complete in a different order from their creation order - which
-		// one would expect to occur quite a lot.
+		// runner completes (unless deferred by an elected runner), otherwise it
+		// remains a candidate and the runners list is scanned from the front to close
+		// the first contiguous sequence of eligible runners. The contiguous scan
+		// handles OrderRunners(true) when runners complete in a different order from
+		// their creation order - which one would expect to occur quite a lot.
 
 		if !grp.orderRunners { // If any order of completion is ok,
-			grp.closePrintRemove(e) // then close now
+			grp.closeUnordered(rnr) // then close now
 		} else {
 			grp.closePrintRemoveContiguousFront() // Otherwise only eligible contigs
 		}
 
 		// Can the potentially new front RunFunc switch to foreground?
 
-		if grp.runners.Len() > 0 && grp.foregroundAllowed() {
-			e = grp.runners.Front()
-			rnr := e.Value.(*runner)
-			rnr.switchToForeground() // Switch if not already foreground
+		if grp.live > 0 && grp.foregroundAllowed() {
+			grp.switchToForeground(grp.all[grp.front]) // Switch if not already foreground
+		}
+	}
+
+	if grp.collapse { // Only now are all matches known
+		grp.writeCollapsed()
+	}
+
+	// Workers are done with halt
+	return errors.Join(grp.errors(grp.halt.err, grp.signals.err(), grp.canceled,
+		grp.pipeErr(), grp.writeErr, grp.resultErr)...)
+}
+
+// writers returns the distinct Group io.Writers so that a writer supplied as both stdout
+// and stderr is only returned once, if it can be compared.
+func (grp *Group) writers() []io.Writer {
+	if grp.combined ||
+		reflect.TypeOf(grp.stderr).Comparable() && grp.stderr == grp.stdout {
+		return []io.Writer{grp.stdout}
+	}
+
+	return []io.Writer{grp.stdout, grp.stderr}
+}
+
+// closeWriters closes the Group io.Writers for WithCloseOnFinish, returning any errors.
+func (grp *Group) closeWriters() error {
+	writers := grp.writers()
+	if grp.unbuffered != nil {
+		writers = grp.unbuffered
+	}
+	var errs []error
+	for _, w := range writers {
+		if w == os.Stdout || w == os.Stderr {
+			continue
+		}
+		if c, ok := w.(io.Closer); ok {
+			errs = append(errs, c.Close())
 		}
 	}
+
+	return errors.Join(errs...)
+}
+
+// Errors returns the error recorded for each runner in the order in which they were
+// added to the Group. Runners which succeeded, or which were not added with an
+// error-returning variant such as [Group.AddErr], have a nil entry. Errors can only be
+// called after [Group.Wait] has returned.
+func (grp *Group) Errors() []error {
+	grp.checkState(groupIsDone)
+	errs := make([]error, 0, len(grp.all))
+	for _, rnr := range grp.runners() {
+		if rnr.detached { // Detached runners may still be setting err
+			errs = append(errs, ErrDetached)
+			continue
+		}
+		errs = append(errs, rnr.err)
+	}
+
+	return errs
+}
+
+// errors returns just the non-nil runner errors in creation order. Skipped runners
+// generally share the same error, such as a context error or [ErrHalted], so each
+// distinct skip error is only returned once, after all other errors, rather than
+// repeated for every skipped runner. Any non-nil extra errors are treated the same as
+// skip errors.
+func (grp *Group) errors(extra ...error) (errs []error) {
+	var skipErrs []error
+	for _, rnr := range grp.runners() {
+		if rnr.detached { // Detached runners may still be setting err
+			extra = append(extra, ErrDetached)
+			continue
+		}
+		if rnr.err == nil {
+			continue
+		}
+		if rnr.skipped {
+			extra = append(extra, rnr.err)
+			continue
+		}
+		errs = append(errs, rnr.err)
+	}
+
+	for _, e := range extra {
+		if e == nil || slices.Contains(skipErrs, e) {
+			continue
+		}
+		skipErrs = append(skipErrs, e)
+	}
+
+	return append(errs, skipErrs...)
 }
 
 // Close and print all runners at the front of the list which have canClose set. This
@@ -323,37 +1078,125 @@ func (grp *Group) Wait() {
 // list to finish first. If OrderedRunners(true) then the output of that runner must be
 // deferred until they are at the front.
 //
-// This function potentially removes runners by way of closePrintRemove() so the
-// encapsulating for loop cannot rely on Element.Next()
+// closePrintRemove() advances grp.front past each removed runner so each iteration
+// examines the new front runner.
 func (grp *Group) closePrintRemoveContiguousFront() {
-	nextE := grp.runners.Front()
-	for e := nextE; e != nil; e = nextE {
-		nextE = e.Next()
-		rnr := e.Value.(*runner)
+	for grp.live > 0 {
+		rnr := grp.all[grp.front]
 		if rnr.canClose != true { // Stop at first incomplete
 			return
 		}
-		grp.closePrintRemove(e)
+		grp.closePrintRemove(rnr)
 	}
 }
 
 // Finish up a runner that is ready to be printed, including any separators. Remove the
-// runner from the Group list. Caller must be aware that *list.Element.Next() is invalid
-// on return.
-func (grp *Group) closePrintRemove(e *list.Element) {
-	rnr := e.Value.(*runner)
-	grp.runners.Remove(e)
+// runner from the Group by marking it as removed and advancing grp.front past all
+// contiguous removed runners.
+func (grp *Group) closePrintRemove(rnr *runner) {
+	rnr.removed = true
+	grp.live--
+	for grp.front < len(grp.all) && grp.all[grp.front].removed {
+		grp.front++
+	}
 	rnr.close()
+	// Deferred first so that notification follows the buffered and HTTP flushes
+	defer grp.notifyFlushed(rnr)
+	if grp.httpFlush != nil { // After the buffered flush
+		defer grp.flushRunnerHTTP()
+	}
+	if grp.buffered != nil { // After footers and separators
+		defer grp.flushBuffered(rnr)
+	}
+	if err := rnr.drainErr(); err != nil {
+		grp.debug("drain error", rnr, "error", err)
+		grp.recordWriteErr(rnr, err)
+	}
+	grp.debug("flush", rnr)
+	grp.hooks.flush(rnr)
+	rnr.endSpan()
+	if grp.emitted != nil {
+		grp.emitted = append(grp.emitted, rnr)
+	}
+	grp.emitCond.Broadcast() // For Results and WaitN
+	if grp.jobLog != nil && !rnr.omitted {
+		grp.writeJobLog(rnr)
+	}
+	grp.writeResults(rnr)
 
-	// Close and flush all writers
-	if grp.runners.Len() > 0 { // If not the last runner, consider separators
-		if len(grp.outSep) > 0 {
-			grp.stdout.Write([]byte(grp.outSep))
-		}
-		if len(grp.errSep) > 0 {
-			grp.stderr.Write([]byte(grp.errSep))
-		}
+	// Close and flush all writers. Skipped runners have no output so they don't
+	// warrant footers or separators either.
+	if rnr.skipped {
+		return
 	}
+	grp.outputMu.Lock() // Ungroup runners may be writing concurrently
+	defer grp.outputMu.Unlock()
+	if grp.ungroup { // Separators are owed by the worker - see oweUngroupSeparators
+		grp.writeFooter(rnr)
+		return
+	}
+	grp.paySeparators() // In case rnr wrote no output
+	grp.writeFooter(rnr)
+	grp.oweSeparators()
+}
+
+// writeFooter writes the WithRunnerFooter footer, if any, to stdout. Caller must hold
+// grp.outputMu.
+func (grp *Group) writeFooter(rnr *runner) {
+	if grp.footer == nil {
+		return
+	}
+	footer := grp.footer(rnr.flushedInfo())
+	if len(footer) == 0 {
+		return
+	}
+	grp.stdout.Write([]byte(footer))
+}
+
+// oweSeparators records that separators are owed by a runner which has run. Rather than
+// being written immediately, they are paid by the tail prior to the next output of any
+// runner, or when the next runner to run is flushed. Thus separators only ever appear
+// between the output of runners which actually ran, and never trail the output of the
+// last one, regardless of whether subsequent runners are skipped by [WithHalt],
+// [WithShard], [WithResume] and similar, or whether more runners are yet to be added to an
+// [OpenEnded] Group. Caller must hold grp.outputMu.
+func (grp *Group) oweSeparators() {
+	grp.sepOwed = len(grp.outSep) > 0 || len(grp.errSep) > 0
+}
+
+// paySeparators writes any separators owed from a previous runner. Caller must hold
+// grp.outputMu.
+func (grp *Group) paySeparators() {
+	if !grp.sepOwed {
+		return
+	}
+	grp.sepOwed = false
+	if len(grp.outSep) > 0 {
+		grp.stdout.Write([]byte(grp.outSep))
+	}
+	if len(grp.errSep) > 0 {
+		grp.stderr.Write([]byte(grp.errSep))
+	}
+}
+
+// oweUngroupSeparators is called by the worker once an Ungroup runner has run. Ungroup
+// output is written as soon as it is produced, so waiting for Wait to flush the runner
+// would place the separators after any output other runners write in the meantime.
+func (grp *Group) oweUngroupSeparators() {
+	grp.outputMu.Lock()
+	defer grp.outputMu.Unlock()
+	grp.paySeparators() // In case the runner wrote no output
+	grp.oweSeparators()
+}
+
+// transition checks that the Group is in the "from" state then sets it to the "to"
+// state. It panics if the Group is not in the "from" state.
+func (grp *Group) transition(from, to groupState) {
+	grp.mu.Lock()
+	defer grp.mu.Unlock()
+
+	grp.checkState(from)
+	grp.state = to
 }
 
 // Check that the Group is in the expected state for the caller. If not, panic.
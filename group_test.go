@@ -2,7 +2,12 @@ package parallel
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -212,6 +217,28 @@ func TestGroupPassthru(t *testing.T) {
 	}
 }
 
+func TestGroupPassthruTags(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), Passthru(true),
+		OrderRunners(false))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("o: ", "e: ", func(out, err io.Writer) {
+		out.Write([]byte("one\ntwo\n"))
+		err.Write([]byte("oops\n"))
+	})
+	grp.Run()
+	grp.Wait()
+
+	if stdout.String() != "o: one\no: two\n" {
+		t.Errorf("Passthru stdout not tagged %q", stdout.String())
+	}
+	if stderr.String() != "e: oops\n" {
+		t.Errorf("Passthru stderr not tagged %q", stderr.String())
+	}
+}
+
 // Seps and tags while we're at it
 func TestGroupSeparators(t *testing.T) {
 	var stdout, stderr bytes.Buffer
@@ -352,3 +379,677 @@ func TestGroupErrors(t *testing.T) {
 		t.Error("Expected error return from WithStderr(nil)")
 	}
 }
+
+// Test that AddContext supplies a live context which is cancelled once the RunFuncCtx
+// returns.
+func TestGroupAddContext(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+
+	var saved context.Context
+	var wasLive bool
+	grp.AddContext("", "", func(ctx context.Context, out, err io.Writer) {
+		saved = ctx
+		wasLive = ctx.Err() == nil
+		out.Write([]byte("ctx runner\n"))
+	})
+	grp.Add("", "", func(out, err io.Writer) {
+		out.Write([]byte("plain runner\n"))
+	})
+
+	grp.Run()
+	grp.Wait()
+
+	if saved == nil {
+		t.Fatal("RunFuncCtx was not supplied a context")
+	}
+	if !wasLive {
+		t.Error("RunFuncCtx context should not be cancelled while running")
+	}
+	if saved.Err() == nil {
+		t.Error("RunFuncCtx context should be cancelled after Wait")
+	}
+
+	actual := stdout.String()
+	expect := "ctx runner\nplain runner\n"
+	if actual != expect {
+		t.Error("AddContext output mismatch.\nExpect:\n", expect, "\nActual\n", actual)
+	}
+}
+
+// Test that RunFuncErr errors are recorded in add order and aggregated by Wait.
+func TestGroupAddErr(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+
+	e1 := errors.New("runner one failed")
+	e3 := errors.New("runner three failed")
+	grp.AddErr("", "", func(out, err io.Writer) error { return e1 })
+	grp.Add("", "", func(out, err io.Writer) {})
+	grp.AddErr("", "", func(out, err io.Writer) error { return e3 })
+	grp.AddErr("", "", func(out, err io.Writer) error { return nil })
+
+	grp.Run()
+	err = grp.Wait()
+	if err == nil {
+		t.Fatal("Expected an aggregate error from Wait")
+	}
+	if !errors.Is(err, e1) || !errors.Is(err, e3) {
+		t.Error("Wait error should contain both runner errors, not", err)
+	}
+
+	errs := grp.Errors()
+	if len(errs) != 4 {
+		t.Fatal("Expected four Errors() entries, not", len(errs))
+	}
+	if errs[0] != e1 || errs[1] != nil || errs[2] != e3 || errs[3] != nil {
+		t.Error("Errors() not in add order", errs)
+	}
+
+	// A Group with no errors should return nil
+	grp, _ = NewGroup(WithStdout(&stdout), WithStderr(&stderr))
+	grp.AddErr("", "", func(out, err io.Writer) error { return nil })
+	grp.Run()
+	err = grp.Wait()
+	if err != nil {
+		t.Error("Did not expect an error from Wait", err)
+	}
+}
+
+func TestGroupAddArg(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	echo := func(arg string, out, err io.Writer) {
+		fmt.Fprintln(out, "out", arg)
+		fmt.Fprintln(err, "err", arg)
+	}
+	for _, arg := range []string{"a", "b"} {
+		grp.AddArg(arg, echo)
+	}
+	grp.AddArg("c", echo, RunnerTagSuffix(": "))
+	grp.Run()
+	grp.Wait()
+
+	if got := stdout.String(); got != "a\tout a\nb\tout b\nc: out c\n" {
+		t.Errorf("Wrong stdout %q", got)
+	}
+	if got := stderr.String(); got != "a\terr a\nb\terr b\nc: err c\n" {
+		t.Errorf("Wrong stderr %q", got)
+	}
+}
+
+func TestGroupTagSuffix(t *testing.T) {
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithTagSuffix("|"))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	echo := func(arg string, out, err io.Writer) { fmt.Fprintln(out, arg) }
+	grp.Add("a", "", func(out, err io.Writer) { fmt.Fprintln(out, "a") })
+	grp.Add("", "", func(out, err io.Writer) { fmt.Fprintln(out, "untagged") })
+	grp.AddArg("b", echo)
+	grp.AddArg("c", echo, RunnerTagSuffix(" "))
+	grp.Run()
+	grp.Wait()
+
+	if got := stdout.String(); got != "a|a\nuntagged\nb|b\nc c\n" {
+		t.Errorf("Wrong stdout %q", got)
+	}
+}
+
+func TestGroupWaitN(t *testing.T) {
+	var stdout testLockedBuffer
+	grp, err := NewGroup(WithStdout(&stdout))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	for ix := range 4 {
+		grp.Add("", "", func(out, err io.Writer) {
+			if ix == 3 {
+				<-release
+			}
+			fmt.Fprintln(out, ix)
+		})
+	}
+	grp.Run()
+	grp.WaitN(2)
+	if got := stdout.String(); !strings.HasPrefix(got, "0\n1\n") {
+		t.Errorf("First two runners not flushed %q", got)
+	}
+	select {
+	case <-grp.Done():
+		t.Error("Group should not be done")
+	default:
+	}
+
+	close(release)
+	grp.WaitN(10) // More than there are runners
+	if err := grp.Wait(); err != nil {
+		t.Error("Unexpected error", err)
+	}
+	if got := stdout.String(); got != "0\n1\n2\n3\n" {
+		t.Errorf("Wrong output %q", got)
+	}
+}
+
+// Test that cancelling the RunContext context skips runners not yet started.
+func TestGroupRunContext(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr),
+		LimitActiveRunners(1), WithStdoutSeparator("--\n"))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ran atomic.Int32
+	grp.AddContext("", "", func(rctx context.Context, out, err io.Writer) {
+		ran.Add(1)
+		out.Write([]byte("first\n"))
+		cancel()
+		<-rctx.Done() // Active runner contexts should also be cancelled
+	})
+	for ix := 0; ix < 4; ix++ {
+		grp.Add("", "", func(out, err io.Writer) {
+			ran.Add(1)
+			out.Write([]byte("should not run\n"))
+		})
+	}
+
+	grp.RunContext(ctx)
+	err = grp.Wait()
+	if !errors.Is(err, context.Canceled) {
+		t.Error("Expected Wait to return context.Canceled, not", err)
+	}
+	if ran.Load() != 1 {
+		t.Error("Only the first runner should have run, not", ran.Load())
+	}
+	actual := stdout.String()
+	expect := "first\n"
+	if actual != expect {
+		t.Error("Skipped runners should produce no output.\nExpect:\n", expect,
+			"\nActual\n", actual)
+	}
+
+	errs := grp.Errors()
+	if errs[0] != nil {
+		t.Error("First runner should have no error", errs[0])
+	}
+	for ix := 1; ix < len(errs); ix++ {
+		if !errors.Is(errs[ix], context.Canceled) {
+			t.Error(ix, "Skipped runner should have context.Canceled, not", errs[ix])
+		}
+	}
+}
+
+// Test that an OpenEnded Group accepts Add calls after Run, including from within a
+// RunFunc and concurrently with Wait, and that Wait only returns after CloseAdd.
+func TestGroupOpenEnded(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), OpenEnded(true),
+		LimitActiveRunners(2), WithStdoutSeparator("--\n"))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+
+	grp.Add("", "", func(out, err io.Writer) {
+		out.Write([]byte("one\n"))
+		grp.Add("", "", func(out, err io.Writer) { // Add from within a RunFunc
+			out.Write([]byte("two\n"))
+		})
+	})
+	grp.Run()
+
+	waitDone := make(chan error)
+	go func() {
+		waitDone <- grp.Wait()
+	}()
+
+	time.Sleep(time.Millisecond * 100) // Let Wait drain the list
+	grp.Add("", "", func(out, err io.Writer) {
+		out.Write([]byte("three\n"))
+	})
+
+	select {
+	case <-waitDone:
+		t.Fatal("Wait returned before CloseAdd")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	grp.CloseAdd()
+	grp.CloseAdd() // Should be idempotent
+	select {
+	case err = <-waitDone:
+		if err != nil {
+			t.Error("Unexpected Wait error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return after CloseAdd")
+	}
+
+	actual := stdout.String()
+	expect := "one\n--\ntwo\n--\nthree\n"
+	if actual != expect {
+		t.Error("OpenEnded output mismatch.\nExpect:\n", expect, "\nActual\n", actual)
+	}
+
+	didPanic := func() (didPanic bool) {
+		defer func() { didPanic = recover() != nil }()
+		grp.Add("", "", func(out, err io.Writer) {})
+		return
+	}()
+	if !didPanic {
+		t.Error("Add after CloseAdd should panic")
+	}
+}
+
+// Test that a Group which is not OpenEnded still panics on Add after Run.
+func TestGroupAddAfterRun(t *testing.T) {
+	grp, err := NewGroup()
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Run()
+	didPanic := func() (didPanic bool) {
+		defer func() { didPanic = recover() != nil }()
+		grp.Add("", "", func(out, err io.Writer) {})
+		return
+	}()
+	if !didPanic {
+		t.Error("Add after Run should panic when not OpenEnded")
+	}
+	grp.Wait()
+}
+
+// Test that Ungroup writes immediately with tags and separators applied.
+func TestGroupUngroup(t *testing.T) {
+	var buf bytes.Buffer
+	step1 := make(chan struct{})
+	step2 := make(chan struct{})
+	finish := func(info RunnerInfo) { // Runner "2: " has returned
+		if info.Index == 1 {
+			close(step2)
+		}
+	}
+	grp, err := NewGroup(WithStdout(&buf), WithStderr(&buf), Ungroup(true),
+		OrderRunners(false), WithStdoutSeparator("--\n"),
+		WithHooks(Hooks{OnFinish: finish}))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+
+	grp.Add("1: ", "1e: ", func(out, err io.Writer) {
+		out.Write([]byte("a\nb\n"))
+		close(step1)
+		<-step2
+		err.Write([]byte("c\n"))
+	})
+	grp.Add("2: ", "2e: ", func(out, err io.Writer) {
+		<-step1
+		out.Write([]byte("x\n"))
+	})
+
+	grp.Run()
+	grp.Wait()
+
+	actual := buf.String()
+	expect := "1: a\n1: b\n2: x\n--\n1e: c\n"
+	if actual != expect {
+		t.Error("Ungroup output mismatch.\nExpect:\n", expect, "\nActual\n", actual)
+	}
+
+	for _, opt := range []Option{OrderRunners(true), OrderStderr(true), Passthru(true),
+		LimitMemoryPerRunner(10)} {
+		_, err = NewGroup(Ungroup(true), OrderRunners(false), LimitActiveRunners(1), opt)
+		if err == nil {
+			t.Error("Expected conflict error with Ungroup(true)")
+		}
+	}
+}
+
+// Test that MergeStderr preserves relative ordering in a single stream.
+func TestGroupMergeStderr(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), MergeStderr(true))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	for _, tag := range []string{"a: ", "b: "} {
+		grp.Add(tag, "ignored: ", func(out, err io.Writer) {
+			out.Write([]byte("1\n"))
+			err.Write([]byte("2\n"))
+			out.Write([]byte("3\n"))
+		})
+	}
+	grp.Run()
+	grp.Wait()
+
+	expect := "a: 1\na: 2\na: 3\nb: 1\nb: 2\nb: 3\n"
+	if stdout.String() != expect || stderr.Len() != 0 {
+		t.Errorf("MergeStderr output wrong %q %q", stdout.String(), stderr.String())
+	}
+
+	_, err = NewGroup(MergeStderr(true), OrderStderr(true))
+	if err == nil {
+		t.Error("Expected conflict between MergeStderr and OrderStderr")
+	}
+}
+
+// Test that WithCombinedOutput preserves cross-stream ordering with a single writer.
+func TestGroupCombinedOutput(t *testing.T) {
+	var combined bytes.Buffer
+	grp, err := NewGroup(WithCombinedOutput(&combined))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	for ix := 0; ix < 2; ix++ {
+		grp.Add("o: ", "e: ", func(out, err io.Writer) {
+			out.Write([]byte("1\n"))
+			err.Write([]byte("2\n"))
+			out.Write([]byte("3\n"))
+		})
+	}
+	grp.Run()
+	grp.Wait()
+
+	expect := "o: 1\ne: 2\no: 3\no: 1\ne: 2\no: 3\n"
+	if combined.String() != expect {
+		t.Errorf("Combined output wrong %q", combined.String())
+	}
+
+	for _, opts := range [][]Option{
+		{WithCombinedOutput(nil)},
+		{WithCombinedOutput(&combined), OrderStderr(true)},
+		{WithCombinedOutput(&combined), WithStdout(&combined)},
+		{WithCombinedOutput(&combined), WithStderr(&combined)},
+	} {
+		_, err = NewGroup(opts...)
+		if err == nil {
+			t.Error("Expected error from WithCombinedOutput conflicts")
+		}
+	}
+}
+
+func TestGroupRunnerFooter(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	footer := func(info RunnerInfo) string {
+		if info.Index == 1 {
+			return "" // Empty footers are not written
+		}
+		return info.OutTag + strconv.Itoa(int(info.Stdout)) + " " +
+			strconv.Itoa(int(info.Stderr)) + " " + strconv.FormatBool(info.Err != nil) + "\n"
+	}
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr),
+		WithStdoutSeparator("--\n"), WithRunnerFooter(footer))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("a: ", "", func(out, err io.Writer) { out.Write([]byte("one\n")) })
+	grp.Add("b: ", "", func(out, err io.Writer) { out.Write([]byte("two\n")) })
+	grp.AddErr("c: ", "", func(out, err io.Writer) error {
+		err.Write([]byte("oops\n"))
+		return errors.New("failed")
+	})
+	grp.Run()
+	grp.Wait()
+
+	expect := "a: one\na: 4 0 false\n--\nb: two\n--\nc: 0 5 true\n"
+	if stdout.String() != expect {
+		t.Errorf("Footer output wrong %q", stdout.String())
+	}
+
+	for _, opts := range [][]Option{
+		{WithRunnerFooter(footer), WithJSONOutput(true)},
+		{WithRunnerFooter(footer), WithFramedOutput(true)},
+		{WithRunnerFooter(footer), WithCompression(1)},
+	} {
+		_, err = NewGroup(opts...)
+		if err == nil {
+			t.Error("Expected error from WithRunnerFooter conflicts")
+		}
+	}
+}
+
+func TestGroupDone(t *testing.T) {
+	grp, err := NewGroup(WithStdout(io.Discard))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	failed := errors.New("failed")
+	release := make(chan struct{})
+	grp.AddErr("", "", func(out, err io.Writer) error {
+		<-release
+		return failed
+	})
+	grp.Run()
+
+	done := grp.Done()
+	select {
+	case <-done:
+		t.Fatal("Done closed before runner completed")
+	default:
+	}
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Done not closed after runner completed")
+	}
+	if grp.Done() != done {
+		t.Error("Done should always return the same channel")
+	}
+	if err = grp.Wait(); !errors.Is(err, failed) {
+		t.Error("Expected Wait to return runner error after Done, got", err)
+	}
+
+	// Done is also closed by a regular Wait
+	grp, _ = NewGroup()
+	grp.Run()
+	grp.Wait()
+	select {
+	case <-grp.Done():
+	default:
+		t.Error("Done not closed after Wait")
+	}
+}
+
+func TestGroupWaitContext(t *testing.T) {
+	grp, err := NewGroup(WithStdout(io.Discard))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	grp.Add("quick", "", func(out, err io.Writer) {})
+	grp.Add("slow", "", func(out, err io.Writer) { <-release })
+	grp.Run()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = grp.WaitContext(ctx)
+	var ue *UnfinishedError
+	if !errors.As(err, &ue) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("Expected UnfinishedError wrapping DeadlineExceeded, got", err)
+	}
+	if len(ue.Runners) != 1 || ue.Runners[0].Index != 1 || ue.Runners[0].OutTag != "slow" {
+		t.Error("Wrong unfinished runners", ue.Runners)
+	}
+
+	close(release)
+	if err = grp.WaitContext(context.Background()); err != nil {
+		t.Error("Unexpected error from second WaitContext", err)
+	}
+	if err = grp.Wait(); err != nil {
+		t.Error("Unexpected error from Wait after WaitContext", err)
+	}
+}
+
+func TestGroupCancel(t *testing.T) {
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), LimitActiveRunners(2))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	quick := make(chan struct{})
+	started := make(chan struct{})
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("quick\n")); close(quick) })
+	grp.AddContextErr("", "", func(ctx context.Context, out, err io.Writer) error {
+		close(started)
+		<-ctx.Done()
+		return context.Cause(ctx)
+	})
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("never\n")) })
+	grp.Run()
+	<-quick
+	<-started
+	grp.Cancel()
+	err = grp.Wait()
+	if !errors.Is(err, ErrCanceled) {
+		t.Error("Expected ErrCanceled from Wait, got", err)
+	}
+	errs := grp.Errors()
+	if errs[0] != nil || errs[1] != ErrCanceled || errs[2] != ErrCanceled {
+		t.Error("Wrong runner errors", errs)
+	}
+	if res := grp.RunnerResult(2); res.Outcome != Skipped {
+		t.Error("Pending runner should have been skipped", res)
+	}
+	if got := stdout.String(); got != "quick\n" {
+		t.Error("Wrong output", got)
+	}
+	grp.Cancel() // No-op once done
+}
+
+func TestGroupOrderBy(t *testing.T) {
+	var stdout bytes.Buffer
+	byTag := func(i, j RunnerInfo) bool { return i.OutTag < j.OutTag }
+	grp, err := NewGroup(WithStdout(&stdout), OrderBy(byTag))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	for _, tag := range []string{"c ", "a ", "b "} {
+		grp.AddErr(tag, "", func(out, err io.Writer) error {
+			out.Write([]byte("line\n"))
+			return errors.New(tag)
+		})
+	}
+	grp.Run()
+	grp.Wait()
+	if got := stdout.String(); got != "a line\nb line\nc line\n" {
+		t.Error("Output not in OrderBy order", got)
+	}
+	errs := grp.Errors() // Still in order of addition
+	if errs[0].Error() != "c " || errs[1].Error() != "a " || errs[2].Error() != "b " {
+		t.Error("Errors not in order of addition", errs)
+	}
+	if res := grp.RunnerResult(0); res.OutTag != "c " {
+		t.Error("RunnerResult not in order of addition", res)
+	}
+
+	_, err = NewGroup(OrderBy(byTag), OrderRunners(false))
+	if err == nil || !strings.Contains(err.Error(), "OrderBy with OrderRunners(false)") {
+		t.Error("Expected OrderRunners(false) conflict, got", err)
+	}
+	_, err = NewGroup(OrderBy(byTag), OpenEnded(true))
+	if err == nil || !strings.Contains(err.Error(), "OrderBy with OpenEnded(true)") {
+		t.Error("Expected OpenEnded conflict, got", err)
+	}
+	_, err = NewGroup(OrderBy(nil))
+	if err == nil {
+		t.Error("Expected error for nil OrderBy function")
+	}
+}
+
+type testCloser struct {
+	bytes.Buffer
+	closed int
+	err    error
+}
+
+func (tc *testCloser) Close() error {
+	tc.closed++
+	return tc.err
+}
+
+func TestGroupCloseOnFinish(t *testing.T) {
+	closeErr := errors.New("close failed")
+	stdout, stderr := &testCloser{}, &testCloser{err: closeErr}
+	grp, err := NewGroup(WithStdout(stdout), WithStderr(stderr), WithCloseOnFinish(true))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("one\n")) })
+	grp.Run()
+	err = grp.Wait()
+	if !errors.Is(err, closeErr) {
+		t.Error("Expected Close error from Wait, got", err)
+	}
+	if stdout.closed != 1 || stderr.closed != 1 {
+		t.Error("Expected writers to be closed once", stdout.closed, stderr.closed)
+	}
+	if stdout.String() != "one\n" {
+		t.Error("Wrong stdout", stdout.String())
+	}
+
+	shared := &testCloser{}
+	grp, err = NewGroup(WithStdout(shared), WithStderr(shared), WithCloseOnFinish(true))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Run()
+	if err = grp.Wait(); err != nil || shared.closed != 1 {
+		t.Error("Shared writer should be closed once", err, shared.closed)
+	}
+
+	other := &testCloser{}
+	grp, err = NewGroup(WithStdout(other))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Run()
+	grp.Wait()
+	if other.closed != 0 {
+		t.Error("Writer should not be closed by default")
+	}
+}
+
+// Compressed buffering lets a background runner buffer far more than its memory limit.
+func TestGroupCompressedBuffering(t *testing.T) {
+	var stdout bytes.Buffer
+	var blocked atomic.Bool
+	grp, err := NewGroup(WithStdout(&stdout), LimitActiveRunners(2),
+		LimitMemoryPerRunner(8192), WithCompressedBuffering(true),
+		WithOnBlocked(func(RunnerInfo, uint64) { blocked.Store(true) }))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	line := strings.Repeat("x", 99) + "\n"
+	grp.Add("", "", func(out, err io.Writer) { <-release })
+	grp.Add("", "", func(out, err io.Writer) {
+		for range 1000 { // 100KB in background
+			out.Write([]byte(line))
+		}
+		close(release)
+	})
+	grp.Run()
+	grp.Wait()
+
+	if blocked.Load() {
+		t.Error("Compressed output should not have reached the memory limit")
+	}
+	if stdout.String() != strings.Repeat(line, 1000) {
+		t.Error("Compressed output corrupted")
+	}
+}
@@ -0,0 +1,152 @@
+package parallel
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type haltWhen int
+
+const (
+	haltNever haltWhen = iota
+	haltSoon           // Stop dispatching new runners
+	haltNow            // As for haltSoon and also cancel active runners
+)
+
+type haltOn int
+
+const (
+	haltOnFail haltOn = iota
+	haltOnSuccess
+	haltOnDone
+)
+
+// haltPolicy is the parsed form of the WithHalt policy string.
+type haltPolicy struct {
+	when    haltWhen
+	on      haltOn
+	count   int     // Halt once this many runners match, or
+	percent float64 // halt once this percentage of all runners match if non-zero
+}
+
+// parseHaltPolicy parses a GNU parallel style “--halt” policy such as "now,fail=1",
+// "soon,fail=30%", "now,success=1", "soon,done=10" or "never".
+func parseHaltPolicy(policy string) (*haltPolicy, error) {
+	if policy == "never" {
+		return &haltPolicy{when: haltNever}, nil
+	}
+
+	when, cond, found := strings.Cut(policy, ",")
+	if !found {
+		return nil, errors.New("WithHalt policy must be 'never' or 'when,condition=value': " +
+			policy)
+	}
+
+	hp := &haltPolicy{}
+	switch when {
+	case "soon":
+		hp.when = haltSoon
+	case "now":
+		hp.when = haltNow
+	default:
+		return nil, errors.New("WithHalt policy must start with 'now' or 'soon': " + policy)
+	}
+
+	on, value, found := strings.Cut(cond, "=")
+	if !found {
+		return nil, errors.New("WithHalt condition must be 'condition=value': " + policy)
+	}
+	switch on {
+	case "fail":
+		hp.on = haltOnFail
+	case "success":
+		hp.on = haltOnSuccess
+	case "done":
+		hp.on = haltOnDone
+	default:
+		return nil, errors.New("WithHalt condition must be fail, success or done: " + policy)
+	}
+
+	if pc, found := strings.CutSuffix(value, "%"); found {
+		f, err := strconv.ParseFloat(pc, 64)
+		if err != nil || f <= 0 || f > 100 {
+			return nil, errors.New("WithHalt percentage must be >0 and <=100: " + policy)
+		}
+		hp.percent = f
+		return hp, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return nil, errors.New("WithHalt value must be a positive integer: " + policy)
+	}
+	hp.count = n
+
+	return hp, nil
+}
+
+// haltState tracks the progress of a Group towards the halt threshold.
+type haltState struct {
+	sync.Mutex
+	failed    int
+	succeeded int
+	err       error // Set once the threshold is reached
+}
+
+// reached returns true if the counts meet the policy threshold given the total number of
+// runners in the Group.
+func (hp *haltPolicy) reached(hs *haltState, total int) bool {
+	var n int
+	switch hp.on {
+	case haltOnFail:
+		n = hs.failed
+	case haltOnSuccess:
+		n = hs.succeeded
+	case haltOnDone:
+		n = hs.failed + hs.succeeded
+	}
+
+	if hp.percent > 0 {
+		return float64(n)*100 >= hp.percent*float64(total)
+	}
+
+	return n >= hp.count
+}
+
+// checkHalt is called by each worker as soon as a runner returns so that no further
+// runners are dispatched once the threshold is reached. If the halt policy threshold is
+// reached, dispatching of new runners is stopped and for a "now" policy the contexts of
+// all active runners are cancelled.
+func (grp *Group) checkHalt(rnr *runner) {
+	hp := grp.haltPolicy
+	if hp == nil || hp.when == haltNever {
+		return
+	}
+
+	grp.halt.Lock()
+	defer grp.halt.Unlock()
+	if grp.halt.err != nil {
+		return
+	}
+
+	if rnr.err != nil {
+		grp.halt.failed++
+	} else {
+		grp.halt.succeeded++
+	}
+
+	grp.mu.Lock()
+	total := len(grp.all) // Can grow in an OpenEnded Group
+	grp.mu.Unlock()
+	if !hp.reached(&grp.halt, total) {
+		return
+	}
+
+	grp.halt.err = ErrHalted
+	grp.stop(ErrHalted)
+	if hp.when == haltNow {
+		grp.cancel(ErrHalted)
+	}
+}
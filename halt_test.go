@@ -0,0 +1,167 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHaltParse(t *testing.T) {
+	type testCase struct {
+		policy  string
+		when    haltWhen
+		on      haltOn
+		count   int
+		percent float64
+		error   string
+	}
+
+	testCases := []testCase{
+		{"never", haltNever, haltOnFail, 0, 0, ""},
+		{"now,fail=1", haltNow, haltOnFail, 1, 0, ""},
+		{"soon,fail=30%", haltSoon, haltOnFail, 0, 30, ""},
+		{"soon,success=2", haltSoon, haltOnSuccess, 2, 0, ""},
+		{"now,done=50%", haltNow, haltOnDone, 0, 50, ""},
+		{"", 0, 0, 0, 0, "must be 'never'"},
+		{"later,fail=1", 0, 0, 0, 0, "must start with"},
+		{"now,fail", 0, 0, 0, 0, "condition=value"},
+		{"now,oops=1", 0, 0, 0, 0, "fail, success or done"},
+		{"now,fail=0", 0, 0, 0, 0, "positive integer"},
+		{"now,fail=x", 0, 0, 0, 0, "positive integer"},
+		{"now,fail=101%", 0, 0, 0, 0, "percentage"},
+	}
+
+	for ix, tc := range testCases {
+		hp, err := parseHaltPolicy(tc.policy)
+		if err != nil {
+			if len(tc.error) == 0 {
+				t.Error(ix, "Unexpected error", err)
+			} else if !strings.Contains(err.Error(), tc.error) {
+				t.Error(ix, "Wrong error. Expected", tc.error, "got", err)
+			}
+			continue
+		}
+		if len(tc.error) > 0 {
+			t.Error(ix, "Expected error", tc.error)
+			continue
+		}
+		if hp.when != tc.when || hp.on != tc.on || hp.count != tc.count ||
+			hp.percent != tc.percent {
+			t.Errorf("%d: Policy mismatch %+v", ix, *hp)
+		}
+	}
+
+	_, err := NewGroup(WithHalt("bogus"))
+	if err == nil {
+		t.Error("Expected NewGroup to reject an invalid halt policy")
+	}
+}
+
+// Test that "soon" stops dispatch but does not cancel active runners while "now" does.
+func TestHaltSoonNow(t *testing.T) {
+	for _, policy := range []string{"soon,fail=1", "now,fail=1"} {
+		grp, err := NewGroup(WithStdout(io.Discard), WithStderr(io.Discard),
+			LimitActiveRunners(2), WithHalt(policy))
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+
+		var ran atomic.Int32
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var activeErr error
+		grp.AddContextErr("", "", func(ctx context.Context, out, err io.Writer) error {
+			ran.Add(1)
+			close(started)
+			select {
+			case <-ctx.Done():
+				activeErr = context.Cause(ctx)
+			case <-release:
+			}
+			return nil
+		})
+		grp.AddErr("", "", func(out, err io.Writer) error {
+			<-started // Make sure the first runner is active
+			ran.Add(1)
+			if policy == "soon,fail=1" {
+				go func() { // Let the active runner complete after halting
+					time.Sleep(time.Millisecond * 50)
+					close(release)
+				}()
+			}
+			return errors.New("failed")
+		})
+		for ix := 0; ix < 5; ix++ {
+			grp.Add("", "", func(out, err io.Writer) { ran.Add(1) })
+		}
+
+		grp.Run()
+		err = grp.Wait()
+		if !errors.Is(err, ErrHalted) {
+			t.Error(policy, "Expected ErrHalted from Wait, not", err)
+		}
+		if ran.Load() != 2 {
+			t.Error(policy, "Expected two runners to run, not", ran.Load())
+		}
+		if policy == "now,fail=1" && !errors.Is(activeErr, ErrHalted) {
+			t.Error(policy, "Expected active runner to be cancelled with ErrHalted, not",
+				activeErr)
+		}
+		if policy == "soon,fail=1" && activeErr != nil {
+			t.Error(policy, "Active runner should not be cancelled", activeErr)
+		}
+		errs := grp.Errors()
+		if !errors.Is(errs[len(errs)-1], ErrHalted) {
+			t.Error(policy, "Skipped runner should have ErrHalted, not", errs[len(errs)-1])
+		}
+	}
+}
+
+// Test that halting does not leave a trailing separator after the last runner to run.
+func TestHaltSeparators(t *testing.T) {
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(io.Discard), LimitActiveRunners(1),
+		WithHalt("soon,fail=1"), WithStdoutSeparator("--\n"))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("a\n")) })
+	grp.AddErr("", "", func(out, err io.Writer) error {
+		out.Write([]byte("x\n"))
+		return errors.New("failed")
+	})
+	for ix := 0; ix < 3; ix++ {
+		grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("skipped\n")) })
+	}
+	grp.Run()
+	if err = grp.Wait(); !errors.Is(err, ErrHalted) {
+		t.Error("Expected ErrHalted from Wait, not", err)
+	}
+	if actual, expect := stdout.String(), "a\n--\nx\n"; actual != expect {
+		t.Errorf("Expected %q, got %q", expect, actual)
+	}
+}
+
+// Test percentage and success thresholds
+func TestHaltPercent(t *testing.T) {
+	hp, _ := parseHaltPolicy("soon,fail=30%")
+	hs := &haltState{failed: 2}
+	if hp.reached(hs, 10) {
+		t.Error("2 of 10 should not reach 30%")
+	}
+	hs.failed = 3
+	if !hp.reached(hs, 10) {
+		t.Error("3 of 10 should reach 30%")
+	}
+
+	hp, _ = parseHaltPolicy("now,done=2")
+	hs = &haltState{failed: 1, succeeded: 1}
+	if !hp.reached(hs, 10) {
+		t.Error("1+1 should reach done=2")
+	}
+}
@@ -1,10 +1,15 @@
 package parallel
 
+import "sync/atomic"
+
 // head provides a stable, lifetime io.Writer interface for RunFunc - one for each of
 // stdout and stderr. It adapts the io.Writer interface of the application to the
 // writer interface of “parallel”.
 type head struct {
 	commonWriter
+	written atomic.Int64         // Total bytes accepted from the RunFunc
+	writes  atomic.Int64         // Total Write calls made by the RunFunc
+	leak    atomic.Pointer[leak] // Set once the runner is detached
 }
 
 func newHead(out writer) *head {
@@ -15,7 +20,14 @@ func newHead(out writer) *head {
 }
 
 func (wtr *head) Write(p []byte) (n int, err error) {
-	return wtr.out.Write(p)
+	if lk := wtr.leak.Load(); lk != nil {
+		return lk.Write(p)
+	}
+	n, err = wtr.out.Write(p)
+	wtr.written.Add(int64(n))
+	wtr.writes.Add(1)
+
+	return
 }
 
 func (wtr *head) close() {
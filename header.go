@@ -0,0 +1,64 @@
+package parallel
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// sectionHeader writes a WithSectionHeaders header to stdout immediately before the
+// first output of a runner on either stream, so the header starts the runner's output
+// block. A runner which produces no output has no header. The stdout and stderr
+// headerWriters of a runner share the one sectionHeader.
+type sectionHeader struct {
+	once sync.Once
+	line []byte
+	out  writer // The stdout writer which receives the header
+}
+
+func newSectionHeader(out writer, format, name string) *sectionHeader {
+	line := fmt.Sprintf(format, name)
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+
+	return &sectionHeader{line: []byte(line), out: out}
+}
+
+func (sh *sectionHeader) write() {
+	sh.once.Do(func() { sh.out.Write(sh.line) })
+}
+
+// headerWriter ensures the sectionHeader is written prior to passing output downstream.
+type headerWriter struct {
+	commonWriter
+	sh *sectionHeader
+}
+
+func newHeaderWriter(out writer, sh *sectionHeader) *headerWriter {
+	wtr := &headerWriter{sh: sh}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+func (wtr *headerWriter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		wtr.sh.write()
+	}
+
+	return wtr.out.Write(p)
+}
+
+func (wtr *headerWriter) close() {
+	wtr.out.close() // Pass it on
+}
+
+// sectionName returns the name used in the section header of a runner.
+func (rnr *runner) sectionName() string {
+	if len(rnr.name) > 0 {
+		return rnr.name
+	}
+
+	return strings.TrimSpace(string(rnr.outTag))
+}
@@ -0,0 +1,67 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSectionHeaders(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr),
+		WithSectionHeaders("===== %s ====="))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("h1: ", "", func(out, err io.Writer) { out.Write([]byte("one\n")) },
+		RunnerName("host1"))
+	grp.Add(" h2 ", "", func(out, err io.Writer) { err.Write([]byte("two\n")) })
+	grp.Add("h3", "", func(out, err io.Writer) {}) // No output so no header
+	grp.Run()
+	grp.Wait()
+
+	exp := "===== host1 =====\nh1: one\n===== h2 =====\n"
+	if got := stdout.String(); got != exp {
+		t.Errorf("Wrong stdout. Got %q Expected %q", got, exp)
+	}
+	if got := stderr.String(); got != "two\n" {
+		t.Error("Wrong stderr", got)
+	}
+}
+
+func TestSectionHeadersBackground(t *testing.T) {
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithCombinedOutput(&stdout), WithSectionHeaders("[%s]\n"))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	grp.Add("", "", func(out, err io.Writer) { <-release; out.Write([]byte("a1\n")) },
+		RunnerName("a"))
+	grp.Add("", "", func(out, err io.Writer) {
+		err.Write([]byte("b1\n")) // Buffered in background until a completes
+		out.Write([]byte("b2\n"))
+		close(release)
+	}, RunnerName("b"))
+	grp.Run()
+	grp.Wait()
+
+	exp := "[a]\na1\n[b]\nb1\nb2\n"
+	if got := stdout.String(); got != exp {
+		t.Errorf("Wrong output. Got %q Expected %q", got, exp)
+	}
+}
+
+func TestSectionHeadersConflicts(t *testing.T) {
+	for _, opt := range []Option{Passthru(true), Ungroup(true), WithJSONOutput(true),
+		WithFramedOutput(true), WithCompression(1)} {
+		_, err := NewGroup(OrderRunners(false), WithSectionHeaders("%s"), opt)
+		if err == nil || !strings.Contains(err.Error(), "WithSectionHeaders") {
+			t.Error("Expected WithSectionHeaders conflict, got", err)
+		}
+	}
+	if _, err := NewGroup(WithSectionHeaders("")); err == nil {
+		t.Error("Expected error for empty format")
+	}
+}
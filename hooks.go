@@ -0,0 +1,63 @@
+package parallel
+
+import "time"
+
+// RunnerInfo identifies a runner to application supplied callbacks such as [Hooks].
+type RunnerInfo struct {
+	Index  int    // Order in which the runner was added, starting at zero
+	Name   string // As supplied by RunnerName
+	OutTag string // As supplied to Add
+	ErrTag string // As supplied to Add
+	Err    error  // Error returned by the RunFunc - only set once it has completed
+	Stream Stream // Only set for WithPipelineWriter
+	Slot   int    // Job slot of the RunFunc - zero until it has started
+
+	// These are only set once all output has been flushed, such as for OnFlush and
+	// WithRunnerFooter.
+	Duration time.Duration // Time spent in the RunFunc
+	Stdout   int64         // Bytes written by the RunFunc to stdout
+	Stderr   int64         // Bytes written by the RunFunc to stderr
+}
+
+// Hooks are application callbacks invoked as each runner progresses through its life
+// cycle. Any hook may be nil. Hooks are called from Group goroutines, possibly
+// concurrently, so they must be safe for concurrent use and they should return quickly
+// as the Group is stalled until they do. Hooks must not call [Group] methods.
+type Hooks struct {
+	OnStart  func(RunnerInfo) // Called just before the RunFunc is called
+	OnFinish func(RunnerInfo) // Called just after the RunFunc returns
+	OnFlush  func(RunnerInfo) // Called once all output has been written to the Group io.Writers
+}
+
+func (rnr *runner) info() RunnerInfo {
+	return RunnerInfo{Index: rnr.index, Name: rnr.name, OutTag: string(rnr.outTag),
+		ErrTag: string(rnr.errTag), Err: rnr.err, Slot: rnr.slot}
+}
+
+// flushedInfo returns info() along with the statistics which are only stable once the
+// runner has been closed.
+func (rnr *runner) flushedInfo() RunnerInfo {
+	info := rnr.info()
+	info.Duration = rnr.duration
+	info.Stdout, info.Stderr = rnr.written()
+
+	return info
+}
+
+func (h *Hooks) start(rnr *runner) {
+	if h.OnStart != nil {
+		h.OnStart(rnr.info())
+	}
+}
+
+func (h *Hooks) finish(rnr *runner) {
+	if h.OnFinish != nil {
+		h.OnFinish(rnr.info())
+	}
+}
+
+func (h *Hooks) flush(rnr *runner) {
+	if h.OnFlush != nil {
+		h.OnFlush(rnr.flushedInfo())
+	}
+}
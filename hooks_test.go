@@ -0,0 +1,58 @@
+package parallel
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) func(RunnerInfo) {
+		return func(ri RunnerInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, fmt.Sprint(event, ri.Index, ri.OutTag, ri.Err))
+		}
+	}
+
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), LimitActiveRunners(1),
+		WithHooks(Hooks{OnStart: record("start"), OnFinish: record("finish"),
+			OnFlush: record("flush")}))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("a", "", func(out, err io.Writer) {})
+	grp.AddErr("b", "", func(out, err io.Writer) error { return errors.New("bad") })
+	grp.Run()
+	grp.Wait()
+
+	expect := []string{"start0a<nil>", "finish0a<nil>", "flush0a<nil>",
+		"start1b<nil>", "finish1bbad", "flush1bbad"}
+	if len(events) != len(expect) {
+		t.Fatal("Wrong number of hook events", events)
+	}
+	for _, e := range expect { // Flush of a may race with start of b
+		found := false
+		for _, a := range events {
+			found = found || a == e
+		}
+		if !found {
+			t.Error("Missing hook event", e, events)
+		}
+	}
+	if events[0] != expect[0] || events[1] != expect[1] {
+		t.Error("Start and finish should be first", events)
+	}
+
+	// Nil hooks are fine
+	grp, _ = NewGroup(WithStdout(&stdout), WithHooks(Hooks{}))
+	grp.Add("", "", func(out, err io.Writer) {})
+	grp.Run()
+	grp.Wait()
+}
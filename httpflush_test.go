@@ -0,0 +1,90 @@
+package parallel
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testHTTPFlusher records the content written as of each Flush.
+type testHTTPFlusher struct {
+	mu      sync.Mutex
+	buf     strings.Builder
+	flushes []string
+}
+
+func (hf *testHTTPFlusher) Write(p []byte) (int, error) {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	return hf.buf.Write(p)
+}
+
+func (hf *testHTTPFlusher) Flush() {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.flushes = append(hf.flushes, hf.buf.String())
+}
+
+var _ http.Flusher = &testHTTPFlusher{}
+
+func TestHTTPFlushRunnerBoundary(t *testing.T) {
+	for _, opts := range [][]Option{{}, {WithBufferedOutput(1024)}, {WithAsyncOutput(4)}} {
+		hf := &testHTTPFlusher{}
+		opts = append(opts, WithStdout(hf), WithStderr(io.Discard))
+		grp, err := NewGroup(opts...)
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		grp.Add("a: ", "", func(out, err io.Writer) { out.Write([]byte("1\n")) })
+		grp.Add("b: ", "", func(out, err io.Writer) { out.Write([]byte("2\n")) })
+		grp.Run()
+		grp.Wait()
+
+		hf.mu.Lock()
+		if len(hf.flushes) != 2 || hf.flushes[0] != "a: 1\n" ||
+			hf.flushes[1] != "a: 1\nb: 2\n" {
+			t.Errorf("%d: Wrong flushes %q", len(opts), hf.flushes)
+		}
+		hf.mu.Unlock()
+	}
+}
+
+func TestHTTPFlushInterval(t *testing.T) {
+	hf := &testHTTPFlusher{}
+	clock := NewTestClock(time.Now())
+	grp, err := NewGroup(WithStdout(hf), WithFlushInterval(time.Second), WithClock(clock))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	grp.Add("", "", func(out, err io.Writer) {
+		out.Write([]byte("partial"))
+		<-release
+	})
+	grp.Run()
+	for clock.Waiters() == 0 { // Until the flush timer is running
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Second)
+	for flushed := false; !flushed; time.Sleep(time.Millisecond) {
+		hf.mu.Lock()
+		flushed = len(hf.flushes) > 0
+		hf.mu.Unlock()
+	}
+	close(release)
+	grp.Wait()
+
+	// Works with a real http.ResponseWriter too
+	rec := httptest.NewRecorder()
+	grp, _ = NewGroup(WithStdout(rec))
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("body")) })
+	grp.Run()
+	grp.Wait()
+	if !rec.Flushed || rec.Body.String() != "body" {
+		t.Error("ResponseRecorder was not flushed", rec.Flushed, rec.Body.String())
+	}
+}
@@ -0,0 +1,223 @@
+package parallel
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JobLogFormat selects the encoding of [WithJobLog]. See [WithJobLogFormat].
+type JobLogFormat int
+
+const (
+	JobLogParallel JobLogFormat = iota // GNU parallel style tab-separated - the default
+	JobLogTSV                          // Tab-separated values with escaped fields
+	JobLogCSV                          // RFC 4180 comma-separated values
+	JobLogJSON                         // One JSON object per line with no header
+)
+
+const jobLogHeader = "Seq\tTag\tStarttime\tJobRuntime\tStdout\tStderr\tError\n"
+
+const jobLogCSVHeader = "Seq,Tag,Starttime,JobRuntime,Stdout,Stderr,Error\n"
+
+const jobLogFields = 7
+
+// jobLogEntry is the content of a job log line regardless of format. The JSON field names
+// follow the header field names.
+type jobLogEntry struct {
+	Seq        int     `json:"seq"`
+	Tag        string  `json:"tag"`
+	Starttime  float64 `json:"starttime"`
+	JobRuntime float64 `json:"jobRuntime"`
+	Stdout     int64   `json:"stdout"`
+	Stderr     int64   `json:"stderr"`
+	Error      *string `json:"error"` // nil if the RunFunc succeeded
+}
+
+// writeJobLogHeader writes the field names for subsequent WithJobLog lines.
+func (grp *Group) writeJobLogHeader() {
+	var header string
+	switch grp.jobLogFormat {
+	case JobLogParallel, JobLogTSV:
+		header = jobLogHeader
+	case JobLogCSV:
+		header = jobLogCSVHeader
+	default: // JSON has no header
+		return
+	}
+
+	grp.outputMu.Lock()
+	defer grp.outputMu.Unlock()
+	io.WriteString(grp.jobLog, header)
+}
+
+// writeJobLog writes the WithJobLog line for a completed runner. The job log may well be
+// the same io.Writer as one of the Group io.Writers so it is protected by the same mutex.
+func (grp *Group) writeJobLog(rnr *runner) {
+	line := formatJobLog(rnr, grp.jobLogFormat)
+
+	grp.outputMu.Lock()
+	defer grp.outputMu.Unlock()
+	io.WriteString(grp.jobLog, line)
+}
+
+func newJobLogEntry(rnr *runner) jobLogEntry {
+	entry := jobLogEntry{Seq: rnr.index + 1, Tag: strings.TrimSpace(string(rnr.outTag)),
+		JobRuntime: rnr.duration.Round(time.Millisecond).Seconds()}
+	if !rnr.started.IsZero() {
+		entry.Starttime = float64(rnr.started.UnixMilli()) / 1000
+	}
+	entry.Stdout, entry.Stderr = rnr.written()
+	if rnr.err != nil {
+		text := rnr.err.Error()
+		entry.Error = &text
+	}
+
+	return entry
+}
+
+func formatJobLog(rnr *runner, format JobLogFormat) string {
+	entry := newJobLogEntry(rnr)
+	start := strconv.FormatFloat(entry.Starttime, 'f', 3, 64)
+	runtime := strconv.FormatFloat(entry.JobRuntime, 'f', 3, 64)
+	seq, stdout, stderr := strconv.Itoa(entry.Seq), strconv.FormatInt(entry.Stdout, 10),
+		strconv.FormatInt(entry.Stderr, 10)
+
+	switch format {
+	case JobLogTSV:
+		errText := "-"
+		if entry.Error != nil {
+			errText = escapeTSV(*entry.Error)
+		}
+		return strings.Join([]string{seq, escapeTSV(entry.Tag), start, runtime, stdout,
+			stderr, errText}, "\t") + "\n"
+
+	case JobLogCSV:
+		var errText string
+		if entry.Error != nil {
+			errText = *entry.Error
+		}
+		var sb strings.Builder
+		cw := csv.NewWriter(&sb)
+		cw.Write([]string{seq, entry.Tag, start, runtime, stdout, stderr, errText})
+		cw.Flush()
+		return sb.String()
+
+	case JobLogJSON:
+		b, _ := json.Marshal(entry) // Cannot fail
+		return string(b) + "\n"
+	}
+
+	errText := "-"
+	if entry.Error != nil {
+		errText = strconv.Quote(*entry.Error)
+	}
+
+	return strings.Join([]string{seq, entry.Tag, start, runtime, stdout, stderr, errText},
+		"\t") + "\n"
+}
+
+// tsvEscaper escapes the characters which cannot otherwise appear in a TSV field.
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func escapeTSV(s string) string {
+	return tsvEscaper.Replace(s)
+}
+
+// parseJobLog reads a job log written by WithJobLog in any JobLogFormat and returns the
+// set of tags which completed without error. The format is determined from the start of
+// the job log. Header lines are ignored, which allows for a job log which has been
+// appended to by multiple runs in the same format.
+func parseJobLog(r io.Reader) (map[string]bool, error) {
+	br := bufio.NewReader(r)
+	start, _ := br.Peek(len(jobLogCSVHeader)) // Short if the job log is short
+	switch {
+	case bytes.HasPrefix(start, []byte("{")):
+		return parseJSONJobLog(br)
+	case string(start) == jobLogCSVHeader:
+		return parseCSVJobLog(br)
+	}
+
+	return parseTabJobLog(br)
+}
+
+// parseTabJobLog parses the JobLogParallel and JobLogTSV formats. TSV escapes are not
+// reversed so tags containing escaped characters never match.
+func parseTabJobLog(r io.Reader) (map[string]bool, error) {
+	done := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if len(line) == 0 || line+"\n" == jobLogHeader {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != jobLogFields {
+			return nil, fmt.Errorf("Job log line %d has %d fields, expected %d",
+				lineNo, len(fields), jobLogFields)
+		}
+		if len(fields[1]) > 0 && fields[jobLogFields-1] == "-" {
+			done[fields[1]] = true
+		}
+	}
+
+	return done, scanner.Err()
+}
+
+func parseCSVJobLog(r io.Reader) (map[string]bool, error) {
+	done := make(map[string]bool)
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = jobLogFields
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Job log: %w", err)
+		}
+		if strings.Join(fields, ",")+"\n" == jobLogCSVHeader {
+			continue
+		}
+		if len(fields[1]) > 0 && len(fields[jobLogFields-1]) == 0 {
+			done[fields[1]] = true
+		}
+	}
+
+	return done, nil
+}
+
+func parseJSONJobLog(r io.Reader) (map[string]bool, error) {
+	done := make(map[string]bool)
+	dec := json.NewDecoder(r)
+	for lineNo := 1; ; lineNo++ {
+		var entry jobLogEntry
+		if err := dec.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("Job log line %d: %w", lineNo, err)
+		}
+		if len(entry.Tag) > 0 && entry.Error == nil {
+			done[entry.Tag] = true
+		}
+	}
+
+	return done, nil
+}
+
+// resumable returns true if the runner previously completed successfully according to
+// the WithResume job log or the SkipExistingResults runner directory.
+func (grp *Group) resumable(rnr *runner) bool {
+	if rnr.resultsDone {
+		return true
+	}
+	tag := strings.TrimSpace(string(rnr.outTag))
+
+	return len(tag) > 0 && grp.resume[tag]
+}
@@ -0,0 +1,217 @@
+package parallel
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobLogFormat(t *testing.T) {
+	rnr := newRunner("a: ", "", nil)
+	rnr.index = 2
+	rnr.started = time.UnixMilli(1700000000123)
+	rnr.duration = time.Millisecond * 1500
+	rnr.err = errors.New("bad")
+	rnr.stdout = newHead(nil)
+	rnr.stderr = newHead(nil)
+	rnr.stdout.(*head).written.Store(10)
+	rnr.stderr.(*head).written.Store(3)
+
+	got := formatJobLog(rnr, JobLogParallel)
+	expect := "3\ta:\t1700000000.123\t1.500\t10\t3\t\"bad\"\n"
+	if got != expect {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+}
+
+func TestJobLogGroup(t *testing.T) {
+	var stdout, stderr, jobLog bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), WithJobLog(&jobLog))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("one", "", func(out, err io.Writer) { out.Write([]byte("12345\n")) })
+	grp.AddErr("two", "", func(out, err io.Writer) error {
+		err.Write([]byte("e\n"))
+		return errors.New("failed")
+	})
+	grp.Run()
+	grp.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(jobLog.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatal("Expected header and two job log lines, got", lines)
+	}
+	if lines[0]+"\n" != jobLogHeader {
+		t.Error("Wrong job log header", lines[0])
+	}
+	f1 := strings.Split(lines[1], "\t")
+	f2 := strings.Split(lines[2], "\t")
+	if f1[0] != "1" || f1[1] != "one" || f1[4] != "6" || f1[5] != "0" || f1[6] != "-" {
+		t.Error("Wrong first job log line", f1)
+	}
+	if f2[0] != "2" || f2[1] != "two" || f2[4] != "0" || f2[5] != "2" || f2[6] != `"failed"` {
+		t.Error("Wrong second job log line", f2)
+	}
+
+	_, err = NewGroup(WithJobLog(nil))
+	if err == nil {
+		t.Error("Expected error from WithJobLog(nil)")
+	}
+}
+
+func TestJobLogParse(t *testing.T) {
+	log := jobLogHeader +
+		"1\tone\t1.000\t1.000\t0\t0\t-\n" +
+		"2\ttwo\t1.000\t1.000\t0\t0\t\"failed\"\n" +
+		"3\t\t1.000\t1.000\t0\t0\t-\n" +
+		jobLogHeader +
+		"2\ttwo\t2.000\t1.000\t0\t0\t-\n"
+	done, err := parseJobLog(strings.NewReader(log))
+	if err != nil {
+		t.Fatal("Unexpected parse error", err)
+	}
+	if len(done) != 2 || !done["one"] || !done["two"] {
+		t.Error("Wrong set of completed tags", done)
+	}
+
+	_, err = parseJobLog(strings.NewReader("1\tone\n"))
+	if err == nil {
+		t.Error("Expected error from short job log line")
+	}
+}
+
+// Test that WithResume only re-runs the failed runners.
+func TestJobLogResume(t *testing.T) {
+	var stdout, jobLog bytes.Buffer
+	fail := true
+	run := func(jobLog io.Writer, opts ...Option) (ran []string) {
+		opts = append(opts, WithStdout(&stdout), WithJobLog(jobLog), LimitActiveRunners(1))
+		grp, err := NewGroup(opts...)
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		for _, tag := range []string{"a", "b", "c"} {
+			tag := tag
+			grp.AddErr(tag, "", func(out, err io.Writer) error {
+				ran = append(ran, tag)
+				if tag == "b" && fail {
+					return errors.New("b failed")
+				}
+				return nil
+			})
+		}
+		grp.Run()
+		grp.Wait()
+		return
+	}
+
+	ran := run(&jobLog)
+	if len(ran) != 3 {
+		t.Fatal("First run should run everything", ran)
+	}
+
+	fail = false
+	previous := jobLog.String()
+	ran = run(&jobLog, WithResume(strings.NewReader(previous)))
+	if len(ran) != 1 || ran[0] != "b" {
+		t.Error("Resume should only re-run the failed runner", ran)
+	}
+	if strings.Count(jobLog.String(), "\tb\t") != 2 || strings.Count(jobLog.String(), "\ta\t") != 1 {
+		t.Error("Resumed runners should not be logged again", jobLog.String())
+	}
+
+	// Validating first must not consume the job log
+	resume := WithResume(strings.NewReader(previous))
+	if err := ValidateOptions(resume); err != nil {
+		t.Error("Unexpected ValidateOptions error", err)
+	}
+	ran = run(io.Discard, resume)
+	if len(ran) != 1 || ran[0] != "b" {
+		t.Error("Resume after ValidateOptions should only re-run b", ran)
+	}
+
+	_, err := NewGroup(WithResume(nil))
+	if err == nil {
+		t.Error("Expected error from WithResume(nil)")
+	}
+}
+
+// Test that resumed-over runners, including those at the end, leave no separators.
+func TestJobLogResumeSeparators(t *testing.T) {
+	var stdout, jobLog bytes.Buffer
+	run := func(opts ...Option) {
+		opts = append(opts, WithStdout(&stdout), WithStdoutSeparator("--\n"),
+			LimitActiveRunners(1))
+		grp, err := NewGroup(opts...)
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		for _, tag := range []string{"a ", "b ", "c ", "d "} {
+			grp.AddErr(tag, "", func(out, err io.Writer) error {
+				out.Write([]byte("x\n"))
+				if tag == "a " || tag == "c " {
+					return errors.New("failed")
+				}
+				return nil
+			})
+		}
+		grp.Run()
+		grp.Wait()
+	}
+
+	run(WithJobLog(&jobLog))
+	stdout.Reset()
+	run(WithResume(&jobLog))
+	if actual, expect := stdout.String(), "a x\n--\nc x\n"; actual != expect {
+		t.Errorf("Expected %q, got %q", expect, actual)
+	}
+}
+
+func TestJobLogFormats(t *testing.T) {
+	if _, err := NewGroup(WithJobLogFormat(JobLogJSON + 1)); err == nil {
+		t.Error("Expected error with unknown format")
+	}
+	testCases := []struct {
+		format JobLogFormat
+		header string
+		line   string
+	}{
+		{JobLogTSV, jobLogHeader, "2\ta\\tb\t0.000\t0.000\t1\t0\tbad\\nnews\n"},
+		{JobLogCSV, jobLogCSVHeader, "2,a\tb,0.000,0.000,1,0,\"bad\nnews\"\n"},
+		{JobLogJSON, "", `{"seq":2,"tag":"a\tb","starttime":0,"jobRuntime":0,` +
+			`"stdout":1,"stderr":0,"error":"bad\nnews"}` + "\n"},
+	}
+	for _, tc := range testCases {
+		var jobLog bytes.Buffer
+		grp, err := NewGroup(WithStdout(io.Discard), WithJobLog(&jobLog),
+			WithJobLogFormat(tc.format), WithClock(NewTestClock(time.Unix(0, 0))))
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		grp.Add("ok", "", func(out, err io.Writer) {})
+		grp.AddErr("a\tb", "", func(out, err io.Writer) error {
+			out.Write([]byte("x"))
+			return errors.New("bad\nnews")
+		})
+		grp.Run()
+		grp.Wait()
+
+		got := jobLog.String()
+		if !strings.HasPrefix(got, tc.header) || !strings.HasSuffix(got, tc.line) {
+			t.Errorf("%d: Wrong job log %q", tc.format, got)
+		}
+
+		// Every format can be resumed, skipping only the successful RunFunc
+		done, err := parseJobLog(strings.NewReader(got + got[len(tc.header):]))
+		if err != nil {
+			t.Error(tc.format, "Unexpected parse error", err)
+		}
+		if len(done) != 1 || !done["ok"] {
+			t.Error(tc.format, "Wrong set of completed tags", done)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package parallel
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// jsonRecord is the object written for each line by WithJSONOutput.
+type jsonRecord struct {
+	Seq    int    `json:"seq"`
+	Tag    string `json:"tag"`
+	Stream string `json:"stream"`
+	Data   string `json:"data"`
+}
+
+// jsonLines is a writer which replaces the tagger when WithJSONOutput is set. Each line is
+// encoded as a jsonRecord and written with a single Write to the next writer. A partial
+// line is held back until it is completed or the writer is closed.
+type jsonLines struct {
+	mu sync.Mutex
+	commonWriter
+	rec     jsonRecord
+	delim   byte // Line delimiter - see WithLineDelimiter
+	partial []byte
+}
+
+func newJSONLines(out writer, rnr *runner, stream Stream, delim byte) *jsonLines {
+	wtr := &jsonLines{rec: jsonRecord{Seq: rnr.index + 1,
+		Tag: strings.TrimSpace(string(rnr.outTag)), Stream: stream.String()}, delim: delim}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+// Write encodes each complete line. As with tagger, the returned count is that of the
+// supplied data, not what is written downstream.
+func (wtr *jsonLines) Write(p []byte) (n int, err error) {
+	wtr.mu.Lock()
+	defer wtr.mu.Unlock()
+
+	wtr.partial = append(wtr.partial, p...)
+	for {
+		ix := bytes.IndexByte(wtr.partial, wtr.delim)
+		if ix < 0 {
+			break
+		}
+		e := wtr.writeRecord(wtr.partial[:ix])
+		if e != nil && err == nil {
+			err = e
+		}
+		wtr.partial = wtr.partial[ix+1:]
+	}
+	if len(wtr.partial) == 0 {
+		wtr.partial = nil // Release to GC
+	}
+
+	return len(p), err
+}
+
+func (wtr *jsonLines) writeRecord(line []byte) error {
+	wtr.rec.Data = string(line)
+	b, err := json.Marshal(&wtr.rec)
+	if err != nil {
+		return err
+	}
+
+	_, err = wtr.out.Write(append(b, '\n'))
+
+	return err
+}
+
+func (wtr *jsonLines) close() {
+	wtr.mu.Lock()
+	if len(wtr.partial) > 0 {
+		wtr.writeRecord(wtr.partial)
+		wtr.partial = nil
+	}
+	wtr.mu.Unlock()
+	wtr.out.close() // Pass it on
+}
@@ -0,0 +1,53 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestJSONLinesWriter(t *testing.T) {
+	var out testBufWriter
+	rnr := newRunner(" host1\t", "", nil)
+	rnr.index = 2
+	wtr := newJSONLines(&out, rnr, Stderr, '\n')
+	wtr.Write([]byte("a\nb \"q\""))
+	wtr.Write([]byte("\n\npart"))
+	wtr.close()
+
+	expect := `{"seq":3,"tag":"host1","stream":"stderr","data":"a"}` + "\n" +
+		`{"seq":3,"tag":"host1","stream":"stderr","data":"b \"q\""}` + "\n" +
+		`{"seq":3,"tag":"host1","stream":"stderr","data":""}` + "\n" +
+		`{"seq":3,"tag":"host1","stream":"stderr","data":"part"}` + "\n"
+	if out.String() != expect {
+		t.Errorf("JSON output mismatch\nExpect: %s\nActual: %s", expect, out.String())
+	}
+}
+
+func TestJSONLinesGroup(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), WithJSONOutput(true))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("one", "ignored", func(out, err io.Writer) {
+		out.Write([]byte("x\n"))
+		err.Write([]byte("y\n"))
+	})
+	grp.Run()
+	grp.Wait()
+
+	expect := `{"seq":1,"tag":"one","stream":"stdout","data":"x"}` + "\n" +
+		`{"seq":1,"tag":"one","stream":"stderr","data":"y"}` + "\n"
+	if stdout.String() != expect || stderr.Len() != 0 {
+		t.Errorf("JSON group output mismatch %q %q", stdout.String(), stderr.String())
+	}
+
+	for _, opt := range []Option{WithStdoutSeparator("--\n"), WithTagColors(),
+		Passthru(true)} {
+		_, err = NewGroup(WithJSONOutput(true), OrderRunners(false), opt)
+		if err == nil {
+			t.Error("Expected conflict error with WithJSONOutput")
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package parallel
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// debug logs a runner event to the WithLogger logger, if set.
+func (grp *Group) debug(msg string, rnr *runner, args ...any) {
+	if grp.logger == nil || !grp.logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	args = append([]any{"index", rnr.index, "tag", strings.TrimSpace(string(rnr.outTag))},
+		args...)
+	grp.logger.Debug("parallel: "+msg, args...)
+}
+
+// switchToForeground switches the runner to foreground, logging if it was switched.
+func (grp *Group) switchToForeground(rnr *runner) {
+	if rnr.switchToForeground() {
+		grp.debug("foreground", rnr)
+		if grp.buffered != nil { // Show drained output now rather than when rnr ends
+			grp.flushBuffered(rnr)
+		}
+	}
+}
+
+// onThrottle is called by a runner's queue when its usage first exceeds
+// SoftLimitMemoryPerRunner with used bytes buffered.
+func (grp *Group) onThrottle(rnr *runner, used uint64) {
+	grp.debug("throttled", rnr, "buffered", used)
+	if grp.onThrottled != nil {
+		grp.onThrottled(rnr.info(), used)
+	}
+}
+
+// onBlock is called by a runner's queue when a Write is stalled by LimitMemoryPerRunner
+// with used bytes buffered.
+func (grp *Group) onBlock(rnr *runner, used uint64) {
+	grp.debug("blocked", rnr, "buffered", used)
+	if grp.onBlocked != nil {
+		grp.onBlocked(rnr.info(), used)
+	}
+	if grp.blocked != nil {
+		grp.notifyBlocked()
+	}
+}
@@ -0,0 +1,156 @@
+package parallel
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+	var logBuf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logBuf,
+		&slog.HandlerOptions{Level: slog.LevelDebug}))
+	grp, err := NewGroup(WithStdout(io.Discard), WithLogger(logger), LimitActiveRunners(1),
+		LimitMemoryPerRunner(2))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	grp.Add("a", "", func(out, err io.Writer) { <-release })
+	grp.Add("b", "", func(out, err io.Writer) { out.Write([]byte("hello\n")) })
+	grp.Run()
+	close(release)
+	grp.Wait()
+
+	log := logBuf.String()
+	for _, want := range []string{
+		`msg="parallel: foreground" index=0 tag=a`,
+		`msg="parallel: dispatch" index=0 tag=a`,
+		`msg="parallel: complete" index=0 tag=a duration=`,
+		`msg="parallel: flush" index=0 tag=a`,
+		`msg="parallel: foreground" index=1 tag=b`,
+		`msg="parallel: flush" index=1 tag=b`,
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("Log missing %q in\n%s", want, log)
+		}
+	}
+
+	// Info level suppresses all events
+	logBuf.Reset()
+	grp, _ = NewGroup(WithStdout(io.Discard), WithLogger(slog.New(slog.NewTextHandler(&logBuf,
+		nil))))
+	grp.Add("a", "", func(out, err io.Writer) {})
+	grp.Run()
+	grp.Wait()
+	if logBuf.Len() != 0 {
+		t.Error("Expected no log output at Info level", logBuf.String())
+	}
+
+	_, err = NewGroup(WithLogger(nil))
+	if err == nil {
+		t.Error("Expected error from WithLogger(nil)")
+	}
+}
+
+func TestLoggerBlockedAndDrainError(t *testing.T) {
+	var logBuf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logBuf,
+		&slog.HandlerOptions{Level: slog.LevelDebug}))
+	ttw := &testTruncateWriter{}
+	ttw.append("fail", 0, errors.New("disk full"))
+	grp, err := NewGroup(WithStdout(ttw), WithLogger(logger),
+		LimitActiveRunners(2), LimitMemoryPerRunner(2))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	grp.Add("a", "", func(out, err io.Writer) { <-release })
+	grp.Add("b", "", func(out, err io.Writer) {
+		out.Write([]byte("x"))
+		close(release)
+		out.Write([]byte("hello\n")) // Blocks
+	})
+	grp.Run()
+	grp.Wait()
+
+	log := logBuf.String()
+	for _, want := range []string{
+		`msg="parallel: blocked" index=1 tag=b`,
+		`msg="parallel: drain error" index=1 tag=b error="disk full"`,
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("Log missing %q in\n%s", want, log)
+		}
+	}
+}
+
+func TestOnBlocked(t *testing.T) {
+	var info RunnerInfo
+	var buffered uint64
+	blocked := make(chan struct{})
+	grp, err := NewGroup(WithStdout(io.Discard), LimitActiveRunners(2),
+		LimitMemoryPerRunner(4),
+		WithOnBlocked(func(i RunnerInfo, b uint64) {
+			info, buffered = i, b
+			close(blocked) // Only called once as b only blocks once
+		}))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("a", "", func(out, err io.Writer) { <-blocked })
+	grp.Add("b", "", func(out, err io.Writer) {
+		out.Write([]byte("123"))
+		out.Write([]byte("hello\n")) // Blocks
+	})
+	grp.Run()
+	grp.Wait()
+
+	if info.Index != 1 || info.OutTag != "b" || buffered != 3 {
+		t.Error("Wrong OnBlocked arguments", info.Index, info.OutTag, buffered)
+	}
+
+	if _, err := NewGroup(WithOnBlocked(nil)); err == nil {
+		t.Error("Expected error with nil function")
+	}
+}
+
+func TestOnThrottled(t *testing.T) {
+	var info RunnerInfo
+	var buffered uint64
+	grp, err := NewGroup(WithStdout(io.Discard), LimitActiveRunners(2),
+		LimitMemoryPerRunner(100), SoftLimitMemoryPerRunner(10, time.Millisecond),
+		WithOnThrottled(func(i RunnerInfo, b uint64) { info, buffered = i, b }))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	grp.Add("a", "", func(out, err io.Writer) { <-release })
+	grp.Add("b", "", func(out, err io.Writer) {
+		out.Write([]byte("0123456789abc")) // Throttled in background
+		close(release)
+	})
+	grp.Run()
+	grp.Wait()
+
+	if info.Index != 1 || buffered != 13 {
+		t.Error("Wrong OnThrottled arguments", info.Index, buffered)
+	}
+
+	for _, opts := range [][]Option{
+		{SoftLimitMemoryPerRunner(10, time.Second)},
+		{LimitActiveRunners(1), LimitMemoryPerRunner(10),
+			SoftLimitMemoryPerRunner(10, time.Second)},
+		{SoftLimitMemoryPerRunner(0, time.Second)},
+		{SoftLimitMemoryPerRunner(10, 0)},
+		{WithOnThrottled(nil)},
+	} {
+		if _, err := NewGroup(opts...); err == nil {
+			t.Error("Expected setup error", len(opts))
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package parallel
+
+import (
+	"io"
+)
+
+// Map is a convenience function which runs fn for each element of items in parallel with
+// all output serialised by a [Group] constructed with the supplied Options. It returns a
+// slice of results in the same order as items regardless of the order in which each fn
+// completes. This saves callers from writing the boilerplate closures, result slices and
+// concurrency controls typically needed to collect results from RunFuncs.
+//
+// The returned error is the aggregate error returned by [Group.Wait] or an error from
+// [NewGroup] if the Options are invalid, in which case the results are nil. A result is
+// always stored, even when fn returns an error.
+//
+//	sums, err := parallel.Map(os.Args[1:],
+//		func(file string, stdout, stderr io.Writer) (string, error) {
+//			return checksum(file, stdout, stderr)
+//		},
+//		parallel.LimitActiveRunners(8))
+func Map[T, R any](items []T, fn func(item T, stdout, stderr io.Writer) (R, error),
+	opts ...Option) ([]R, error) {
+	grp, err := NewGroup(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]R, len(items))
+	for ix := range items {
+		ix := ix // Pre 1.22 semantics
+		grp.AddErr("", "", func(stdout, stderr io.Writer) error {
+			var err error
+			results[ix], err = fn(items[ix], stdout, stderr) // Each RunFunc has its own slot
+			return err
+		})
+	}
+
+	grp.Run()
+	grp.CloseAdd() // In case OpenEnded was supplied
+	err = grp.Wait()
+
+	return results, err
+}
+
+// ForEach is identical to [Map] except that fn does not return a result.
+func ForEach[T any](items []T, fn func(item T, stdout, stderr io.Writer) error,
+	opts ...Option) error {
+	_, err := Map(items,
+		func(item T, stdout, stderr io.Writer) (struct{}, error) {
+			return struct{}{}, fn(item, stdout, stderr)
+		},
+		opts...)
+
+	return err
+}
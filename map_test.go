@@ -0,0 +1,60 @@
+package parallel
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test that Map preserves input order for both results and output.
+func TestMap(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	items := []int{5, 4, 3, 2, 1}
+	results, err := Map(items,
+		func(item int, out, err io.Writer) (string, error) {
+			time.Sleep(time.Millisecond * time.Duration(item*10)) // Reverse completion
+			fmt.Fprintln(out, "item", item)
+			return strings.Repeat("x", item), nil
+		},
+		WithStdout(&stdout), WithStderr(&stderr))
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	for ix, item := range items {
+		if results[ix] != strings.Repeat("x", item) {
+			t.Error(ix, "Result out of order", results[ix])
+		}
+	}
+
+	actual := stdout.String()
+	expect := "item 5\nitem 4\nitem 3\nitem 2\nitem 1\n"
+	if actual != expect {
+		t.Error("Map output mismatch.\nExpect:\n", expect, "\nActual\n", actual)
+	}
+}
+
+func TestMapErrors(t *testing.T) {
+	_, err := Map([]int{1}, func(int, io.Writer, io.Writer) (int, error) { return 0, nil },
+		WithStdout(nil))
+	if err == nil {
+		t.Error("Expected Map to return the NewGroup error")
+	}
+
+	e2 := errors.New("two failed")
+	err = ForEach([]int{1, 2, 3},
+		func(item int, out, err io.Writer) error {
+			if item == 2 {
+				return e2
+			}
+			return nil
+		},
+		WithStdout(io.Discard), OpenEnded(true))
+	if !errors.Is(err, e2) {
+		t.Error("Expected ForEach to return runner error, not", err)
+	}
+}
@@ -0,0 +1,47 @@
+package parallel
+
+// Metrics is a point-in-time snapshot of Group activity as returned by [Group.Metrics].
+type Metrics struct {
+	Added    int   // Runners added to the Group
+	Pending  int   // Runners not yet started
+	Active   int   // Runners whose RunFunc is running
+	Flushed  int   // Runners whose output has been written to the Group io.Writers
+	Blocked  int   // Runners stalled on a Write by LimitMemoryPerRunner
+	Buffered int64 // Output bytes currently buffered for background runners
+	Written  int64 // Total output bytes written by all RunFuncs so far
+}
+
+// Metrics returns a snapshot of Group activity which is suitable for monitoring
+// long-running programs which embed a Group. Metrics can be called at any time from any
+// goroutine. To publish the metrics via [expvar]:
+//
+//	expvar.Publish("parallel", expvar.Func(func() any { return group.Metrics() }))
+//
+// As the values are gathered from concurrently changing state, they are not necessarily
+// mutually consistent.
+func (grp *Group) Metrics() Metrics {
+	grp.mu.Lock()
+	defer grp.mu.Unlock()
+
+	started := int(grp.started.Load())
+	completed := int(grp.completed.Load())
+	m := Metrics{Added: len(grp.all), Pending: len(grp.all) - started,
+		Active: started - completed, Flushed: len(grp.all) - grp.live}
+	for _, rnr := range grp.all {
+		stdout, stderr := rnr.written()
+		m.Written += stdout + stderr
+	}
+	for ix := grp.front; ix < len(grp.all); ix++ {
+		rnr := grp.all[ix]
+		if rnr.removed || rnr.queue == nil {
+			continue
+		}
+		outLen, errLen := rnr.queue.cq.len()
+		m.Buffered += int64(outLen + errLen)
+		if _, isBlocked := rnr.queue.cq.usage(); isBlocked {
+			m.Blocked++
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,53 @@
+package parallel
+
+import (
+	"io"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	grp, err := NewGroup(WithStdout(io.Discard), LimitActiveRunners(2),
+		LimitMemoryPerRunner(4))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	blocking := make(chan struct{})
+	fronted := make(chan struct{})
+	grp.Add("", "", func(out, err io.Writer) {
+		out.Write([]byte("front\n")) // Foreground so never buffered
+		close(fronted)
+		<-release
+	})
+	grp.Add("", "", func(out, err io.Writer) {
+		out.Write([]byte("abc"))
+		close(blocking)
+		out.Write([]byte("defghi")) // Over the limit so blocks
+	})
+	grp.Add("", "", func(out, err io.Writer) {})
+
+	m := grp.Metrics()
+	if m.Added != 3 || m.Pending != 3 || m.Active != 0 || m.Written != 0 {
+		t.Error("Wrong metrics before Run", m)
+	}
+	grp.Run()
+	<-blocking
+	<-fronted
+	for { // Wait for the front write and for the second Write to block
+		m = grp.Metrics()
+		if m.Blocked == 1 && m.Written == 9 && m.Active == 2 {
+			break
+		}
+	}
+	if m.Pending != 1 || m.Buffered != 3 || m.Flushed != 0 {
+		t.Error("Wrong metrics while running", m)
+	}
+
+	close(release)
+	grp.Wait()
+	m = grp.Metrics()
+	if m.Active != 0 || m.Pending != 0 || m.Blocked != 0 || m.Buffered != 0 ||
+		m.Written != 15 || m.Flushed != 3 {
+		t.Error("Wrong metrics after Wait", m)
+	}
+}
@@ -0,0 +1,88 @@
+package parallel
+
+import (
+	"io"
+)
+
+// Stream identifies which of the RunFunc output streams a [RunnerInfo] refers to.
+type Stream int
+
+const (
+	Stdout Stream = iota
+	Stderr
+)
+
+func (s Stream) String() string {
+	if s == Stderr {
+		return "stderr"
+	}
+
+	return "stdout"
+}
+
+// PipelineWriter constructs an application writer stage which is inserted into a runner
+// pipeline. See [WithPipelineWriter].
+type PipelineWriter func(next io.Writer, info RunnerInfo) io.Writer
+
+// middleware is a writer which passes all output thru an application supplied io.Writer
+// created by a PipelineWriter. The application io.Writer writes to the next writer in the
+// pipeline. If the application io.Writer is also an io.Closer it is closed prior to the
+// rest of the pipeline so that it can flush any data it has held back.
+type middleware struct {
+	commonWriter
+	app io.Writer
+}
+
+func newMiddleware(out writer, pw PipelineWriter, info RunnerInfo) *middleware {
+	wtr := &middleware{}
+	wtr.setNext(out)
+	wtr.app = pw(out, info)
+
+	return wtr
+}
+
+func (wtr *middleware) Write(p []byte) (n int, err error) {
+	return wtr.app.Write(p)
+}
+
+func (wtr *middleware) close() {
+	if c, ok := wtr.app.(io.Closer); ok {
+		c.Close()
+	}
+	wtr.out.close() // Pass it on
+}
+
+// addMiddleware inserts any application writer stages in front of stdout and stderr.
+// Stages are constructed from the tail end so the last WithStage is built first. Any
+// WithLineFilter and StripANSI precede all application stages so they never see dropped
+// lines or escape sequences and any WithSourceEncoding precedes everything so that all
+// stages see UTF-8.
+func (rnr *runner) addMiddleware(grp *Group, stdout, stderr writer) (writer, writer) {
+	outInfo := rnr.info()
+	outInfo.Stream = Stdout
+	errInfo := rnr.info()
+	errInfo.Stream = Stderr
+
+	for ix := len(grp.stages) - 1; ix >= 0; ix-- {
+		stdout = toWriter(grp.stages[ix](toStage(stdout), outInfo))
+		stderr = toWriter(grp.stages[ix](toStage(stderr), errInfo))
+	}
+	if grp.pipelineWriter != nil {
+		stdout = newMiddleware(stdout, grp.pipelineWriter, outInfo)
+		stderr = newMiddleware(stderr, grp.pipelineWriter, errInfo)
+	}
+	if grp.lineFilter != nil {
+		stdout = newLineFilter(stdout, grp.lineFilter, Stdout, grp.delim)
+		stderr = newLineFilter(stderr, grp.lineFilter, Stderr, grp.delim)
+	}
+	if grp.stripANSI {
+		stdout = newANSIStripper(stdout)
+		stderr = newANSIStripper(stderr)
+	}
+	if rnr.newDecoder != nil {
+		stdout = newTranscoder(stdout, rnr.newDecoder())
+		stderr = newTranscoder(stderr, rnr.newDecoder())
+	}
+
+	return stdout, stderr
+}
@@ -0,0 +1,75 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// upperCloser upper-cases output and holds back a trailing partial line until closed.
+type upperCloser struct {
+	next    io.Writer
+	partial []byte
+	stream  Stream
+}
+
+func (uc *upperCloser) Write(p []byte) (int, error) {
+	uc.partial = append(uc.partial, bytes.ToUpper(p)...)
+	if ix := bytes.LastIndexByte(uc.partial, '\n'); ix >= 0 {
+		uc.next.Write(uc.partial[:ix+1])
+		uc.partial = uc.partial[ix+1:]
+	}
+
+	return len(p), nil
+}
+
+func (uc *upperCloser) Close() error {
+	if len(uc.partial) > 0 {
+		uc.next.Write(uc.partial)
+	}
+
+	return nil
+}
+
+func TestPipelineWriter(t *testing.T) {
+	var infos []RunnerInfo
+	pw := func(next io.Writer, info RunnerInfo) io.Writer {
+		infos = append(infos, info)
+		return &upperCloser{next: next, stream: info.Stream}
+	}
+
+	for _, opts := range [][]Option{{}, {OrderRunners(false), Passthru(true)},
+		{OrderRunners(false), Ungroup(true)}} {
+		infos = nil
+		var stdout, stderr bytes.Buffer
+		opts = append(opts, WithStdout(&stdout), WithStderr(&stderr), WithPipelineWriter(pw))
+		grp, err := NewGroup(opts...)
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		grp.Add("t: ", "", func(out, err io.Writer) {
+			out.Write([]byte("hello\nwor"))
+			out.Write([]byte("ld"))
+			err.Write([]byte("oops\n"))
+		})
+		grp.Run()
+		grp.Wait()
+
+		if len(infos) != 2 || infos[0].Stream != Stdout || infos[1].Stream != Stderr ||
+			infos[0].OutTag != "t: " {
+			t.Error("Wrong RunnerInfo", infos)
+		}
+		expect := "t: HELLO\nt: WORLD"
+		if stdout.String() != expect || stderr.String() != "OOPS\n" {
+			t.Errorf("Wrong middleware output %q %q", stdout.String(), stderr.String())
+		}
+	}
+
+	_, err := NewGroup(WithPipelineWriter(nil))
+	if err == nil {
+		t.Error("Expected error from WithPipelineWriter(nil)")
+	}
+	if Stdout.String() != "stdout" || Stderr.String() != "stderr" {
+		t.Error("Wrong Stream names")
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Package parallelcli helps commands written in go accept GNU parallel style command lines.
+It parses an argument list such as:
+
+	wc -l ::: a b c :::+ x y z ::: 1 2
+
+into the command and the argument tuple of each job, so that each tool need not
+reimplement the parsing of the GNU parallel “magic” tokens. The command can then be
+treated as a [Template] containing GNU parallel replacement strings, such as “{}” and
+“{.}”, which is expanded for each job.
+*/
+package parallelcli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// The tokens which introduce each input source.
+const (
+	Args      = ":::"   // Arguments follow
+	LinkArgs  = ":::+"  // Arguments follow which are linked to the previous input source
+	Files     = "::::"  // Files follow, each line of which is an argument
+	LinkFiles = "::::+" // As for Files, linked to the previous input source
+)
+
+// Invocation is the result of parsing a command line with [Parse].
+type Invocation struct {
+	Command []string   // Everything prior to the first input source
+	Jobs    [][]string // The argument tuple of each job in GNU parallel order
+}
+
+// Parse splits args into the command and the argument tuple of each job. Each input source
+// starts with a token, such as [Args], and continues until the next token or the end of
+// args. Unlinked input sources are combined as a cartesian product, with the first input
+// source varying the slowest, so “::: a b ::: 1 2” results in the jobs “a 1”, “a 2”, “b 1”
+// and “b 2”.
+//
+// An input source introduced with [LinkArgs] or [LinkFiles] is instead paired
+// element-by-element with the previous input source, so “::: a b :::+ 1 2” results in the
+// jobs “a 1” and “b 2”. If linked input sources differ in length, the excess arguments are
+// ignored.
+//
+// Each file following [Files] or [LinkFiles] is a separate input source which supplies one
+// argument per line, with the name "-" meaning os.Stdin. Empty files and empty input
+// sources are an error, as is an args list without any input sources.
+func Parse(args []string) (*Invocation, error) {
+	inv := &Invocation{}
+	ix := 0
+	for ; ix < len(args) && !isToken(args[ix]); ix++ {
+		inv.Command = append(inv.Command, args[ix])
+	}
+	if ix == len(args) {
+		return nil, errors.New("parallelcli: '" + Args + "' delimiter not found")
+	}
+
+	var groups [][][]string // Each group is a list of linked sources
+	for ix < len(args) {
+		token := args[ix]
+		ix++
+		var values []string
+		for ; ix < len(args) && !isToken(args[ix]); ix++ {
+			values = append(values, args[ix])
+		}
+		if len(values) == 0 {
+			return nil, errors.New("parallelcli: Need at least one argument after '" +
+				token + "'")
+		}
+
+		var sources [][]string
+		if token == Files || token == LinkFiles {
+			for _, name := range values {
+				lines, err := readLines(name)
+				if err != nil {
+					return nil, err
+				}
+				sources = append(sources, lines)
+			}
+		} else {
+			sources = [][]string{values}
+		}
+
+		for _, source := range sources {
+			if (token == LinkArgs || token == LinkFiles) && len(groups) > 0 {
+				last := len(groups) - 1
+				groups[last] = append(groups[last], source)
+				token = Args // Subsequent files of this token are separate sources
+				continue
+			}
+			if token == LinkArgs || token == LinkFiles {
+				return nil, errors.New("parallelcli: '" + token +
+					"' must follow another input source")
+			}
+			groups = append(groups, [][]string{source})
+		}
+	}
+
+	inv.Jobs = [][]string{{}}
+	for _, group := range groups {
+		var next [][]string
+		for _, job := range inv.Jobs {
+			for _, linked := range link(group) {
+				next = append(next, append(append([]string{}, job...), linked...))
+			}
+		}
+		inv.Jobs = next
+	}
+
+	return inv, nil
+}
+
+func isToken(s string) bool {
+	return s == Args || s == LinkArgs || s == Files || s == LinkFiles
+}
+
+// link pairs the elements of linked sources, truncating to the shortest source.
+func link(sources [][]string) (tuples [][]string) {
+	n := len(sources[0])
+	for _, s := range sources[1:] {
+		n = min(n, len(s))
+	}
+	for ix := range n {
+		tuple := make([]string, 0, len(sources))
+		for _, s := range sources {
+			tuple = append(tuple, s[ix])
+		}
+		tuples = append(tuples, tuple)
+	}
+
+	return
+}
+
+// readLines returns the lines of the named file, or os.Stdin if name is "-".
+func readLines(name string) ([]string, error) {
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		return nil, errors.New("parallelcli: No arguments in '" + name + "'")
+	}
+
+	return lines, nil
+}
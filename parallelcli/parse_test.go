@@ -0,0 +1,61 @@
+package parallelcli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	dir := t.TempDir()
+	f1 := filepath.Join(dir, "f1")
+	os.WriteFile(f1, []byte("x\ny\n"), 0644)
+	empty := filepath.Join(dir, "empty")
+	os.WriteFile(empty, nil, 0644)
+
+	testCases := []struct {
+		args    string
+		command []string
+		jobs    [][]string
+		err     string
+	}{
+		{"wc -l ::: a b", []string{"wc", "-l"}, [][]string{{"a"}, {"b"}}, ""},
+		{"echo ::: a b ::: 1 2", []string{"echo"},
+			[][]string{{"a", "1"}, {"a", "2"}, {"b", "1"}, {"b", "2"}}, ""},
+		{"echo ::: a b c :::+ x y z", []string{"echo"},
+			[][]string{{"a", "x"}, {"b", "y"}, {"c", "z"}}, ""},
+		{"echo ::: a b c :::+ x y", []string{"echo"}, [][]string{{"a", "x"}, {"b", "y"}}, ""},
+		{"echo ::: 1 2 ::: a b :::+ x y", []string{"echo"},
+			[][]string{{"1", "a", "x"}, {"1", "b", "y"}, {"2", "a", "x"}, {"2", "b", "y"}}, ""},
+		{"::: a", nil, [][]string{{"a"}}, ""},
+		{"cat :::: " + f1, []string{"cat"}, [][]string{{"x"}, {"y"}}, ""},
+		{"cat ::: a b ::::+ " + f1, []string{"cat"}, [][]string{{"a", "x"}, {"b", "y"}}, ""},
+		{"echo a b", nil, nil, "delimiter not found"},
+		{"echo ::: a :::", nil, nil, "Need at least one argument after ':::'"},
+		{"echo :::+ a", nil, nil, "must follow another input source"},
+		{"cat :::: " + empty, nil, nil, "No arguments"},
+		{"cat :::: " + filepath.Join(dir, "missing"), nil, nil, "missing"},
+	}
+
+	for ix, tc := range testCases {
+		inv, err := Parse(strings.Fields(tc.args))
+		if len(tc.err) > 0 {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Error(ix, "Expected error", tc.err, "got", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error(ix, "Unexpected error", err)
+			continue
+		}
+		if !reflect.DeepEqual(inv.Command, tc.command) {
+			t.Error(ix, "Wrong command", inv.Command)
+		}
+		if !reflect.DeepEqual(inv.Jobs, tc.jobs) {
+			t.Error(ix, "Wrong jobs", inv.Jobs)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package parallelcli
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Template is a command containing GNU parallel style replacement strings which is
+// expanded for each job with [Template.Expand]. The supported replacement strings are:
+//
+//	{}    The job arguments
+//	{.}   The job arguments without their extensions
+//	{/}   The basename of the job arguments
+//	{//}  The directory name of the job arguments
+//	{/.}  The basename of the job arguments without their extensions
+//	{#}   The job sequence number, starting at 1
+//	{N}   The N'th job argument, starting at 1, such as from the N'th input source
+//
+// Where a job has multiple arguments, the replacements which apply to the job arguments
+// apply to each argument and the results are joined with a space. Replacement strings can
+// appear anywhere within a word, such as “out/{/.}.gz”. Anything else within braces is
+// left unchanged.
+type Template []string
+
+// Expand returns the command for the job with sequence number seq and arguments args. As
+// with GNU parallel, if the Template contains no replacement strings, args are appended
+// to the command.
+func (tpl Template) Expand(seq int, args ...string) []string {
+	cmd := make([]string, 0, len(tpl)+len(args))
+	replaced := false
+	for _, word := range tpl {
+		w, found := expandWord(word, seq, args)
+		replaced = replaced || found
+		cmd = append(cmd, w)
+	}
+	if !replaced {
+		cmd = append(cmd, args...)
+	}
+
+	return cmd
+}
+
+// Command returns an [exec.Cmd] of the expanded Template which is suitable for supplying
+// to [parallel.Group.AddCommand].
+func (tpl Template) Command(seq int, args ...string) *exec.Cmd {
+	cmd := tpl.Expand(seq, args...)
+
+	return exec.Command(cmd[0], cmd[1:]...)
+}
+
+// expandWord replaces all replacement strings in word. It returns true if any were found.
+func expandWord(word string, seq int, args []string) (string, bool) {
+	var b strings.Builder
+	found := false
+	for {
+		start := strings.IndexByte(word, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(word[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		value, ok := replacement(word[start+1:end], seq, args)
+		if !ok {
+			b.WriteString(word[:start+1]) // Not ours so move past the brace
+			word = word[start+1:]
+			continue
+		}
+		found = true
+		b.WriteString(word[:start])
+		b.WriteString(value)
+		word = word[end+1:]
+	}
+	b.WriteString(word)
+
+	return b.String(), found
+}
+
+// replacement returns the value of the replacement string with the braces removed.
+func replacement(name string, seq int, args []string) (string, bool) {
+	var op func(string) string
+	switch name {
+	case "":
+		op = func(s string) string { return s }
+	case ".":
+		op = removeExt
+	case "/":
+		op = filepath.Base
+	case "//":
+		op = filepath.Dir
+	case "/.":
+		op = func(s string) string { return removeExt(filepath.Base(s)) }
+	case "#":
+		return strconv.Itoa(seq), true
+	default:
+		n, err := strconv.Atoi(name)
+		if err != nil || n < 1 || n > len(args) {
+			return "", false
+		}
+		return args[n-1], true
+	}
+
+	values := make([]string, 0, len(args))
+	for _, a := range args {
+		values = append(values, op(a))
+	}
+
+	return strings.Join(values, " "), true
+}
+
+func removeExt(s string) string {
+	return strings.TrimSuffix(s, filepath.Ext(s))
+}
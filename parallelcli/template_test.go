@@ -0,0 +1,44 @@
+package parallelcli
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses slash separated paths")
+	}
+	testCases := []struct {
+		tpl  string
+		seq  int
+		args []string
+		exp  string
+	}{
+		{"wc -l", 1, []string{"a.txt"}, "wc -l a.txt"},
+		{"gzip -c {} >{.}.gz", 1, []string{"dir/a.txt"}, "gzip -c dir/a.txt >dir/a.gz"},
+		{"cp {} out/{/}", 2, []string{"dir/a.txt"}, "cp dir/a.txt out/a.txt"},
+		{"mkdir {//}/{#}", 3, []string{"dir/sub/a.txt"}, "mkdir dir/sub/3"},
+		{"echo {/.}", 1, []string{"dir.d/a.tar.gz"}, "echo a.tar"},
+		{"echo {//}", 1, []string{"a.txt"}, "echo ."},
+		{"echo {2}-{1}", 1, []string{"x", "y"}, "echo y-x"},
+		{"echo {}", 1, []string{"x", "y"}, "echo x y"},
+		{"echo {.}", 1, []string{"x.c", "y.h"}, "echo x y"},
+		{"awk {print} {}", 1, []string{"f"}, "awk {print} f"},
+		{"echo {3} {x", 1, []string{"f"}, "echo {3} {x f"},
+	}
+
+	for ix, tc := range testCases {
+		got := Template(strings.Fields(tc.tpl)).Expand(tc.seq, tc.args...)
+		if joined := strings.Join(got, " "); joined != tc.exp {
+			t.Errorf("%d Got %q expected %q", ix, joined, tc.exp)
+		}
+	}
+
+	cmd := Template{"echo", "{#}"}.Command(7, "a")
+	if !reflect.DeepEqual(cmd.Args, []string{"echo", "7"}) {
+		t.Error("Wrong Command args", cmd.Args)
+	}
+}
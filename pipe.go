@@ -0,0 +1,198 @@
+package parallel
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+)
+
+// maxPipeRecordSize is the largest single record AddPipe accepts. Blocks can be larger as
+// they consist of multiple records.
+const maxPipeRecordSize = 64 * 1024 * 1024
+
+// RunFuncReader is the variant of [RunFunc] which is also supplied an input io.Reader,
+// much like stdin of a command in a shell pipeline. It is added to a Group with
+// [Group.AddPipe]. Apart from the additional io.Reader it has identical semantics to
+// [RunFunc].
+type RunFuncReader func(stdin io.Reader, stdout, stderr io.Writer)
+
+// AddPipe reads r until EOF, splits it into blocks and adds a RunFuncReader for each block
+// with the block supplied as the stdin io.Reader. This mirrors the GNU parallel “--pipe”
+// option where a large input stream is distributed across many commands. All runners
+// share the same outTag and errTag.
+//
+// Input is split into records by split which defaults to [bufio.ScanLines] if nil. Unlike
+// normal [bufio.Scanner] usage, the records are passed on exactly as they were read, so a
+// line record retains its line ending. Records are accumulated until the block contains
+// at least blockSize bytes. If blockSize is less than one, each record is a block. A
+// record is never split across blocks.
+//
+// AddPipe returns any error from reading or splitting r. Blocks read prior to the error
+// have already been added. The same calling constraints as [Group.Add] apply for each
+// block added.
+func (grp *Group) AddPipe(r io.Reader, blockSize int, split bufio.SplitFunc,
+	outTag, errTag string, rFunc RunFuncReader) error {
+	if split == nil {
+		split = bufio.ScanLines
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxPipeRecordSize)
+	scanner.Split(rawRecords(split))
+
+	var block []byte
+	addBlock := func() {
+		stdin := block
+		block = nil
+		grp.add(outTag, errTag,
+			func(_ context.Context, stdout, stderr io.Writer) error {
+				rFunc(bytes.NewReader(stdin), stdout, stderr)
+				return nil
+			}, nil)
+	}
+
+	for scanner.Scan() {
+		block = append(block, scanner.Bytes()...)
+		if len(block) >= blockSize {
+			addBlock()
+		}
+	}
+	if len(block) > 0 {
+		addBlock()
+	}
+
+	return scanner.Err()
+}
+
+// rawRecords adapts split so the token returned is all the data consumed by split rather
+// than the token split returns. This preserves delimiters such as line endings.
+func rawRecords(split bufio.SplitFunc) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		advance, token, err = split(data, atEOF)
+		if advance > 0 {
+			token = data[:advance]
+		}
+
+		return
+	}
+}
+
+// Opener returns the input io.ReadCloser for a runner added with [Group.AddReader].
+type Opener func() (io.ReadCloser, error)
+
+// OpenFile returns an [Opener] which opens the named file.
+func OpenFile(name string) Opener {
+	return func() (io.ReadCloser, error) {
+		return os.Open(name)
+	}
+}
+
+// AddReader is identical to [Group.Add] except that the RunFuncReader is supplied an input
+// io.Reader which is managed by the Group. The Group calls open just before the
+// RunFuncReader is called and closes the returned io.ReadCloser once it returns. Opening
+// lazily means that a Group of many runners, each reading a file, only has as many files
+// open as there are active runners, which is usually constrained by
+// [LimitActiveRunners].
+//
+// If open returns an error the RunFuncReader is not called and the error is recorded
+// against the runner. Similarly, an error returned by Close is recorded against the
+// runner. See [Group.Errors].
+func (grp *Group) AddReader(outTag, errTag string, open Opener, rFunc RunFuncReader,
+	opts ...RunnerOption) {
+	grp.add(outTag, errTag,
+		func(_ context.Context, stdout, stderr io.Writer) error {
+			stdin, err := open()
+			if err != nil {
+				return err
+			}
+			closed := false
+			defer func() { // In case rFunc panics
+				if !closed {
+					stdin.Close()
+				}
+			}()
+			rFunc(stdin, stdout, stderr)
+			closed = true
+
+			return stdin.Close()
+		}, opts)
+}
+
+// AddPipePart splits the first size bytes of r into parts byte ranges and adds a
+// RunFuncReader for each range with an [io.SectionReader] of the range supplied as the
+// stdin io.Reader. This mirrors the GNU parallel “--pipepart” option which enables
+// parallel processing of a single large file while, with the default OrderRunners(true),
+// the output remains in the order of the input. All runners share the same outTag and
+// errTag. Typically r is an [os.File] which must remain open until [Group.Wait] returns:
+//
+//	f, _ := os.Open(name)
+//	defer f.Close()
+//	fi, _ := f.Stat()
+//	err := group.AddPipePart(f, fi.Size(), runtime.NumCPU(), '\n', "", "", process)
+//
+// Each range ends immediately after a recEnd byte so that a record is never split across
+// ranges. Ranges are of roughly equal size, but as a range must end on a record boundary,
+// fewer than parts ranges are added if records are large compared to size/parts.
+//
+// AddPipePart returns any error from reading r while locating the record boundaries, in
+// which case no runners are added. The same calling constraints as [Group.Add] apply for
+// each range added.
+func (grp *Group) AddPipePart(r io.ReaderAt, size int64, parts int, recEnd byte,
+	outTag, errTag string, rFunc RunFuncReader, opts ...RunnerOption) error {
+	bounds, err := partBoundaries(r, size, max(1, parts), recEnd)
+	if err != nil {
+		return err
+	}
+
+	var start int64
+	for _, end := range bounds {
+		section := io.NewSectionReader(r, start, end-start)
+		start = end
+		grp.add(outTag, errTag,
+			func(_ context.Context, stdout, stderr io.Writer) error {
+				rFunc(section, stdout, stderr)
+				return nil
+			}, opts)
+	}
+
+	return nil
+}
+
+// partBoundaries returns the end offset of each range. Each nominal boundary is advanced
+// to just past the next recEnd, so large records may consume more than one nominal range.
+func partBoundaries(r io.ReaderAt, size int64, parts int, recEnd byte) ([]int64, error) {
+	var bounds []int64
+	var prev int64
+	buf := make([]byte, 4096)
+	for k := 1; k < parts; k++ {
+		pos := max(prev+1, size*int64(k)/int64(parts)) // Ranges are never empty
+
+		// Scan for the end of the record containing pos-1
+		for pos < size {
+			n, err := r.ReadAt(buf[:min(int64(len(buf)), size-pos+1)], pos-1)
+			if ix := bytes.IndexByte(buf[:n], recEnd); ix >= 0 {
+				pos += int64(ix)
+				break
+			}
+			pos += int64(n)
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			if n == 0 {
+				pos = size
+			}
+		}
+		if pos >= size {
+			break
+		}
+		bounds = append(bounds, pos)
+		prev = pos
+	}
+	if size > prev {
+		bounds = append(bounds, size)
+	}
+
+	return bounds, nil
+}
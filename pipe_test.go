@@ -0,0 +1,166 @@
+package parallel
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAddPipe(t *testing.T) {
+	testCases := []struct {
+		blockSize int
+		split     bufio.SplitFunc
+		input     string
+		expect    string
+	}{
+		{0, nil, "a\nb\r\nc", "<a\n><b\r\n><c>"},
+		{4, nil, "a\nb\nc\nd\ne\n", "<a\nb\n><c\nd\n><e\n>"},
+		{3, nil, "aaaa\nb\n", "<aaaa\n><b\n>"}, // Records are never split
+		{4, bufio.ScanWords, "ab cd ef", "<ab cd ><ef>"},
+		{0, nil, "", ""},
+	}
+
+	for ix, tc := range testCases {
+		var stdout bytes.Buffer
+		grp, err := NewGroup(WithStdout(&stdout))
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		err = grp.AddPipe(strings.NewReader(tc.input), tc.blockSize, tc.split, "", "",
+			func(stdin io.Reader, stdout, stderr io.Writer) {
+				stdout.Write([]byte("<"))
+				io.Copy(stdout, stdin)
+				stdout.Write([]byte(">"))
+			})
+		if err != nil {
+			t.Error(ix, "Unexpected AddPipe error", err)
+		}
+		grp.Run()
+		grp.Wait()
+		if stdout.String() != tc.expect {
+			t.Errorf("%d expected %q, got %q", ix, tc.expect, stdout.String())
+		}
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("read failed") }
+
+func TestAddPipeError(t *testing.T) {
+	grp, _ := NewGroup()
+	err := grp.AddPipe(errReader{}, 0, nil, "", "",
+		func(stdin io.Reader, stdout, stderr io.Writer) {})
+	if err == nil {
+		t.Error("Expected read error from AddPipe")
+	}
+}
+
+type testReadCloser struct {
+	io.Reader
+	closed int
+}
+
+func (trc *testReadCloser) Close() error {
+	trc.closed++
+	return nil
+}
+
+func TestAddReader(t *testing.T) {
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+
+	trc := &testReadCloser{Reader: strings.NewReader("hello\n")}
+	opened := false
+	grp.AddReader("a: ", "", func() (io.ReadCloser, error) {
+		opened = true
+		return trc, nil
+	}, func(stdin io.Reader, stdout, stderr io.Writer) {
+		io.Copy(stdout, stdin)
+	})
+	grp.AddReader("b: ", "", OpenFile("/nonexistent/file"),
+		func(stdin io.Reader, stdout, stderr io.Writer) {
+			t.Error("RunFuncReader should not be called when open fails")
+		})
+
+	if opened {
+		t.Error("Reader should not be opened until the runner starts")
+	}
+	grp.Run()
+	grp.Wait()
+
+	if stdout.String() != "a: hello\n" {
+		t.Errorf("Wrong output %q", stdout.String())
+	}
+	if trc.closed != 1 {
+		t.Error("Reader should be closed exactly once, not", trc.closed)
+	}
+	errs := grp.Errors()
+	if errs[0] != nil || errs[1] == nil {
+		t.Error("Expected open error on second runner only", errs)
+	}
+}
+
+func TestAddPipePart(t *testing.T) {
+	var input strings.Builder
+	for ix := range 1000 {
+		input.WriteString(strings.Repeat("x", ix%37) + "\n")
+	}
+	data := input.String()
+
+	for _, parts := range []int{0, 1, 2, 7, 64, 5000} {
+		var stdout bytes.Buffer
+		grp, err := NewGroup(WithStdout(&stdout))
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		err = grp.AddPipePart(strings.NewReader(data), int64(len(data)), parts, '\n', "", "",
+			func(stdin io.Reader, out, err io.Writer) {
+				b, _ := io.ReadAll(stdin)
+				if len(b) == 0 || b[len(b)-1] != '\n' {
+					t.Error(parts, "Range does not end on a record boundary", len(b))
+				}
+				out.Write(b)
+			})
+		if err != nil {
+			t.Fatal(parts, "Unexpected error", err)
+		}
+		count := len(grp.all)
+		grp.Run()
+		grp.Wait()
+		if stdout.String() != data {
+			t.Error(parts, "Output does not match input")
+		}
+		if count < 1 || count > max(1, parts) {
+			t.Error(parts, "Wrong number of ranges", count)
+		}
+	}
+}
+
+func TestPartBoundaries(t *testing.T) {
+	data := "aaaaaaaaaa\nb\nc" // One large record then two small ones
+	bounds, err := partBoundaries(strings.NewReader(data), int64(len(data)), 4, '\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bounds) != 3 || bounds[0] != 11 || bounds[1] != 13 || bounds[2] != 14 {
+		t.Error("Wrong boundaries", bounds)
+	}
+
+	_, err = partBoundaries(errReaderAt{}, 100, 2, '\n')
+	if err == nil {
+		t.Error("Expected ReadAt error")
+	}
+}
+
+type errReaderAt struct{}
+
+func (errReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	return 0, errors.New("ReadAt failed")
+}
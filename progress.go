@@ -0,0 +1,105 @@
+package parallel
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const progressInterval = time.Second / 2
+
+// progress periodically renders a single status line showing how many runners have
+// completed, are active and are pending along with elapsed time and an ETA. The status
+// line is rewritten in place by way of a leading carriage return. The line is written
+// under the protection of the Group output mutex so that it never splits a Write
+// made by a runner pipeline.
+//
+// All counters are atomic as they are updated by workers and Group.Add concurrently with
+// the rendering goroutine.
+type progress struct {
+	w        io.Writer
+	outputMu *sync.Mutex
+	clock    Clock
+	start    time.Time
+
+	added     atomic.Int64
+	started   atomic.Int64 // Includes skipped runners
+	completed atomic.Int64 // Includes skipped runners
+
+	stopOnce sync.Once
+	stop     chan struct{} // Closed to stop rendering goroutine
+	done     chan struct{} // Closed by rendering goroutine on exit
+	lastLen  int           // Length of previous line so it can be erased
+}
+
+func newProgress(w io.Writer, clock Clock, outputMu *sync.Mutex, added int) *progress {
+	p := &progress{w: w, clock: clock, outputMu: outputMu, start: clock.Now(),
+		stop: make(chan struct{}), done: make(chan struct{})}
+	p.added.Store(int64(added))
+
+	return p
+}
+
+// run renders the status line every progressInterval until stopped at which time a final
+// status line and newline are rendered.
+func (p *progress) run() {
+	defer close(p.done)
+	timer := p.clock.NewTimer(progressInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C():
+			p.render(false)
+			timer.Reset(progressInterval)
+		case <-p.stop:
+			p.render(true)
+			return
+		}
+	}
+}
+
+// finish stops the rendering goroutine and waits for it to write the final status line.
+func (p *progress) finish() {
+	p.stopOnce.Do(func() { close(p.stop) })
+	<-p.done
+}
+
+func (p *progress) render(final bool) {
+	line := p.format(since(p.clock, p.start))
+	pad := ""
+	if p.lastLen > len(line) { // Erase remnants of a longer previous line
+		pad = strings.Repeat(" ", p.lastLen-len(line))
+	}
+	p.lastLen = len(line)
+	nl := ""
+	if final {
+		nl = "\n"
+	}
+
+	p.outputMu.Lock()
+	defer p.outputMu.Unlock()
+	io.WriteString(p.w, "\r"+line+pad+nl)
+}
+
+// format returns the status line for the current counters. The ETA is a simple linear
+// extrapolation of the average completion time.
+func (p *progress) format(elapsed time.Duration) string {
+	added := p.added.Load()
+	started := p.started.Load()
+	completed := p.completed.Load()
+	active := started - completed
+	pending := added - started
+
+	line := fmt.Sprintf("parallel: %d/%d done, %d active, %d pending, %s elapsed",
+		completed, added, active, pending, elapsed.Round(time.Second))
+	if completed > 0 && completed < added {
+		eta := elapsed / time.Duration(completed) * time.Duration(added-completed)
+		line += ", ETA " + eta.Round(time.Second).String()
+	}
+
+	return line
+}
@@ -0,0 +1,70 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressFormat(t *testing.T) {
+	p := newProgress(io.Discard, systemClock{}, nil, 10)
+	p.started.Store(6)
+	p.completed.Store(4)
+
+	got := p.format(time.Second * 8)
+	expect := "parallel: 4/10 done, 2 active, 4 pending, 8s elapsed, ETA 12s"
+	if got != expect {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+
+	p.completed.Store(0)
+	got = p.format(time.Second)
+	if strings.Contains(got, "ETA") {
+		t.Error("ETA should not be shown before any completions", got)
+	}
+}
+
+// Test that the final status line is written by Wait.
+func TestProgressGroup(t *testing.T) {
+	var stdout, progress bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithProgress(&progress))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	for ix := 0; ix < 3; ix++ {
+		grp.Add("", "", func(out, err io.Writer) {})
+	}
+	grp.Run()
+	grp.Wait()
+
+	got := progress.String()
+	if !strings.HasPrefix(got, "\r") || !strings.HasSuffix(got, "\n") {
+		t.Errorf("Progress should start with CR and end with NL: %q", got)
+	}
+	if !strings.Contains(got, "3/3 done, 0 active, 0 pending") {
+		t.Errorf("Final progress line is wrong: %q", got)
+	}
+
+	_, err = NewGroup(WithProgress(nil))
+	if err == nil {
+		t.Error("Expected error from WithProgress(nil)")
+	}
+}
+
+// Progress is not written to a non-terminal in TTYAuto mode.
+func TestProgressMode(t *testing.T) {
+	var progress bytes.Buffer
+	grp, err := NewGroup(WithStdout(io.Discard), WithProgress(&progress),
+		WithProgressMode(TTYAuto))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("", "", func(out, err io.Writer) {})
+	grp.Run()
+	grp.Wait()
+	if progress.Len() > 0 {
+		t.Errorf("Progress should not be written to a non-terminal: %q", progress.String())
+	}
+}
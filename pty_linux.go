@@ -0,0 +1,85 @@
+package parallel
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// opost enables output post-processing, such as mapping NL to CR-NL, in termios.Oflag. It
+// has the same value on all Linux architectures but is not defined by syscall.
+const opost = 0x1
+
+// startPTY allocates a pseudo-terminal, connects it to the stdout and stderr of cmd as its
+// controlling terminal and starts cmd. The returned master is read to obtain the command
+// output and must be closed by the caller.
+func startPTY(cmd *exec.Cmd) (*os.File, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, err
+	}
+	var n uint32
+	var unlock int32
+	err = ioctl(master, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock))
+	if err == nil {
+		err = ioctl(master, syscall.TIOCGPTN, unsafe.Pointer(&n))
+	}
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	slave, err := os.OpenFile("/dev/pts/"+strconv.Itoa(int(n)), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+	defer slave.Close() // Only the command retains the slave so master sees EIO when it exits
+
+	var termios syscall.Termios
+	err = ioctl(slave, syscall.TCGETS, unsafe.Pointer(&termios))
+	if err == nil {
+		termios.Oflag &^= opost
+		err = ioctl(slave, syscall.TCSETS, unsafe.Pointer(&termios))
+	}
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	cmd.Stdout, cmd.Stderr = slave, slave
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+	cmd.SysProcAttr.Ctty = 1 // The child's stdout
+	if err := cmd.Start(); err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	return master, nil
+}
+
+// ioctl issues a pointer-based ioctl request against f.
+func ioctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errno syscall.Errno
+	err = rc.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg))
+	})
+	if err != nil {
+		return err
+	}
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package parallel
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// startPTY always fails as pseudo-terminals are only supported on Linux.
+func startPTY(cmd *exec.Cmd) (*os.File, error) {
+	return nil, errors.New("parallel: pseudo-terminals are not supported on " + runtime.GOOS)
+}
@@ -2,7 +2,10 @@ package parallel
 
 import (
 	"io"
+	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type destination int
@@ -74,9 +77,20 @@ type commonQueue struct {
 	limit        uint64 // LimitMemoryPerRunner
 	out, err     writer
 
-	used  uint64   // LimitMemoryPerRunner
-	block chan any // Writers block here in overQuota state
-	buf   chunkBuffer
+	used    uint64            // LimitMemoryPerRunner
+	block   chan any          // Writers block here in overQuota state
+	onBlock func(used uint64) // Optionally called when a Write blocks
+	buf     chunkBuffer
+
+	soft       uint64            // SoftLimitMemoryPerRunner - zero if not set
+	maxDelay   time.Duration     // Delay applied as used approaches limit
+	clock      Clock             // Times the throttle delay
+	throttled  bool              // If used has exceeded soft
+	onThrottle func(used uint64) // Optionally called when used first exceeds soft
+
+	drainErr error // First downstream error when draining
+
+	queued, drained, blocks atomic.Int64 // See Group.Snapshot
 }
 
 // Create two writers which share all state via a commonQueue
@@ -84,6 +98,7 @@ func newQueue(orderStderr bool, limit uint64, out, err writer) (stdout, stderr *
 	cq := &commonQueue{state: backgroundWithLimit, orderStderr: orderStderr,
 		limit: limit,
 		out:   out, err: err,
+		clock: systemClock{},
 		block: make(chan any)}
 
 	if cq.limit == 0 {
@@ -120,7 +135,22 @@ func (wtr *queue) Write(p []byte) (n int, err error) {
 	case backgroundWithLimit:
 		if (wtr.cq.used + uint64(len(p))) <= wtr.cq.limit { // Over the limit?
 			n, err = wtr.cq.buf.write(wtr.where, p)
+			wtr.cq.queued.Add(int64(n))
 			wtr.cq.used += uint64(n)
+			wtr.cq.used -= min(wtr.cq.buf.takeSaved(), wtr.cq.used)
+			wtr.cq.throttle() // Unlocks
+			break
+		}
+
+		if len(wtr.cq.buf.spillDir) > 0 { // Spill rather than block
+			n, err = wtr.cq.buf.spillWrite(wtr.where, p)
+			if err == nil {
+				wtr.cq.queued.Add(int64(n))
+				wtr.cq.throttle() // Unlocks
+				break
+			}
+			// Blocking could stall forever as the conflict checks are relaxed when
+			// spilling, so return the error instead.
 			wtr.cq.Unlock()
 			break
 		}
@@ -129,12 +159,18 @@ func (wtr *queue) Write(p []byte) (n int, err error) {
 		fallthrough // FALLTHRU
 
 	case blocked:
+		onBlock, used := wtr.cq.onBlock, wtr.cq.used
+		wtr.cq.blocks.Add(1)
 		wtr.cq.Unlock()
+		if onBlock != nil {
+			onBlock(used)
+		}
 		<-wtr.cq.block // Can only come off here when state == foreground
 		n, err = wtr.out.Write(p)
 
 	case backgroundNoLimit:
 		n, err = wtr.cq.buf.write(wtr.where, p)
+		wtr.cq.queued.Add(int64(n))
 		wtr.cq.Unlock()
 
 	case foreground:
@@ -148,23 +184,61 @@ func (wtr *queue) Write(p []byte) (n int, err error) {
 	return
 }
 
+// throttle delays the caller if usage exceeds the SoftLimitMemoryPerRunner watermark. The
+// delay grows linearly from zero at the soft watermark to maxDelay at the limit so that
+// a chatty RunFunc is slowed progressively rather than being stopped dead at the
+// limit. The delay ends early if the queue switches to foreground. Caller must hold the
+// mutex which is released by throttle.
+func (cq *commonQueue) throttle() {
+	if cq.soft == 0 || cq.used <= cq.soft {
+		cq.Unlock()
+		return
+	}
+	fraction := float64(cq.used-cq.soft) / float64(cq.limit-cq.soft)
+	delay := time.Duration(float64(cq.maxDelay) * min(fraction, 1))
+	notify := !cq.throttled
+	cq.throttled = true
+	onThrottle, used, block := cq.onThrottle, cq.used, cq.block
+	cq.Unlock()
+
+	if notify && onThrottle != nil {
+		onThrottle(used)
+	}
+	timer := cq.clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+	case <-block: // No need to throttle once in foreground
+	}
+}
+
 // Returns total length of queued writes. Concurrency safe.
 func (cq *commonQueue) len() (outLen, errLen int) {
 	cq.Lock()
 	defer cq.Unlock()
 
 	for _, b := range cq.buf.chunks {
+		l := int(b.len())
 		switch b.where {
 		case toStdout:
-			outLen += len(b.data)
+			outLen += l
 		case toStderr:
-			errLen += len(b.data)
+			errLen += l
 		}
 	}
 
 	return
 }
 
+// usage returns the number of bytes counted towards LimitMemoryPerRunner and whether a
+// Write is blocked. Concurrency safe.
+func (cq *commonQueue) usage() (used uint64, isBlocked bool) {
+	cq.Lock()
+	defer cq.Unlock()
+
+	return cq.used, cq.state == blocked
+}
+
 func (wtr *queue) close() {
 	wtr.foreground()
 	wtr.out.close() // Pass it on
@@ -179,32 +253,77 @@ func (wtr *queue) close() {
 // time to finish, depending on what downstream is doing, but there is no need to return
 // control in a hurry as [Group.Wait] cannot do anything useful until this transition has
 // completed anyway. IOWs, there is no good reason to start a separate goroutine for this.
-func (wtr *queue) foreground() {
+//
+// foreground returns true if this call made the switch. Any downstream write error
+// detected while draining is retained in drainErr.
+func (wtr *queue) foreground() (switched bool) {
 	wtr.cq.Lock()
 	defer wtr.cq.Unlock()
 
 	if wtr.cq.state == foreground {
-		return
+		return false
 	}
 
 	wtr.cq.state = draining // This ephemeral state should never be visible inside the mutex
-	wtr.cq.buf.drain(wtr.cq.orderStderr, wtr.cq.out, wtr.cq.err)
+	var queued int64
+	for _, b := range wtr.cq.buf.chunks {
+		queued += b.len()
+	}
+	wtr.cq.drainErr = wtr.cq.buf.drain(wtr.cq.orderStderr, wtr.cq.out, wtr.cq.err)
+	wtr.cq.drained.Add(queued)
 	wtr.cq.state = foreground
 	close(wtr.cq.block) // Free up all blocked Writer() callers
+
+	return true
+}
+
+// isForeground returns true once the queue has switched to foreground.
+func (wtr *queue) isForeground() bool {
+	wtr.cq.Lock()
+	defer wtr.cq.Unlock()
+
+	return wtr.cq.state == foreground
 }
 
-// chunk contains the data for a single Write call
+// chunk contains the data for a single Write call. If the chunk has been spilled to disk,
+// data is nil and the chunk is located at offset in the spill file. If the chunk has been
+// compressed, data contains the compressed form. In both cases size is the original
+// length of the data.
 type chunk struct {
-	where destination
-	data  []byte
+	where      destination
+	data       []byte
+	spilled    bool
+	compressed bool
+	offset     int64
+	size       int64
+}
+
+// len returns the original length of the chunk data.
+func (b *chunk) len() int64 {
+	if b.spilled || b.compressed {
+		return b.size
+	}
+
+	return int64(len(b.data))
 }
 
 // chunkBuffer contains all Write() data in arrival order. It provides the ability to
 // transfer the writes in the same order by way of iterating thru getChunks()
 //
+// If spillDir is set, chunks which would otherwise exceed [LimitMemoryPerRunner] are
+// written to a temporary file in that directory rather than blocking the writer. If
+// compress is set, chunks are compressed once they can no longer be coalesced.
+//
 // All callers to chunkBuffer must provide concurrency protection.
 type chunkBuffer struct {
-	chunks []chunk
+	chunks   []chunk
+	coalesce int      // Max size of a coalesced chunk. Zero means never coalesce
+	spillDir string   // Empty means no spilling
+	spill    *os.File // Created on first spill
+	spillEnd int64    // Offset of the next spilled chunk
+	compress bool     // WithCompressedBuffering
+	expected int      // WithExpectedOutputSize - zero if unknown
+	saved    uint64   // Bytes saved by compression since last takeSaved
 }
 
 // write appends the supplied bytes to the chunkBuffer. It is normally called as a
@@ -213,53 +332,181 @@ type chunkBuffer struct {
 // the data is no longer needed or immutable. This is also needed as the parent caller is
 // Write() and the io.Writer documentation clearly states that "Implementations must not
 // retain p".
+//
+// If the previous chunk has the same destination and there's room within the coalesce
+// limit, p is appended to the previous chunk rather than creating a new chunk. This stops
+// runners which write a byte at a time from creating vast numbers of tiny chunks.
 func (buf *chunkBuffer) write(where destination, p []byte) (n int, err error) {
-	b := chunk{where: where, data: make([]byte, len(p))}
+	if last := len(buf.chunks) - 1; last >= 0 {
+		b := &buf.chunks[last]
+		newLen := len(b.data) + len(p)
+		if b.where == where && !b.spilled && newLen <= buf.coalesce {
+			if newLen > cap(b.data) { // Move to a bigger buffer
+				data := getBuf(newLen)[:len(b.data)]
+				copy(data, b.data)
+				putBuf(b.data)
+				b.data = data
+			}
+			b.data = append(b.data, p...)
+
+			return len(p), nil
+		}
+	}
+
+	buf.seal() // The previous chunk can no longer be coalesced
+	size := len(p)
+	if len(buf.chunks) == 0 && buf.expected > 0 {
+		size = buf.presize(size)
+	}
+	b := chunk{where: where, data: getBuf(size)[:len(p)]}
 	copy(b.data, p) // Do not retain p
 	buf.chunks = append(buf.chunks, b)
 
 	return len(p), nil
 }
 
-// Transfer all chunks to downstream writers in configured order
-func (buf *chunkBuffer) drain(orderStderr bool, out, err io.Writer) {
+// presize allocates the chunks slice for the expected output size and returns the
+// capacity to allocate for the first chunk of n bytes. The first chunk is sized to absorb
+// as much of the expected output as coalescing allows.
+func (buf *chunkBuffer) presize(n int) int {
+	if buf.chunks == nil && buf.coalesce > 0 { // Otherwise the chunk count is unknowable
+		buf.chunks = make([]chunk, 0, buf.expected/buf.coalesce+1)
+	}
+
+	return max(n, min(buf.expected, buf.coalesce))
+}
+
+// spillWrite appends the supplied bytes to the spill file, creating it if need be. If the
+// write fails, the spill file is truncated back to its previous size so that the caller
+// can fall back to other strategies.
+func (buf *chunkBuffer) spillWrite(where destination, p []byte) (n int, err error) {
+	if buf.spill == nil {
+		buf.spill, err = os.CreateTemp(buf.spillDir, "parallel-spill-*")
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err = buf.spill.Write(p)
+	if err != nil {
+		buf.spill.Truncate(buf.spillEnd)
+		buf.spill.Seek(buf.spillEnd, io.SeekStart)
+		return 0, err
+	}
+
+	buf.chunks = append(buf.chunks,
+		chunk{where: where, spilled: true, offset: buf.spillEnd, size: int64(n)})
+	buf.spillEnd += int64(n)
+
+	return
+}
+
+// Transfer all chunks to downstream writers in configured order. Chunk data is recycled
+// once transferred as downstream writers must not retain it. Any spill file is removed
+// once all chunks have been transferred. The first transfer error is returned.
+func (buf *chunkBuffer) drain(orderStderr bool, out, err io.Writer) (e error) {
 	if orderStderr {
-		buf.transfer(out, nil)
-		buf.transfer(nil, err)
+		e = buf.transfer(out, nil)
+		if e2 := buf.transfer(nil, err); e == nil {
+			e = e2
+		}
 	} else {
-		buf.transfer(out, err)
+		e = buf.transfer(out, err)
+	}
+	for _, b := range buf.chunks {
+		if !b.compressed { // Compressed data does not come from the pool
+			putBuf(b.data)
+		}
 	}
 	buf.chunks = []chunk{} // Release to GC and empty slice
+	if buf.spill != nil {
+		buf.spill.Close()
+		os.Remove(buf.spill.Name())
+		buf.spill = nil
+		buf.spillEnd = 0
+	}
+
+	return
+}
+
+// writeChunk writes a single chunk to the io.Writer, reading it back from the spill file
+// if need be.
+func (buf *chunkBuffer) writeChunk(w io.Writer, b chunk) (err error) {
+	if b.compressed {
+		return writeCompressed(w, b)
+	}
+	if !b.spilled {
+		_, err = w.Write(b.data)
+		return
+	}
+
+	_, err = io.Copy(w, io.NewSectionReader(buf.spill, b.offset, b.size))
+
+	return
 }
 
 // transfer all chunks to the downstream writers if present. Caller is responsible for
 // clearing the chunks so that they are not written more than once. If a downstream
 // Write() fails the transfer stops for that io.Writer and that error is returned if it is
-// the first error detected. As it stands, current callers ignore the returned error as
-// there is no mechanism to pass it back on up to the application due to this function
-// being called asynchronously (typically by parallel.Wait()).
+// the first error detected. The error is retained by the queue as this function is called
+// asynchronously (typically by parallel.Wait()) rather than by the application.
+//
+// Consecutive in-memory chunks for the same destination are gathered and written with a
+// single vectored write if the downstream writer supports it.
 func (buf *chunkBuffer) transfer(stdout, stderr io.Writer) (err error) {
+	var vec [][]byte // Gathered chunk data awaiting a vectored write
+	var vecWhere destination
+
+	fail := func(where destination, e error) {
+		if err == nil { // First error detected?
+			err = e
+		}
+		if where == toStdout { // Do not write to this io.Writer any more
+			stdout = nil
+		} else {
+			stderr = nil
+		}
+	}
+	flush := func() {
+		if len(vec) == 0 {
+			return
+		}
+		w := stdout
+		if vecWhere == toStderr {
+			w = stderr
+		}
+		if _, e := w.(vectorWriter).writeVec(vec); e != nil {
+			fail(vecWhere, e)
+		}
+		vec = vec[:0]
+	}
+
 	for _, b := range buf.chunks {
-		switch {
-		case b.where == toStdout && stdout != nil:
-			_, e := stdout.Write(b.data)
-			if e != nil {
-				if err == nil { // First error detected?
-					err = e
-				}
-				stdout = nil // Do not write to this io.Writer any more
-			}
+		w := stdout
+		if b.where == toStderr {
+			w = stderr
+		}
+		if w == nil {
+			continue
+		}
+		if len(vec) > 0 && vecWhere != b.where {
+			flush() // Preserve the order of writes across destinations
+		}
+		if _, ok := w.(vectorWriter); ok && !b.spilled && !b.compressed {
+			vec = append(vec, b.data)
+			vecWhere = b.where
+			continue
+		}
 
-		case b.where == toStderr && stderr != nil:
-			_, e := stderr.Write(b.data)
-			if e != nil {
-				if err == nil { // First error detected?
-					err = e
-				}
-				stderr = nil // Do not write to this io.Writer any more
-			}
+		flush()
+		if (b.where == toStdout && stdout == nil) || (b.where == toStderr && stderr == nil) {
+			continue // The flush failed on this io.Writer
+		}
+		if e := buf.writeChunk(w, b); e != nil {
+			fail(b.where, e)
 		}
 	}
+	flush()
 
 	return
 }
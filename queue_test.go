@@ -1,7 +1,10 @@
 package parallel
 
 import (
+	"bytes"
 	"errors"
+	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -202,3 +205,169 @@ func TestQueueTransferErr(t *testing.T) {
 		t.Error("Expected stderr to be 'ABC', not", res)
 	}
 }
+
+// Test that a queue with a spill directory spills rather than blocks once over the limit
+// and that the spill file is removed after draining.
+func TestQueueSpill(t *testing.T) {
+	dir := t.TempDir()
+	ob := &testBufWriter{}
+	eb := &testBufWriter{}
+	outQ, errQ := newQueue(false, 10, ob, eb)
+	outQ.cq.buf.spillDir = dir
+
+	outQ.Write([]byte("0123456789")) // Fits in memory
+	errQ.Write([]byte("abcdefghij")) // Spilled
+	outQ.Write([]byte("ABCDEFGHIJ")) // Spilled
+	errQ.Write([]byte("xyz"))        // Spilled
+
+	if outQ.cq.state != backgroundWithLimit {
+		t.Error("Queue should not have blocked, state is", outQ.cq.state)
+	}
+	if outQ.cq.used != 10 {
+		t.Error("Spilled data should not count towards used, not", outQ.cq.used)
+	}
+	ol, el := outQ.cq.len()
+	if ol != 20 || el != 13 {
+		t.Error("Queued lengths should include spilled data, not", ol, el)
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatal("Expected one spill file, not", len(files))
+	}
+
+	outQ.foreground()
+	if ob.String() != "0123456789ABCDEFGHIJ" {
+		t.Error("stdout corrupted by spill", ob.String())
+	}
+	if eb.String() != "abcdefghijxyz" {
+		t.Error("stderr corrupted by spill", eb.String())
+	}
+	files, _ = os.ReadDir(dir)
+	if len(files) != 0 {
+		t.Error("Spill file should be removed after drain", len(files))
+	}
+}
+
+// Test that consecutive writes to the same destination are coalesced.
+func TestQueueCoalesce(t *testing.T) {
+	ob := &testBufWriter{}
+	outQ, errQ := newQueue(false, 0, ob, ob)
+	outQ.cq.buf.coalesce = 8
+
+	for _, c := range "abcdefghij" { // Byte at a time: 8 + 2
+		outQ.Write([]byte(string(c)))
+	}
+	errQ.Write([]byte("E"))     // Different destination means a new chunk
+	outQ.Write([]byte("k"))     // As does switching back
+	outQ.Write(make([]byte, 9)) // Too big to coalesce
+
+	if len(outQ.cq.buf.chunks) != 5 {
+		t.Error("Expected 5 chunks, got", len(outQ.cq.buf.chunks))
+	}
+	outQ.foreground()
+	expect := "abcdefghijEk" + string(make([]byte, 9))
+	if ob.String() != expect {
+		t.Errorf("Coalesced output corrupted %q", ob.String())
+	}
+}
+
+// Test that WithExpectedOutputSize pre-sizes the chunks and the first chunk
+func TestQueuePresize(t *testing.T) {
+	ob := &testBufWriter{}
+	outQ, _ := newQueue(false, 0, ob, ob)
+	outQ.cq.buf.coalesce = 1024
+	outQ.cq.buf.expected = 10000
+
+	outQ.Write([]byte("a"))
+	chunks := outQ.cq.buf.chunks
+	if cap(chunks) != 10 || cap(chunks[0].data) < 1024 {
+		t.Error("Wrong preallocation", cap(chunks), cap(chunks[0].data))
+	}
+	data := chunks[0].data
+	outQ.Write(make([]byte, 1000)) // Coalesced without reallocation
+	if &outQ.cq.buf.chunks[0].data[0] != &data[0] {
+		t.Error("First chunk should not have been reallocated")
+	}
+	outQ.foreground()
+	if ob.Len() != 1001 {
+		t.Error("Wrong output length", ob.Len())
+	}
+}
+
+// Test that sealed chunks are compressed, reduce usage and are restored when drained
+func TestQueueCompress(t *testing.T) {
+	ob := &testBufWriter{}
+	outQ, errQ := newQueue(false, 100000, ob, ob)
+	outQ.cq.buf.coalesce = 1024
+	outQ.cq.buf.compress = true
+
+	line := []byte(strings.Repeat("all work and no play ", 40) + "\n") // 841 bytes
+	var expect bytes.Buffer
+	for ix := range 20 {
+		q := outQ
+		if ix%5 == 4 {
+			q = errQ
+		}
+		q.Write(line)
+		expect.Write(line)
+	}
+	errQ.Write([]byte("tiny")) // Coalesced into the last, unsealed, chunk
+	expect.WriteString("tiny")
+
+	compressed := 0
+	for _, b := range outQ.cq.buf.chunks {
+		if b.compressed {
+			compressed++
+		}
+	}
+	if compressed != 19 { // All but the last chunk is sealed
+		t.Error("Expected 19 compressed chunks, got", compressed)
+	}
+	if used := outQ.cq.used; used >= uint64(expect.Len())/4 {
+		t.Error("Compression should reduce usage, not", used, expect.Len())
+	}
+	if ol, el := outQ.cq.len(); ol+el != expect.Len() {
+		t.Error("len should report original sizes, not", ol, el)
+	}
+
+	outQ.foreground()
+	if ob.String() != expect.String() {
+		t.Error("Compressed output corrupted")
+	}
+}
+
+// Test that Writes above the soft watermark are progressively delayed
+func TestQueueThrottle(t *testing.T) {
+	var outBuf, errBuf testBufWriter
+	outQ, _ := newQueue(false, 100, &outBuf, &errBuf)
+	cq := outQ.cq
+	var notified []uint64
+	cq.soft, cq.maxDelay = 10, 200*time.Millisecond
+	cq.onThrottle = func(used uint64) { notified = append(notified, used) }
+
+	start := time.Now()
+	outQ.Write(make([]byte, 10)) // At, but not above, the soft watermark
+	if time.Since(start) > 50*time.Millisecond || len(notified) > 0 {
+		t.Error("Write below the soft watermark should not be throttled")
+	}
+
+	start = time.Now()
+	outQ.Write(make([]byte, 45)) // Half way to the limit
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Error("Write above the soft watermark should be delayed, not", elapsed)
+	}
+	outQ.Write(make([]byte, 1))
+	if len(notified) != 1 || notified[0] != 55 {
+		t.Error("Expected one notification at 55, not", notified)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		outQ.foreground()
+	}()
+	start = time.Now()
+	outQ.Write(make([]byte, 40)) // Close to the limit
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Error("Foreground should end the delay early, not", elapsed)
+	}
+}
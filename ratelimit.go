@@ -0,0 +1,59 @@
+package parallel
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket, measured in bytes, which throttles the combined output
+// written to the Group io.Writers for WithOutputRateLimit. The bucket holds a tenth of a
+// second of output so that output trickles out smoothly rather than in one second bursts.
+type rateLimiter struct {
+	sync.Mutex
+	rate   float64 // Bytes per second
+	burst  int     // Maximum tokens in the bucket and maximum bytes written at once
+	tokens float64
+	clock  Clock
+	last   time.Time // When tokens was last calculated
+}
+
+func newRateLimiter(bytesPerSec int, clock Clock) *rateLimiter {
+	burst := max(bytesPerSec/10, 1)
+	return &rateLimiter{rate: float64(bytesPerSec), burst: burst, tokens: float64(burst),
+		clock: clock, last: clock.Now()}
+}
+
+// wait consumes n tokens, sleeping until they have accrued if need be. n must not exceed
+// burst.
+func (rl *rateLimiter) wait(n int) {
+	rl.Lock()
+	defer rl.Unlock()
+
+	now := rl.clock.Now()
+	rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+	rl.tokens = min(rl.tokens, float64(rl.burst))
+	rl.last = now
+	rl.tokens -= float64(n)
+	if rl.tokens < 0 { // Sleep off the debt
+		<-rl.clock.After(time.Duration(-rl.tokens / rl.rate * float64(time.Second)))
+		rl.tokens = 0
+		rl.last = rl.clock.Now()
+	}
+}
+
+// write writes p to out in pieces no larger than the burst, waiting for each piece to be
+// allowed by the rate limit.
+func (rl *rateLimiter) write(out func([]byte) (int, error), p []byte) (n int, err error) {
+	for len(p) > 0 {
+		piece := p[:min(len(p), rl.burst)]
+		rl.wait(len(piece))
+		b, err := out(piece)
+		n += b
+		if err != nil {
+			return n, err
+		}
+		p = p[len(piece):]
+	}
+
+	return
+}
@@ -0,0 +1,78 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestOutputRateLimit(t *testing.T) {
+	if _, err := NewGroup(WithOutputRateLimit(0)); err == nil {
+		t.Error("Expected error with zero rate")
+	}
+
+	var stdout testLockedBuffer
+	grp, err := NewGroup(WithStdout(&stdout), WithOutputRateLimit(2000))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	line := append(bytes.Repeat([]byte("x"), 99), '\n')
+	for range 3 {
+		grp.Add("", "", func(out, err io.Writer) {
+			for range 2 {
+				out.Write(line)
+			}
+		})
+	}
+
+	// 600 bytes less a 200 byte burst at 2000 bytes/sec takes at least 200ms
+	start := time.Now()
+	grp.Run()
+	grp.Wait()
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Error("Output was not rate limited. Took", elapsed)
+	}
+	if got := len(stdout.String()); got != 600 {
+		t.Error("Wrong output length", got)
+	}
+}
+
+func TestRateLimiterPieces(t *testing.T) {
+	rl := newRateLimiter(10, systemClock{}) // Burst of one byte
+	var pieces int
+	n, err := rl.write(func(p []byte) (int, error) {
+		pieces++
+		return len(p), nil
+	}, []byte("abc"))
+	if n != 3 || err != nil {
+		t.Error("Unexpected write return", n, err)
+	}
+	if pieces != 3 {
+		t.Error("Expected three pieces, got", pieces)
+	}
+}
+
+// Output copied with io.Copy is subject to the same limit as Writes.
+func TestOutputRateLimitReadFrom(t *testing.T) {
+	var stdout testLockedBuffer
+	grp, err := NewGroup(WithStdout(&stdout), WithOutputRateLimit(2000))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	src := bytes.Repeat([]byte("x"), 600)
+	grp.Add("", "", func(out, err io.Writer) {
+		io.Copy(out, struct{ io.Reader }{bytes.NewReader(src)})
+	})
+
+	// 600 bytes less a 200 byte burst at 2000 bytes/sec takes at least 200ms
+	start := time.Now()
+	grp.Run()
+	grp.Wait()
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Error("Copied output was not rate limited. Took", elapsed)
+	}
+	if got := len(stdout.String()); got != 600 {
+		t.Error("Wrong output length", got)
+	}
+}
@@ -0,0 +1,115 @@
+package parallel
+
+import (
+	"io"
+)
+
+// readFromChunkSize is the size of each read when a writer ingests an io.Reader. It's
+// larger than the io.Copy default to reduce the number of chunks queued for large
+// streams.
+const readFromChunkSize = 64 * 1024
+
+// readFrom copies r to the writer, using the writer's own io.ReaderFrom if it has one,
+// otherwise via a copy loop.
+func readFrom(w writer, r io.Reader) (n int64, err error) {
+	if rf, ok := w.(io.ReaderFrom); ok {
+		return rf.ReadFrom(r)
+	}
+
+	return io.CopyBuffer(struct{ io.Writer }{w}, r, make([]byte, readFromChunkSize))
+}
+
+// ReadFrom allows io.Copy to pass the io.Reader down the pipeline.
+func (wtr *head) ReadFrom(r io.Reader) (n int64, err error) {
+	if lk := wtr.leak.Load(); lk != nil {
+		return io.Copy(lk, r)
+	}
+	n, err = readFrom(wtr.out, r)
+	wtr.written.Add(n)
+	wtr.writes.Add(1) // One call by the RunFunc, however many chunks
+
+	return
+}
+
+// ReadFrom copies r in bounded chunks and only holds the output mutex while each chunk is
+// written. Holding it while reading from r would deadlock a RunFunc which copies from a
+// reader, such as a pipe, which is fed by a goroutine that also writes the other stream.
+func (wtr *tail) ReadFrom(r io.Reader) (n int64, err error) {
+	buf := getBuf(readFromChunkSize)
+	defer putBuf(buf)
+	for {
+		nr, rerr := r.Read(buf)
+		if nr > 0 {
+			nw, werr := wtr.Write(buf[:nr]) // Applies any limit and reports errors
+			n += int64(nw)
+			if werr != nil {
+				return n, werr
+			}
+		}
+		if rerr == io.EOF {
+			return n, nil
+		}
+		if rerr != nil {
+			return n, rerr
+		}
+	}
+}
+
+// ReadFrom reads r in large chunks. While the queue is in background mode without a
+// limit, large reads are queued as-is rather than being copied. Otherwise the data is
+// passed to Write which deals with limits and blocking. Once the queue is in foreground
+// mode, the rest of r is passed downstream.
+//
+// The mutex is not held while reading from r as that could stall a transition to
+// foreground for an arbitrary period.
+func (wtr *queue) ReadFrom(r io.Reader) (n int64, err error) {
+	buf := getBuf(readFromChunkSize)
+	defer func() { putBuf(buf) }() // Only if not retained by the queue
+	for {
+		wtr.cq.Lock()
+		state := wtr.cq.state
+		wtr.cq.Unlock()
+		if state == foreground {
+			m, err := readFrom(wtr.out, r)
+			return n + m, err
+		}
+
+		nr, rerr := r.Read(buf)
+		if nr > 0 {
+			var nw int
+			var werr error
+			if nr >= len(buf)/2 && wtr.queueOwned(buf[:nr]) { // Worth retaining?
+				nw = nr
+				buf = getBuf(readFromChunkSize) // buf now belongs to the queue
+			} else {
+				nw, werr = wtr.Write(buf[:nr])
+			}
+			n += int64(nw)
+			if werr != nil {
+				return n, werr
+			}
+		}
+		if rerr == io.EOF {
+			return n, nil
+		}
+		if rerr != nil {
+			return n, rerr
+		}
+	}
+}
+
+// queueOwned queues p without copying if the queue is in background mode with no
+// limit. It returns false if p was not queued, in which case the caller retains
+// ownership of p.
+func (wtr *queue) queueOwned(p []byte) bool {
+	wtr.cq.Lock()
+	defer wtr.cq.Unlock()
+
+	if wtr.cq.state != backgroundNoLimit {
+		return false
+	}
+	wtr.cq.buf.chunks = append(wtr.cq.buf.chunks, chunk{where: wtr.where, data: p})
+	wtr.cq.queued.Add(int64(len(p)))
+
+	return true
+}
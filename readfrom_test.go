@@ -0,0 +1,94 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test that large reads are queued without being split into io.Copy sized chunks.
+func TestReadFromQueue(t *testing.T) {
+	var outBuf, errBuf testBufWriter
+	outQ, _ := newQueue(false, 0, &outBuf, &errBuf)
+	src := strings.Repeat("x", readFromChunkSize*3)
+
+	n, err := io.Copy(newHead(outQ), struct{ io.Reader }{strings.NewReader(src)})
+	if err != nil || n != int64(len(src)) {
+		t.Fatal("Unexpected ReadFrom return", n, err)
+	}
+	if len(outQ.cq.buf.chunks) != 3 {
+		t.Error("Expected three large chunks, not", len(outQ.cq.buf.chunks))
+	}
+
+	outQ.foreground()
+	if outBuf.String() != src {
+		t.Error("Data corrupted by ReadFrom")
+	}
+
+	// Now in foreground everything should pass straight thru
+	outBuf.buf.Reset()
+	io.Copy(outQ, strings.NewReader("abc"))
+	if outBuf.String() != "abc" {
+		t.Error("Foreground ReadFrom failed", outBuf.String())
+	}
+}
+
+// Test that ReadFrom with a limit reverts to Write semantics.
+func TestReadFromQueueLimit(t *testing.T) {
+	var outBuf, errBuf testBufWriter
+	outQ, _ := newQueue(false, 10, &outBuf, &errBuf)
+
+	done := make(chan struct{})
+	go func() {
+		io.Copy(outQ, struct{ io.Reader }{strings.NewReader("0123456789abcdef")})
+		close(done)
+	}()
+	outQ.foreground() // Release any blocked Write
+	<-done
+	if outBuf.String() != "0123456789abcdef" {
+		t.Error("Data corrupted by limited ReadFrom", outBuf.String())
+	}
+}
+
+func TestReadFromTail(t *testing.T) {
+	var buf bytes.Buffer
+	var mu sync.Mutex
+	h := newHead(newTail(&buf, &mu))
+	n, err := io.Copy(h, strings.NewReader("hello"))
+	if err != nil || n != 5 || buf.String() != "hello" || h.written.Load() != 5 {
+		t.Error("Tail ReadFrom failed", n, err, buf.String(), h.written.Load())
+	}
+}
+
+// A foreground RunFunc copying from a pipe which is fed by a goroutine that also writes
+// stderr must not deadlock on the output mutex.
+func TestReadFromTailPipe(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("", "", func(out, err io.Writer) {
+		pr, pw := io.Pipe()
+		go func() {
+			pw.Write([]byte("a\n"))
+			err.Write([]byte("e\n"))
+			pw.Write([]byte("b\n"))
+			pw.Close()
+		}()
+		io.Copy(out, pr)
+	})
+	grp.Run()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := grp.WaitContext(ctx); err != nil {
+		t.Fatal("Unexpected Wait error", err)
+	}
+	if stdout.String() != "a\nb\n" || stderr.String() != "e\n" {
+		t.Error("Wrong output", stdout.String(), stderr.String())
+	}
+}
@@ -0,0 +1,200 @@
+package parallel
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Kinds of recordEvent
+const (
+	recordStart  = "start"
+	recordWrite  = "write"
+	recordFinish = "finish"
+)
+
+// recordEvent is a single line of a WithRecorder recording. Offset is relative to the
+// start of the recording so that a replay can reproduce the original timing.
+type recordEvent struct {
+	Offset time.Duration `json:"t"`
+	Runner int           `json:"runner"` // Index of the runner in order of addition
+	Kind   string        `json:"kind"`
+	OutTag string        `json:"outTag,omitempty"` // Start only
+	ErrTag string        `json:"errTag,omitempty"` // Start only
+	Stream string        `json:"stream,omitempty"` // Write only
+	Data   []byte        `json:"data,omitempty"`   // Write only
+	Err    string        `json:"err,omitempty"`    // Finish only
+}
+
+// recorder writes recordEvents to the WithRecorder io.Writer. Only the first write error
+// is retained and recording stops once an error occurs.
+type recorder struct {
+	mu    sync.Mutex
+	enc   *json.Encoder
+	clock Clock
+	epoch time.Time
+	err   error
+}
+
+func newRecorder(w io.Writer, clock Clock) *recorder {
+	return &recorder{enc: json.NewEncoder(w), clock: clock, epoch: clock.Now()}
+}
+
+func (rec *recorder) record(ev recordEvent) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if rec.err == nil {
+		ev.Offset = since(rec.clock, rec.epoch)
+		rec.err = rec.enc.Encode(ev)
+	}
+}
+
+func (rec *recorder) start(rnr *runner) {
+	rec.record(recordEvent{Runner: rnr.index, Kind: recordStart,
+		OutTag: string(rnr.outTag), ErrTag: string(rnr.errTag)})
+}
+
+func (rec *recorder) finish(rnr *runner) {
+	ev := recordEvent{Runner: rnr.index, Kind: recordFinish}
+	if rnr.err != nil {
+		ev.Err = rnr.err.Error()
+	}
+	rec.record(ev)
+}
+
+// recordWriter is a writer which records every Write by a RunFunc, exactly as written,
+// before passing it on.
+type recordWriter struct {
+	commonWriter
+	rec    *recorder
+	index  int
+	stream Stream
+}
+
+func newRecordWriter(out writer, rec *recorder, index int, stream Stream) *recordWriter {
+	wtr := &recordWriter{rec: rec, index: index, stream: stream}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+func (wtr *recordWriter) Write(p []byte) (int, error) {
+	wtr.rec.record(recordEvent{Runner: wtr.index, Kind: recordWrite,
+		Stream: wtr.stream.String(), Data: p})
+
+	return wtr.out.Write(p)
+}
+
+func (wtr *recordWriter) close() {
+	wtr.out.close() // Pass it on
+}
+
+// replayRunner is the sequence of events of one recorded runner.
+type replayRunner struct {
+	index          int
+	outTag, errTag string
+	events         []recordEvent
+}
+
+// Replay re-emits a session recorded by [WithRecorder] by running a new [Group],
+// constructed with opts, in which each recorded RunFunc is replaced by one which writes
+// the recorded output at the recorded times and returns the recorded error, if any. As
+// the replay passes thru a Group, the effect of different options on the ordering and
+// presentation of the same session can be examined, such as when debugging ordering
+// issues, or a parallel run can be demonstrated without the original RunFuncs.
+//
+// Replay runs in real time when speed is 1. A speed of 10 replays ten times faster and a
+// speed of zero replays without any delay. If ctx is cancelled, the replay stops early.
+// Replay returns any error reading the recording, constructing the Group or returned by
+// [Group.Wait], which includes the recorded RunFunc errors.
+//
+// Every recorded runner is replayed, so opts which limit or constrain runners, such as
+// [LimitActiveRunners], may cause a replay to run slower than speed.
+func Replay(ctx context.Context, r io.Reader, speed float64, opts ...Option) error {
+	if speed < 0 {
+		return errors.New("Cannot replay at a negative speed")
+	}
+	runners, err := readRecording(r)
+	if err != nil {
+		return err
+	}
+	grp, err := NewGroup(opts...)
+	if err != nil {
+		return err
+	}
+
+	var epoch time.Time // Set once all runners are added so all share the same epoch
+	for _, rr := range runners {
+		grp.AddContextErr(rr.outTag, rr.errTag,
+			func(ctx context.Context, stdout, stderr io.Writer) error {
+				return rr.replay(ctx, grp.clock, epoch, speed, stdout, stderr)
+			})
+	}
+	epoch = grp.clock.Now()
+	grp.RunContext(ctx)
+
+	return grp.Wait()
+}
+
+// readRecording parses a recording into runners in their original order of addition.
+func readRecording(r io.Reader) (runners []*replayRunner, err error) {
+	byIndex := make(map[int]*replayRunner)
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for lineNo := 1; ; lineNo++ {
+		var ev recordEvent
+		if err := dec.Decode(&ev); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("Recording event %d: %w", lineNo, err)
+		}
+		rr := byIndex[ev.Runner]
+		switch {
+		case ev.Kind == recordStart && rr == nil:
+			rr = &replayRunner{index: ev.Runner, outTag: ev.OutTag, errTag: ev.ErrTag}
+			byIndex[ev.Runner] = rr
+			runners = append(runners, rr)
+		case rr == nil:
+			return nil, fmt.Errorf("Recording event %d: runner %d has not started",
+				lineNo, ev.Runner)
+		case ev.Kind != recordWrite && ev.Kind != recordFinish:
+			return nil, fmt.Errorf("Recording event %d: unexpected kind %q",
+				lineNo, ev.Kind)
+		}
+		rr.events = append(rr.events, ev)
+	}
+	slices.SortFunc(runners, func(a, b *replayRunner) int { return a.index - b.index })
+
+	return
+}
+
+// replay writes the recorded output at the recorded offsets from epoch, scaled by speed.
+func (rr *replayRunner) replay(ctx context.Context, clock Clock, epoch time.Time,
+	speed float64, stdout, stderr io.Writer) error {
+	for _, ev := range rr.events {
+		if speed > 0 {
+			due := epoch.Add(time.Duration(float64(ev.Offset) / speed))
+			select {
+			case <-clock.After(due.Sub(clock.Now())):
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			}
+		}
+		switch {
+		case ev.Kind == recordWrite && ev.Stream == Stderr.String():
+			stderr.Write(ev.Data)
+		case ev.Kind == recordWrite:
+			stdout.Write(ev.Data)
+		case ev.Kind == recordFinish && len(ev.Err) > 0:
+			return errors.New(ev.Err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordReplay(t *testing.T) {
+	if _, err := NewGroup(WithRecorder(nil)); err == nil {
+		t.Error("Expected error with nil io.Writer")
+	}
+
+	var recording, stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), WithRecorder(&recording))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("a: ", "a! ", func(out, err io.Writer) {
+		out.Write([]byte("one\ntw"))
+		time.Sleep(50 * time.Millisecond)
+		out.Write([]byte("o\n"))
+		err.Write([]byte("oops\n"))
+	})
+	grp.AddErr("b: ", "", func(out, err io.Writer) error {
+		out.Write([]byte("\x00binary\xff\n"))
+		return errors.New("b failed")
+	})
+	grp.Run()
+	grp.Wait()
+
+	for _, kind := range []string{`"start"`, `"write"`, `"finish"`, `"b failed"`} {
+		if !strings.Contains(recording.String(), kind) {
+			t.Error("Recording is missing", kind)
+		}
+	}
+
+	// Replay thru the same options must reproduce the original output and errors
+	var rOut, rErr bytes.Buffer
+	start := time.Now()
+	err = Replay(context.Background(), bytes.NewReader(recording.Bytes()), 0,
+		WithStdout(&rOut), WithStderr(&rErr))
+	if err == nil || err.Error() != "b failed" {
+		t.Error("Expected recorded error, got", err)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Error("Replay at speed zero should not delay. Took", elapsed)
+	}
+	if rOut.String() != stdout.String() || rErr.String() != stderr.String() {
+		t.Errorf("Replay differs\nGot %q %q\nExp %q %q", rOut.String(), rErr.String(),
+			stdout.String(), stderr.String())
+	}
+
+	// At real time the replay should take at least as long as the original sleep
+	start = time.Now()
+	Replay(context.Background(), bytes.NewReader(recording.Bytes()), 1, WithStdout(io.Discard))
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Error("Replay at speed one was too quick", elapsed)
+	}
+}
+
+func TestReplayErrors(t *testing.T) {
+	ctx := context.Background()
+	if err := Replay(ctx, strings.NewReader(""), -1); err == nil {
+		t.Error("Expected error with negative speed")
+	}
+	for _, rec := range []string{
+		"not json",
+		`{"t":0,"runner":3,"kind":"write","data":"eA=="}`,
+		`{"t":0,"runner":0,"kind":"start"}` + "\n" + `{"t":0,"runner":0,"kind":"bogus"}`,
+	} {
+		if err := Replay(ctx, strings.NewReader(rec), 0); err == nil {
+			t.Error("Expected error replaying", rec)
+		}
+	}
+	if err := Replay(ctx, strings.NewReader(""), 0, LimitActiveRunners(0)); err != nil {
+		t.Error("Unexpected error with empty recording", err)
+	}
+}
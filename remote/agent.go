@@ -0,0 +1,115 @@
+package remote
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os/exec"
+	"sync"
+)
+
+// Agent runs the Commands sent by Coordinators. The zero value is ready for use. The
+// exported fields must not be changed once Serve has been called.
+type Agent struct {
+	// Allow, if set, is called with each Command before it is run. A non-nil error
+	// refuses the Command and is returned to the Coordinator as the runner error.
+	Allow func(Command) error
+}
+
+// Serve accepts connections on ln and runs the Command sent over each connection,
+// returning the output and exit status of the command over the same connection. The
+// command is killed if the connection is closed, such as when the runner context of the
+// Coordinator is cancelled. Serve only returns once ln.Accept fails, such as when ln is
+// closed, and it always returns a non-nil error.
+func (a *Agent) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go a.serve(conn)
+	}
+}
+
+// serve runs the Command sent over conn.
+func (a *Agent) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return // Nothing to reply to
+	}
+
+	var status exitStatus
+	var cmd Command
+	if err = json.Unmarshal(line, &cmd); err == nil && a.Allow != nil {
+		err = a.Allow(cmd)
+	}
+	if err == nil {
+		status = a.run(conn, r, cmd)
+	} else {
+		status = exitStatus{Code: -1, Error: err.Error()}
+	}
+	b, _ := json.Marshal(status) // Cannot fail
+	writeMessage(conn, kindExit, b)
+}
+
+// run runs cmd with its output sent over conn. Nothing more is expected from the
+// Coordinator so the command is killed as soon as r returns an error.
+func (a *Agent) run(conn net.Conn, r io.Reader, cmd Command) exitStatus {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		io.Copy(io.Discard, r)
+		cancel()
+	}()
+
+	mw := &messageWriter{conn: conn, cancel: cancel}
+	ec := exec.CommandContext(ctx, cmd.Path, cmd.Args...)
+	ec.Env = append(ec.Environ(), cmd.Env...)
+	ec.Dir = cmd.Dir
+	ec.Stdout = streamWriter{mw, kindStdout}
+	ec.Stderr = streamWriter{mw, kindStderr}
+	err := ec.Run()
+
+	status := exitStatus{Code: -1}
+	if ec.ProcessState != nil {
+		status.Code = ec.ProcessState.ExitCode()
+	}
+	var ee *exec.ExitError
+	if err != nil && !(errors.As(err, &ee) && status.Code > 0) {
+		status.Error = err.Error()
+	}
+
+	return status
+}
+
+// messageWriter serializes the messages of both command streams onto the connection. As
+// the command cannot make progress once the Coordinator stops reading, the command is
+// killed if a Write fails.
+type messageWriter struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	cancel context.CancelFunc
+}
+
+// streamWriter sends each Write as a message of its kind.
+type streamWriter struct {
+	mw   *messageWriter
+	kind string
+}
+
+func (sw streamWriter) Write(p []byte) (int, error) {
+	sw.mw.mu.Lock()
+	defer sw.mw.mu.Unlock()
+
+	if err := writeMessage(sw.mw.conn, sw.kind, p); err != nil {
+		sw.mw.cancel()
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,211 @@
+package remote
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/markdingo/parallel"
+)
+
+// Worker identifies an [Agent] and how many commands it runs concurrently.
+type Worker struct {
+	Addr  string // TCP address of the Agent, as accepted by net.Dial
+	Slots int    // Maximum concurrent commands. Zero means one
+}
+
+func (w Worker) slots() int {
+	return max(w.Slots, 1)
+}
+
+// Coordinator runs Commands on a set of Agents. The exported fields can be changed after
+// construction but must not be changed once commands are running.
+type Coordinator struct {
+	Retries int // Attempts to retry a command which fails to connect
+
+	// Dial, if set, connects to an Agent in place of net.Dialer, such as to use TLS.
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	mu      sync.Mutex
+	workers []*worker
+	wake    chan struct{} // Closed and replaced whenever a slot is released
+}
+
+// worker tracks the use of one Worker. Protected by Coordinator.mu.
+type worker struct {
+	Worker
+	busy int
+}
+
+// New constructs a Coordinator of the supplied workers.
+func New(workers ...Worker) (*Coordinator, error) {
+	if len(workers) == 0 {
+		return nil, errors.New("remote: Cannot construct a Coordinator without workers")
+	}
+	c := &Coordinator{wake: make(chan struct{})}
+	for _, w := range workers {
+		if len(w.Addr) == 0 {
+			return nil, errors.New("remote: Cannot supply a Worker without an Addr")
+		}
+		c.workers = append(c.workers, &worker{Worker: w})
+	}
+
+	return c, nil
+}
+
+// Add adds a runner to grp which runs cmd on the first Agent with a free slot at the time
+// the runner starts. The output of the command is written to the runner's io.Writers as
+// it arrives. The runner error is an [*ExitError] if the command exits with a non-zero
+// status. The job slot of the runner, as described by [parallel.Slot], is set in the
+// PARALLEL_SLOT environment variable of the command.
+//
+// If the runner context is cancelled the connection is closed, which kills the command.
+func (c *Coordinator) Add(grp *parallel.Group, outTag, errTag string, cmd Command,
+	opts ...parallel.RunnerOption) {
+	grp.AddContextErr(outTag, errTag,
+		func(ctx context.Context, stdout, stderr io.Writer) error {
+			return c.run(ctx, cmd, stdout, stderr)
+		}, opts...)
+}
+
+// run runs cmd on an Agent, retrying connection failures up to Retries times, preferably
+// on a different Agent. Once connected the command is never retried as output may
+// already have been written.
+func (c *Coordinator) run(ctx context.Context, cmd Command, stdout, stderr io.Writer) error {
+	if slot := parallel.Slot(ctx); slot > 0 {
+		cmd.Env = append(slices.Clip(cmd.Env), "PARALLEL_SLOT="+strconv.Itoa(slot))
+	}
+	var last *worker
+	var err error
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		var w *worker
+		if w, err = c.acquire(ctx, last); err != nil {
+			return err
+		}
+		var conn net.Conn
+		conn, err = c.dial()(ctx, "tcp", w.Addr)
+		if err == nil {
+			err = exchange(ctx, conn, w.Addr, cmd, stdout, stderr)
+			c.release(w)
+			return err
+		}
+		c.release(w)
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		last = w
+	}
+
+	return err
+}
+
+// exchange sends cmd over conn and writes the returned output to stdout and stderr until
+// the exit status arrives. conn is closed on return.
+func exchange(ctx context.Context, conn net.Conn, addr string, cmd Command,
+	stdout, stderr io.Writer) error {
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	b, err := json.Marshal(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err = conn.Write(append(b, '\n')); err != nil {
+		return connError(ctx, addr, err)
+	}
+	r := bufio.NewReader(conn)
+	for {
+		kind, p, err := readMessage(r)
+		if err != nil {
+			return connError(ctx, addr, err)
+		}
+		switch kind {
+		case kindStdout:
+			stdout.Write(p)
+		case kindStderr:
+			stderr.Write(p)
+		case kindExit:
+			var status exitStatus
+			if err = json.Unmarshal(p, &status); err != nil {
+				return connError(ctx, addr, err)
+			}
+			switch {
+			case len(status.Error) > 0:
+				return errors.New("remote: " + addr + ": " + status.Error)
+			case status.Code != 0:
+				return &ExitError{Addr: addr, Code: status.Code}
+			}
+			return nil
+		default:
+			return connError(ctx, addr, fmt.Errorf("Unknown message kind %q", kind))
+		}
+	}
+}
+
+// connError returns the cause of the cancellation if ctx is done, as that is presumably
+// why the connection failed, otherwise it returns err annotated with addr.
+func connError(ctx context.Context, addr string, err error) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+
+	return fmt.Errorf("remote: %s: %w", addr, err)
+}
+
+// acquire waits for a free slot and returns the least busy worker with a free slot,
+// preferring any worker other than avoid.
+func (c *Coordinator) acquire(ctx context.Context, avoid *worker) (*worker, error) {
+	for {
+		c.mu.Lock()
+		var best *worker
+		for _, cand := range c.workers {
+			if cand.busy >= cand.slots() {
+				continue
+			}
+			if best == nil || (best == avoid && cand != avoid) ||
+				(cand != avoid && cand.busy*best.slots() < best.busy*cand.slots()) {
+				best = cand
+			}
+		}
+		if best != nil {
+			best.busy++
+			c.mu.Unlock()
+			return best, nil
+		}
+		wake := c.wake
+		c.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		}
+	}
+}
+
+func (c *Coordinator) release(w *worker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w.busy--
+	close(c.wake)
+	c.wake = make(chan struct{})
+}
+
+func (c *Coordinator) dial() func(ctx context.Context, network, addr string) (net.Conn,
+	error) {
+	if c.Dial != nil {
+		return c.Dial
+	}
+	var d net.Dialer
+
+	return d.DialContext
+}
@@ -0,0 +1,45 @@
+/*
+Package remote runs commands on remote worker agents over TCP and feeds their output through
+the pipelines of a [parallel.Group]. It is an alternative to the sshlogin package for
+environments where a long running agent is preferable to an ssh connection per command.
+
+An [Agent] runs on each worker machine and accepts connections from any number of
+coordinators. A [Coordinator] is constructed from a list of [Worker]s, each of which
+limits how many commands run concurrently on that agent. [Coordinator.Add] adds a runner
+which sends its [Command] to whichever agent has a free slot when the runner starts:
+
+	// On each worker machine
+	ln, err := net.Listen("tcp", ":7878")
+	if err != nil {
+		return err
+	}
+	return (&remote.Agent{}).Serve(ln)
+
+	// On the coordinating machine
+	coord, err := remote.New(remote.Worker{Addr: "build1:7878", Slots: 4},
+		remote.Worker{Addr: "build2:7878", Slots: 2})
+	if err != nil {
+		return err
+	}
+	grp, _ := parallel.NewGroup()
+	for _, file := range files {
+		coord.Add(grp, file+"\t", file+"\t",
+			remote.Command{Path: "wc", Args: []string{"-l", file}})
+	}
+	grp.Run()
+	grp.Wait()
+
+Each command runs over its own connection. The coordinator sends the Command as a single
+line of JSON and the agent replies with a series of messages, each of which is a header
+line of the form “kind length\n” followed by length bytes of payload. The kind is
+“stdout” or “stderr” for command output, in the order it was read by the agent, and the
+final message is “exit” with a JSON payload holding the exit code and any error. As the
+output is written to the runner's io.Writers as it arrives, it is tagged, ordered and
+otherwise processed by the Group exactly as if the command had run locally.
+
+The protocol has no authentication or encryption. An Agent runs any Command it is sent
+unless [Agent.Allow] says otherwise, so agents should only listen on trusted networks, or
+be served with a listener from [crypto/tls.NewListener] and reached with a
+[Coordinator.Dial] function which uses [crypto/tls.Dialer].
+*/
+package remote
@@ -0,0 +1,85 @@
+package remote
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Command describes a command for an [Agent] to run. It holds the subset of [exec.Cmd]
+// fields which make sense on a remote machine.
+type Command struct {
+	Path string   `json:"path"`           // Program to run, looked up in the Agent's PATH
+	Args []string `json:"args,omitempty"` // Arguments, excluding the program name
+	Env  []string `json:"env,omitempty"`  // Appended to the environment of the Agent
+	Dir  string   `json:"dir,omitempty"`  // Working directory, defaults to the Agent's
+}
+
+// ExitError is returned when a remote command exits with a non-zero status.
+type ExitError struct {
+	Addr string // Of the Worker which ran the command
+	Code int    // Exit code of the command
+}
+
+func (e *ExitError) Error() string {
+	return "remote: " + e.Addr + ": exit status " + strconv.Itoa(e.Code)
+}
+
+// Message kinds sent from an Agent to a Coordinator.
+const (
+	kindStdout = "stdout"
+	kindStderr = "stderr"
+	kindExit   = "exit"
+)
+
+// maxMessage bounds the payload of a message so that a corrupt stream cannot cause an
+// unbounded allocation.
+const maxMessage = 1 << 24
+
+// exitStatus is the payload of the final message.
+type exitStatus struct {
+	Code  int    `json:"code"`            // -1 if the command did not exit normally
+	Error string `json:"error,omitempty"` // Unless the command merely exited non-zero
+}
+
+// writeMessage writes a single message to w with a single Write.
+func writeMessage(w io.Writer, kind string, p []byte) error {
+	msg := make([]byte, 0, len(kind)+len(p)+12)
+	msg = append(msg, kind...)
+	msg = append(msg, ' ')
+	msg = strconv.AppendInt(msg, int64(len(p)), 10)
+	msg = append(msg, '\n')
+	msg = append(msg, p...)
+	_, err := w.Write(msg)
+
+	return err
+}
+
+// readMessage reads the next message from r. A stream which ends part way thru a
+// message returns io.ErrUnexpectedEOF.
+func readMessage(r *bufio.Reader) (kind string, p []byte, err error) {
+	header, err := r.ReadString('\n')
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF // A message stream always ends with kindExit
+		}
+		return
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 2 {
+		return "", nil, fmt.Errorf("Malformed message header %q", header)
+	}
+	length, err := strconv.Atoi(fields[1])
+	if err != nil || length < 0 || length > maxMessage {
+		return "", nil, fmt.Errorf("Malformed message length %q", fields[1])
+	}
+	p = make([]byte, length)
+	if _, err = io.ReadFull(r, p); errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return fields[0], p, err
+}
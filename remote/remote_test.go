@@ -0,0 +1,158 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markdingo/parallel"
+)
+
+// startAgent serves agent on a loopback listener for the duration of the test.
+func startAgent(t *testing.T, agent *Agent) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("Requires a POSIX shell")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go agent.Serve(ln)
+
+	return ln.Addr().String()
+}
+
+// deadAddr returns an address which refuses connections.
+func deadAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.Close()
+
+	return ln.Addr().String()
+}
+
+func sh(script string) Command {
+	return Command{Path: "sh", Args: []string{"-c", script}}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(); err == nil {
+		t.Error("Expected error with no workers")
+	}
+	if _, err := New(Worker{Slots: 2}); err == nil {
+		t.Error("Expected error with missing Addr")
+	}
+}
+
+func TestCoordinatorAdd(t *testing.T) {
+	addr := startAgent(t, &Agent{})
+	c, err := New(Worker{Addr: deadAddr(t)}, Worker{Addr: addr, Slots: 4})
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	c.Retries = 1
+	var stdout, stderr bytes.Buffer
+	grp, _ := parallel.NewGroup(parallel.WithStdout(&stdout), parallel.WithStderr(&stderr))
+	c.Add(grp, "a ", "a ", sh("echo one; echo oops >&2; echo two"))
+	c.Add(grp, "b ", "b ", sh("exit 3"))
+	c.Add(grp, "c ", "c ", sh(`echo "slot $PARALLEL_SLOT"`))
+	grp.Run()
+	err = grp.Wait()
+
+	var ee *ExitError
+	if !errors.As(err, &ee) || ee.Code != 3 || ee.Addr != addr {
+		t.Error("Expected exit code 3, got", err)
+	}
+	if got := stdout.String(); !strings.HasPrefix(got, "a one\na two\nc slot ") ||
+		strings.HasSuffix(got, "slot \n") {
+		t.Error("Wrong stdout", got)
+	}
+	if got := stderr.String(); got != "a oops\n" {
+		t.Error("Wrong stderr", got)
+	}
+}
+
+func TestAgentAllow(t *testing.T) {
+	addr := startAgent(t, &Agent{Allow: func(cmd Command) error {
+		if cmd.Path != "echo" {
+			return errors.New("not allowed")
+		}
+		return nil
+	}})
+	c, _ := New(Worker{Addr: addr})
+	var stdout bytes.Buffer
+	grp, _ := parallel.NewGroup(parallel.WithStdout(&stdout))
+	c.Add(grp, "", "", Command{Path: "echo", Args: []string{"hello"}, Env: []string{"X=1"}})
+	c.Add(grp, "", "", sh("echo bad"))
+	grp.Run()
+	err := grp.Wait()
+
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Error("Expected refusal, got", err)
+	}
+	if got := stdout.String(); got != "hello\n" {
+		t.Error("Wrong stdout", got)
+	}
+}
+
+func TestCoordinatorCancel(t *testing.T) {
+	addr := startAgent(t, &Agent{})
+	c, _ := New(Worker{Addr: addr})
+	grp, _ := parallel.NewGroup()
+	c.Add(grp, "", "", sh("sleep 10"))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	grp.RunContext(ctx)
+	err := grp.Wait()
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("Expected deadline error, got", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("Command was not killed")
+	}
+}
+
+func TestCoordinatorNoAgent(t *testing.T) {
+	c, _ := New(Worker{Addr: deadAddr(t)})
+	grp, _ := parallel.NewGroup()
+	c.Add(grp, "", "", sh("true"))
+	grp.Run()
+	if err := grp.Wait(); err == nil {
+		t.Error("Expected connection error")
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	var buf bytes.Buffer
+	writeMessage(&buf, kindStdout, []byte("hello\n"))
+	r := bufio.NewReader(&buf)
+	kind, p, err := readMessage(r)
+	if err != nil || kind != kindStdout || string(p) != "hello\n" {
+		t.Error("Wrong message", kind, string(p), err)
+	}
+	if _, _, err = readMessage(r); err != io.ErrUnexpectedEOF {
+		t.Error("Expected unexpected EOF, got", err)
+	}
+
+	for _, bad := range []string{"stdout\n", "stdout x\n", "stdout -1\n", "stdout 99999999\n"} {
+		if _, _, err := readMessage(bufio.NewReader(strings.NewReader(bad))); err == nil {
+			t.Error("Expected error with", bad)
+		}
+	}
+	_, _, err = readMessage(bufio.NewReader(strings.NewReader("stdout 10\nshort")))
+	if err != io.ErrUnexpectedEOF {
+		t.Error("Expected unexpected EOF with a truncated payload, got", err)
+	}
+}
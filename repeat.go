@@ -0,0 +1,88 @@
+package parallel
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+)
+
+// repeater is a writer which collapses consecutive identical lines into a single line
+// followed by a syslog-style "last line repeated N times" message. It sits after the
+// tagger so the lines it compares include the tag. The message is also tagged so that it
+// is attributed to the correct runner. Incomplete lines are held back until they are
+// completed or the writer is closed.
+type repeater struct {
+	mu sync.Mutex
+	commonWriter
+	tag     []byte
+	delim   byte   // Line delimiter - see WithLineDelimiter
+	last    []byte // Most recent complete line written, including delim
+	repeats int    // Number of times last has been suppressed
+	partial []byte
+}
+
+func newRepeater(out writer, tag []byte, delim byte) *repeater {
+	wtr := &repeater{tag: tag, delim: delim}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+// Write returns the length of p on success as repeated lines are consumed even tho they
+// are not written downstream. The first downstream error is returned.
+func (wtr *repeater) Write(p []byte) (n int, err error) {
+	wtr.mu.Lock()
+	defer wtr.mu.Unlock()
+
+	wtr.partial = append(wtr.partial, p...)
+	for {
+		ix := bytes.IndexByte(wtr.partial, wtr.delim)
+		if ix < 0 {
+			break
+		}
+		line := wtr.partial[:ix+1]
+		wtr.partial = wtr.partial[ix+1:]
+		if wtr.last != nil && bytes.Equal(line, wtr.last) {
+			wtr.repeats++
+			continue
+		}
+		if e := wtr.flushRepeats(); e != nil && err == nil {
+			err = e
+		}
+		wtr.last = append(wtr.last[:0], line...)
+		if _, e := wtr.out.Write(line); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return len(p), err
+}
+
+// flushRepeats writes the repeat message if any lines have been suppressed. Caller must
+// hold the mutex.
+func (wtr *repeater) flushRepeats() error {
+	if wtr.repeats == 0 {
+		return nil
+	}
+	msg := make([]byte, 0, len(wtr.tag)+40)
+	msg = append(msg, wtr.tag...)
+	msg = append(msg, "last line repeated "...)
+	msg = strconv.AppendInt(msg, int64(wtr.repeats), 10)
+	msg = append(msg, " times"...)
+	msg = append(msg, wtr.delim)
+	wtr.repeats = 0
+	_, err := wtr.out.Write(msg)
+
+	return err
+}
+
+func (wtr *repeater) close() {
+	wtr.mu.Lock()
+	wtr.flushRepeats()
+	if len(wtr.partial) > 0 {
+		wtr.out.Write(wtr.partial)
+		wtr.partial = nil
+	}
+	wtr.mu.Unlock()
+	wtr.out.close() // Pass it on
+}
@@ -0,0 +1,86 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestRepeater(t *testing.T) {
+	testCases := []struct {
+		writes []string
+		expect string
+	}{
+		{[]string{}, ""},
+		{[]string{"a\n"}, "a\n"},
+		{[]string{"a\na\n"}, "a\nT: last line repeated 1 times\n"},
+		{[]string{"a\n", "a\n", "a\nb\n"}, "a\nT: last line repeated 2 times\nb\n"},
+		{[]string{"a", "\na", "\n", "a"}, "a\nT: last line repeated 1 times\na"},
+		{[]string{"a\nb\na\n"}, "a\nb\na\n"},
+		{[]string{"\n\n\n"}, "\nT: last line repeated 2 times\n"},
+	}
+
+	for ix, tc := range testCases {
+		out := &testBufWriter{}
+		wtr := newRepeater(out, []byte("T: "), '\n')
+		for _, w := range tc.writes {
+			n, err := wtr.Write([]byte(w))
+			if err != nil || n != len(w) {
+				t.Error(ix, "Unexpected Write return", n, err)
+			}
+		}
+		wtr.close()
+		if out.String() != tc.expect {
+			t.Errorf("%d Expected %q, got %q", ix, tc.expect, out.String())
+		}
+	}
+}
+
+func TestGroupSuppressRepeats(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), SuppressRepeats(true))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("a: ", "A: ", func(out, err io.Writer) {
+		for i := 0; i < 5; i++ {
+			out.Write([]byte("tick\n"))
+			err.Write([]byte("warn\n"))
+		}
+		out.Write([]byte("done\n"))
+	})
+	grp.Run()
+	grp.Wait()
+
+	exp := "a: tick\na: last line repeated 4 times\na: done\n"
+	if stdout.String() != exp {
+		t.Errorf("Stdout expected %q, got %q", exp, stdout.String())
+	}
+	exp = "A: warn\nA: last line repeated 4 times\n"
+	if stderr.String() != exp {
+		t.Errorf("Stderr expected %q, got %q", exp, stderr.String())
+	}
+}
+
+func TestGroupLineDelimiter(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), SuppressRepeats(true),
+		WithLineDelimiter(0))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("a: ", "A: ", func(out, err io.Writer) {
+		out.Write([]byte("x\ny\x00x\ny\x00x\ny\x00done"))
+		err.Write([]byte("warn\x00"))
+	})
+	grp.Run()
+	grp.Wait()
+
+	exp := "a: x\ny\x00a: last line repeated 2 times\x00a: done"
+	if stdout.String() != exp {
+		t.Errorf("Stdout expected %q, got %q", exp, stdout.String())
+	}
+	if exp = "A: warn\x00"; stderr.String() != exp {
+		t.Errorf("Stderr expected %q, got %q", exp, stderr.String())
+	}
+}
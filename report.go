@@ -0,0 +1,149 @@
+package parallel
+
+import (
+	"bytes"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ReportFormat selects the rendering of [WithReport].
+type ReportFormat int
+
+const (
+	ReportNone     ReportFormat = iota // No report - the default
+	ReportMarkdown                     // GitHub-flavoured Markdown
+	ReportHTML                         // An HTML fragment
+)
+
+// reportSection accumulates all output of a runner so that the whole section, including
+// the status of the runner, can be rendered once the runner is closed. The stdout and
+// stderr reporters of a runner share the one reportSection.
+type reportSection struct {
+	mu      sync.Mutex
+	format  ReportFormat
+	rnr     *runner
+	out     writer // The stdout writer which receives the rendered section
+	buf     bytes.Buffer
+	pending int // Count of reporters not yet closed
+}
+
+func newReportSection(out writer, format ReportFormat, rnr *runner) *reportSection {
+	return &reportSection{format: format, rnr: rnr, out: out, pending: 2}
+}
+
+// reporter replaces the tails when WithReport is set. All output is accumulated in the
+// reportSection until both streams are closed.
+type reporter struct {
+	commonWriter
+	sec *reportSection
+}
+
+func newReporter(out writer, sec *reportSection) *reporter {
+	wtr := &reporter{sec: sec}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+func (wtr *reporter) Write(p []byte) (int, error) {
+	wtr.sec.mu.Lock()
+	defer wtr.sec.mu.Unlock()
+
+	return wtr.sec.buf.Write(p)
+}
+
+// close renders the section once both streams are closed. By then the RunFunc has
+// returned so the runner status is stable.
+func (wtr *reporter) close() {
+	sec := wtr.sec
+	sec.mu.Lock()
+	sec.pending--
+	if sec.pending == 0 {
+		sec.out.Write(sec.render())
+	}
+	sec.mu.Unlock()
+	wtr.out.close() // Pass it on
+}
+
+// render returns the complete section. Caller must hold sec.mu.
+func (sec *reportSection) render() []byte {
+	res := sec.rnr.result()
+	status := res.Outcome.String()
+	if res.Outcome == Completed && res.Err != nil {
+		status = "failed"
+	}
+	name := sec.rnr.sectionName()
+	if len(name) == 0 {
+		name = "runner " + strconv.Itoa(sec.rnr.index+1)
+	}
+	name = html.EscapeString(name)
+	open := ""
+	if status != "completed" { // Draw attention to anything unexpected
+		open = " open"
+	}
+
+	var b bytes.Buffer
+	switch sec.format {
+	case ReportHTML:
+		b.WriteString(`<details class="parallel-runner ` + strings.ReplaceAll(status, " ", "-") +
+			`"` + open + ">\n")
+		b.WriteString(`<summary><span class="parallel-badge">` + status + "</span> " + name +
+			"</summary>\n")
+		if sec.buf.Len() > 0 {
+			b.WriteString("<pre>")
+			b.WriteString(html.EscapeString(sec.buf.String()))
+			b.WriteString("</pre>\n")
+		}
+		if res.Err != nil {
+			b.WriteString(`<p class="parallel-error">` + html.EscapeString(res.Err.Error()) +
+				"</p>\n")
+		}
+		b.WriteString("</details>\n")
+
+	default: // ReportMarkdown
+		b.WriteString("<details" + open + ">\n")
+		b.WriteString("<summary>" + reportBadges[status] + " <b>" + name + "</b> " + status +
+			"</summary>\n\n")
+		if sec.buf.Len() > 0 {
+			fence := strings.Repeat("`", max(3, longestRun(sec.buf.Bytes(), '`')+1))
+			b.WriteString(fence + "text\n")
+			b.Write(sec.buf.Bytes())
+			if !bytes.HasSuffix(sec.buf.Bytes(), []byte("\n")) {
+				b.WriteString("\n")
+			}
+			b.WriteString(fence + "\n\n")
+		}
+		if res.Err != nil {
+			b.WriteString("Error: " + html.EscapeString(res.Err.Error()) + "\n\n")
+		}
+		b.WriteString("</details>\n\n")
+	}
+
+	return b.Bytes()
+}
+
+// reportBadges are the Markdown status badges.
+var reportBadges = map[string]string{
+	"completed": "✅",
+	"failed":    "❌",
+	"panicked":  "💥",
+	"timed out": "⏱️",
+	"skipped":   "⏭️",
+}
+
+// longestRun returns the length of the longest run of c in p.
+func longestRun(p []byte, c byte) (longest int) {
+	run := 0
+	for _, b := range p {
+		if b != c {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+
+	return
+}
@@ -0,0 +1,68 @@
+package parallel
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReportMarkdown(t *testing.T) {
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(io.Discard),
+		WithReport(ReportMarkdown))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.AddErr("", "", func(out, err io.Writer) error {
+		out.Write([]byte("one\n"))
+		return nil
+	}, RunnerName("host1"))
+	grp.AddErr("", "", func(out, err io.Writer) error {
+		err.Write([]byte("has ``` fence"))
+		return errors.New("bad <exit>")
+	})
+	grp.Run()
+	grp.Wait()
+
+	exp := "<details>\n<summary>✅ <b>host1</b> completed</summary>\n\n" +
+		"```text\none\n```\n\n</details>\n\n" +
+		"<details open>\n<summary>❌ <b>runner 2</b> failed</summary>\n\n" +
+		"````text\nhas ``` fence\n````\n\nError: bad &lt;exit&gt;\n\n</details>\n\n"
+	if got := stdout.String(); got != exp {
+		t.Errorf("Wrong report.\nGot %q\nExp %q", got, exp)
+	}
+}
+
+func TestReportHTML(t *testing.T) {
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithReport(ReportHTML))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("a < b\n")) }, RunnerName("x&y"))
+	grp.Run()
+	grp.Wait()
+
+	exp := "<details class=\"parallel-runner completed\">\n" +
+		"<summary><span class=\"parallel-badge\">completed</span> x&amp;y</summary>\n" +
+		"<pre>a &lt; b\n</pre>\n</details>\n"
+	if got := stdout.String(); got != exp {
+		t.Errorf("Wrong report.\nGot %q\nExp %q", got, exp)
+	}
+}
+
+func TestReportConflicts(t *testing.T) {
+	for _, opt := range []Option{Passthru(true), Ungroup(true), WithJSONOutput(true),
+		WithFramedOutput(true), LimitMemoryPerRunner(100)} {
+		_, err := NewGroup(OrderRunners(false), LimitActiveRunners(1),
+			WithReport(ReportHTML), opt)
+		if err == nil || !strings.Contains(err.Error(), "WithReport") {
+			t.Error("Expected WithReport conflict, got", err)
+		}
+	}
+	if _, err := NewGroup(WithReport(ReportHTML + 1)); err == nil {
+		t.Error("Expected error for unknown ReportFormat")
+	}
+}
@@ -0,0 +1,93 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+)
+
+// Outcome describes how a runner finished, as reported by [RunnerResult].
+type Outcome int
+
+const (
+	Completed Outcome = iota // The RunFunc returned, possibly with an error
+	Panicked                 // The RunFunc panicked and Err is a *PanicError
+	TimedOut                 // The RunFunc returned an error wrapping context.DeadlineExceeded
+	Skipped                  // The RunFunc was never called
+	Detached                 // The runner was abandoned by WithDetach and Err is ErrDetached
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Panicked:
+		return "panicked"
+	case TimedOut:
+		return "timed out"
+	case Skipped:
+		return "skipped"
+	case Detached:
+		return "detached"
+	}
+
+	return "completed"
+}
+
+// RunnerResult is the final disposition of a runner as returned by [Group.RunnerResult].
+type RunnerResult struct {
+	Index    int    // Order in which the runner was added, starting at zero
+	OutTag   string // As supplied to Add
+	ErrTag   string // As supplied to Add
+	Outcome  Outcome
+	Err      error // Error recorded against the runner, if any
+	ExitCode int   // Exit code of an AddCommand command, otherwise -1
+	WriteErr error // *WriteError if the runner output could not be written, if any
+}
+
+// RunnerResult returns the result of the i'th runner added to the Group. It is typically
+// used to compute a program exit code which distinguishes between failure modes.
+// RunnerResult can only be called after [Group.Wait] has returned.
+//
+// Skipped runners, such as those skipped due to [WithHalt], [WithResume] or a cancelled
+// context, have an Outcome of Skipped and their Err records the reason, if any.
+func (grp *Group) RunnerResult(i int) RunnerResult {
+	grp.checkState(groupIsDone)
+
+	return grp.runners()[i].result()
+}
+
+func (rnr *runner) result() RunnerResult {
+	res := RunnerResult{Index: rnr.index, OutTag: string(rnr.outTag),
+		ErrTag: string(rnr.errTag), ExitCode: -1}
+	if rnr.detached { // rnr.err may still be changing
+		res.Outcome = Detached
+		res.Err = ErrDetached
+		return res
+	}
+	res.Err = rnr.err
+	res.ExitCode = rnr.exitCode()
+	res.WriteErr = rnr.writeErr
+	var pe *PanicError
+	switch {
+	case rnr.skipped:
+		res.Outcome = Skipped
+	case errors.As(rnr.err, &pe):
+		res.Outcome = Panicked
+	case errors.Is(rnr.err, context.DeadlineExceeded):
+		res.Outcome = TimedOut
+	}
+
+	return res
+}
+
+// notifyFlushed sends the result of rnr to any WithNotify and RunnerNotify channels.
+func (grp *Group) notifyFlushed(rnr *runner) {
+	if grp.notify == nil && rnr.notify == nil {
+		return
+	}
+	res := rnr.result()
+	if rnr.notify != nil {
+		rnr.notify <- res
+	}
+	if grp.notify != nil {
+		grp.notify <- res
+	}
+}
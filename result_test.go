@@ -0,0 +1,125 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRunnerResult(t *testing.T) {
+	grp, err := NewGroup(WithStderr(io.Discard), LimitActiveRunners(1),
+		WithHalt("soon,fail=2"))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	failed := errors.New("failed")
+	grp.AddErr("", "", func(out, err io.Writer) error { return nil })
+	grp.AddErr("", "", func(out, err io.Writer) error { return failed })
+	grp.Add("", "", func(out, err io.Writer) { panic("boom") })
+	grp.AddContextErr("", "", func(ctx context.Context, out, err io.Writer) error {
+		ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+		defer cancel()
+		<-ctx.Done()
+		return fmt.Errorf("wrapped: %w", ctx.Err())
+	})
+	grp.Run()
+	grp.Wait()
+
+	testCases := []struct {
+		outcome Outcome
+		err     bool
+	}{{Completed, false}, {Completed, true}, {Panicked, true}, {Skipped, true}}
+	for ix, tc := range testCases {
+		res := grp.RunnerResult(ix)
+		if res.Index != ix {
+			t.Error(ix, "Wrong index", res.Index)
+		}
+		if res.Outcome != tc.outcome {
+			t.Error(ix, "Expected", tc.outcome, "got", res.Outcome)
+		}
+		if (res.Err != nil) != tc.err {
+			t.Error(ix, "Unexpected Err", res.Err)
+		}
+	}
+	if !errors.Is(grp.RunnerResult(3).Err, ErrHalted) {
+		t.Error("Expected ErrHalted for skipped runner, got", grp.RunnerResult(3).Err)
+	}
+
+	rnr := &runner{err: fmt.Errorf("x: %w", context.DeadlineExceeded)}
+	if rnr.result().Outcome != TimedOut {
+		t.Error("Expected TimedOut, got", rnr.result().Outcome)
+	}
+	if TimedOut.String() != "timed out" || Completed.String() != "completed" {
+		t.Error("Outcome String wrong", TimedOut, Completed)
+	}
+}
+
+// Buffered output which cannot be written downstream is reported as a WriteError.
+func TestRunnerResultWriteErr(t *testing.T) {
+	diskFull := errors.New("disk full")
+	var stdout testTruncateWriter
+	stdout.append("fail", 0, diskFull)
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(io.Discard), OrderRunners(false),
+		LimitActiveRunners(1))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("lost\n")) })
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("kept\n")) })
+	grp.Run()
+	err = grp.Wait()
+
+	var we *WriteError
+	if !errors.As(err, &we) || we.Index != 0 || !errors.Is(err, diskFull) {
+		t.Fatal("Expected WriteError from Wait, got", err)
+	}
+	if res := grp.RunnerResult(0); res.WriteErr != we || res.Err != nil {
+		t.Error("Wrong result for runner 0", res)
+	}
+	if res := grp.RunnerResult(1); res.WriteErr != nil {
+		t.Error("Unexpected WriteErr for runner 1", res.WriteErr)
+	}
+	if stdout.String() != "kept\n" {
+		t.Error("Wrong stdout", stdout.String())
+	}
+}
+
+func TestRunnerNotify(t *testing.T) {
+	var stdout testLockedBuffer
+	all := make(chan RunnerResult, 3)
+	grp, err := NewGroup(WithStdout(&stdout), WithNotify(all), WithBufferedOutput(64))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	one := make(chan RunnerResult, 1)
+	failed := errors.New("failed")
+	grp.Add("a", "", func(out, err io.Writer) { fmt.Fprintln(out, "a") })
+	grp.AddErr("b", "", func(out, err io.Writer) error {
+		fmt.Fprintln(out, "b")
+		return failed
+	}, RunnerNotify(one))
+	grp.Add("c", "", func(out, err io.Writer) {})
+	grp.Run()
+	grp.Done() // Progress the Group in the background
+
+	res := <-one // Output must be flushed by the time the result is received
+	if res.Index != 1 || res.Err != failed || stdout.String() != "aa\nbb\n" {
+		t.Error("Wrong per-runner notification", res, stdout.String())
+	}
+	grp.Wait()
+
+	var indexes []int
+	for res := range all { // Closed by Wait
+		indexes = append(indexes, res.Index)
+	}
+	if fmt.Sprint(indexes) != "[0 1 2]" {
+		t.Error("Wrong Group notifications", indexes)
+	}
+
+	if _, err := NewGroup(WithNotify(nil)); err == nil {
+		t.Error("Expected error with nil channel")
+	}
+}
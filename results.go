@@ -0,0 +1,62 @@
+package parallel
+
+import "iter"
+
+// Results is an alternative to [Group.Wait] which returns an iterator that yields the
+// [RunnerResult] of each runner as its output is written to the Group io.Writers, in the
+// same order as the output is written. This allows callers to process results
+// incrementally rather than waiting for all RunFuncs to complete:
+//
+//	group.Run()
+//	for res := range group.Results() {
+//	    if res.Err != nil {
+//	        fmt.Fprintln(os.Stderr, res.OutTag, "failed:", res.Err)
+//	    }
+//	}
+//
+// Results must be called in place of Wait and the iterator can only be used once. The loop
+// body runs concurrently with the Group, so it may call [Group.Add] and [Group.CloseAdd]
+// for an [OpenEnded] Group. If the loop exits early, the iterator still waits for all
+// RunFuncs to complete before returning. Once the loop exits, the Group is in the same
+// state as if Wait had returned, so [Group.Errors], [Group.Stats] and similar can be
+// called. Wait can also be called to obtain the error which Wait would otherwise have
+// returned:
+//
+//	for res := range group.Results() {
+//	    ...
+//	}
+//	err := group.Wait()
+func (grp *Group) Results() iter.Seq[RunnerResult] {
+	return func(yield func(RunnerResult) bool) {
+		grp.mu.Lock()
+		grp.checkState(groupIsRunning)
+		grp.emitted = []*runner{}
+		grp.bgWait = true // So Wait returns waitErr rather than panicking
+		grp.mu.Unlock()
+
+		waitDone := make(chan struct{})
+		go func() {
+			grp.wait()
+			close(waitDone)
+		}()
+		defer func() { <-waitDone }()
+
+		for {
+			grp.mu.Lock()
+			for len(grp.emitted) == 0 && grp.state != groupIsDone {
+				grp.emitCond.Wait()
+			}
+			if len(grp.emitted) == 0 { // Must be done
+				grp.mu.Unlock()
+				return
+			}
+			rnr := grp.emitted[0]
+			grp.emitted = grp.emitted[1:]
+			grp.mu.Unlock()
+
+			if !yield(rnr.result()) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package parallel
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestResults(t *testing.T) {
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), OpenEnded(true))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	failed := errors.New("failed")
+	grp.AddErr("a", "", func(out, err io.Writer) error {
+		out.Write([]byte("a\n"))
+		return nil
+	})
+	grp.AddErr("b", "", func(out, err io.Writer) error { return failed })
+	grp.Run()
+
+	var tags []string
+	for res := range grp.Results() {
+		tags = append(tags, res.OutTag)
+		if res.Index == 0 { // Can add while iterating
+			grp.Add("c", "", func(out, err io.Writer) {})
+			grp.CloseAdd()
+		}
+		if res.Index == 1 && res.Err != failed {
+			t.Error("Expected failed error, got", res.Err)
+		}
+	}
+	if len(tags) != 3 || tags[0] != "a" || tags[1] != "b" || tags[2] != "c" {
+		t.Error("Wrong results order", tags)
+	}
+	if len(grp.Errors()) != 3 { // Group must be Done
+		t.Error("Expected three errors entries")
+	}
+	if err = grp.Wait(); !errors.Is(err, failed) { // Aggregate error still available
+		t.Error("Expected Wait after Results to return failed, got", err)
+	}
+
+	// Early exit still waits for completion
+	grp, _ = NewGroup(WithStdout(&stdout))
+	for i := 0; i < 5; i++ {
+		grp.Add("", "", func(out, err io.Writer) {})
+	}
+	grp.AddErr("", "", func(out, err io.Writer) error { return failed })
+	grp.Run()
+	count := 0
+	for range grp.Results() {
+		count++
+		break
+	}
+	if count != 1 || grp.state != groupIsDone {
+		t.Error("Early exit did not complete Group", count, grp.state)
+	}
+	if err = grp.Wait(); !errors.Is(err, failed) {
+		t.Error("Expected Wait after early exit to return failed, got", err)
+	}
+}
@@ -0,0 +1,147 @@
+package parallel
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// Names of the files written to each runner directory by WithResultsDir.
+const (
+	resultsStdout = "stdout"
+	resultsStderr = "stderr"
+	resultsStatus = "status.json"
+)
+
+// resultsStatusEntry is the content of the status file. It extends the JSON job log
+// entry with the outcome and exit code of the runner.
+type resultsStatusEntry struct {
+	jobLogEntry
+	Outcome  string `json:"outcome"`
+	ExitCode int    `json:"exitCode"`
+}
+
+// runnerDir manages the directory of a single runner for WithResultsDir. The
+// directory and files are only created once needed so that a large Group does not
+// consume file descriptors for runners which have yet to run.
+type runnerDir struct {
+	mu   sync.Mutex
+	dir  string
+	made bool  // If dir has been created
+	err  error // First error, after which nothing more is written
+}
+
+func newRunnerDir(root string, rnr *runner) *runnerDir {
+	return &runnerDir{dir: filepath.Join(root, strconv.Itoa(rnr.index+1))}
+}
+
+// create creates name in the runner directory, creating the directory if need be.
+// Caller must hold the mutex.
+func (rr *runnerDir) create(name string) *os.File {
+	if rr.err != nil {
+		return nil
+	}
+	if !rr.made {
+		if rr.err = os.MkdirAll(rr.dir, 0o755); rr.err != nil {
+			return nil
+		}
+		rr.made = true
+	}
+	f, err := os.Create(filepath.Join(rr.dir, name))
+	rr.err = err
+
+	return f
+}
+
+// succeeded returns true if a previous run left a status file showing that the runner
+// completed without error.
+func (rr *runnerDir) succeeded() bool {
+	b, err := os.ReadFile(filepath.Join(rr.dir, resultsStatus))
+	if err != nil {
+		return false
+	}
+	var entry resultsStatusEntry
+	if json.Unmarshal(b, &entry) != nil {
+		return false
+	}
+
+	return entry.Outcome == Completed.String() && entry.Error == nil
+}
+
+// writeStatus writes the status file once the runner is closed.
+func (rr *runnerDir) writeStatus(rnr *runner) {
+	res := rnr.result()
+	entry := resultsStatusEntry{jobLogEntry: newJobLogEntry(rnr),
+		Outcome: res.Outcome.String(), ExitCode: res.ExitCode}
+	b, _ := json.MarshalIndent(entry, "", "  ") // Cannot fail
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	if f := rr.create(resultsStatus); f != nil {
+		_, err := f.Write(append(b, '\n'))
+		rr.err = errors.Join(err, f.Close())
+	}
+}
+
+// resultsWriter is a writer which copies the RunFunc output of one stream to its file in
+// the runner directory before passing it on. The file is created on the first Write, or
+// on close if the RunFunc wrote nothing, so that every runner directory has both files.
+// File errors never affect the rest of the pipeline.
+type resultsWriter struct {
+	commonWriter
+	rr   *runnerDir
+	name string
+	f    *os.File
+}
+
+func newResultsWriter(out writer, rr *runnerDir, name string) *resultsWriter {
+	wtr := &resultsWriter{rr: rr, name: name}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+func (wtr *resultsWriter) Write(p []byte) (int, error) {
+	wtr.rr.mu.Lock()
+	if wtr.f == nil {
+		wtr.f = wtr.rr.create(wtr.name)
+	}
+	if wtr.f != nil && wtr.rr.err == nil {
+		_, wtr.rr.err = wtr.f.Write(p)
+	}
+	wtr.rr.mu.Unlock()
+
+	return wtr.out.Write(p)
+}
+
+func (wtr *resultsWriter) close() {
+	wtr.rr.mu.Lock()
+	if wtr.f == nil {
+		wtr.f = wtr.rr.create(wtr.name)
+	}
+	if wtr.f != nil {
+		if err := wtr.f.Close(); wtr.rr.err == nil {
+			wtr.rr.err = err
+		}
+	}
+	wtr.rr.mu.Unlock()
+	wtr.out.close() // Pass it on
+}
+
+// writeResults writes the status file of a closed runner and retains the first error
+// which occurred while writing any runner directory. Caller must hold grp.mu.
+func (grp *Group) writeResults(rnr *runner) {
+	if rnr.results == nil { // Resumed runners have no directory
+		return
+	}
+	rnr.results.writeStatus(rnr)
+
+	rnr.results.mu.Lock()
+	defer rnr.results.mu.Unlock()
+	if grp.resultErr == nil {
+		grp.resultErr = rnr.results.err
+	}
+}
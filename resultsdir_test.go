@@ -0,0 +1,175 @@
+package parallel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestResultsDir(t *testing.T) {
+	if _, err := NewGroup(WithResultsDir("")); err == nil {
+		t.Error("Expected error with empty directory")
+	}
+
+	dir := filepath.Join(t.TempDir(), "results")
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(io.Discard), WithResultsDir(dir),
+		DiscardStderr(true))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("one: ", "one! ", func(out, err io.Writer) {
+		out.Write([]byte("hello\n"))
+		err.Write([]byte("oops\n"))
+	})
+	grp.AddErr("two: ", "", func(out, err io.Writer) error { return errors.New("failed") })
+	grp.Run()
+	if err := grp.Wait(); err == nil || err.Error() != "failed" {
+		t.Error("Expected only the RunFunc error, got", err)
+	}
+	if stdout.String() != "one: hello\n" {
+		t.Errorf("Combined stream should be unaffected %q", stdout.String())
+	}
+
+	read := func(parts ...string) string {
+		b, err := os.ReadFile(filepath.Join(append([]string{dir}, parts...)...))
+		if err != nil {
+			t.Error("Unexpected read error", err)
+		}
+		return string(b)
+	}
+	if read("1", "stdout") != "hello\n" || read("1", "stderr") != "oops\n" {
+		t.Error("Wrong output in runner 1 directory")
+	}
+	if read("2", "stdout") != "" || read("2", "stderr") != "" {
+		t.Error("Expected empty output files in runner 2 directory")
+	}
+	var status struct {
+		Seq      int     `json:"seq"`
+		Tag      string  `json:"tag"`
+		Stdout   int64   `json:"stdout"`
+		Error    *string `json:"error"`
+		Outcome  string  `json:"outcome"`
+		ExitCode int     `json:"exitCode"`
+	}
+	if err := json.Unmarshal([]byte(read("2", "status.json")), &status); err != nil {
+		t.Fatal("Unexpected status error", err)
+	}
+	if status.Seq != 2 || status.Tag != "two:" || status.Error == nil ||
+		*status.Error != "failed" || status.Outcome != "completed" || status.ExitCode != -1 {
+		t.Error("Wrong status", status)
+	}
+	if !strings.Contains(read("1", "status.json"), `"stdout": 6`) {
+		t.Error("Wrong status for runner 1", read("1", "status.json"))
+	}
+}
+
+func TestResultsDirError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(file, nil, 0o644)
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithResultsDir(file)) // Not a directory
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("x")) })
+	grp.Run()
+	if err := grp.Wait(); err == nil {
+		t.Error("Expected results directory error")
+	}
+	if stdout.String() != "x" {
+		t.Error("Output should be unaffected", stdout.String())
+	}
+}
+
+func TestSkipExistingResults(t *testing.T) {
+	dir := t.TempDir()
+	run := func(fail bool) (ran []int, out string, err error) {
+		var stdout bytes.Buffer
+		var mu sync.Mutex
+		grp, err := NewGroup(WithStdout(&stdout), WithResultsDir(dir),
+			SkipExistingResults(true))
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		for ix := 1; ix <= 3; ix++ {
+			grp.AddErr(strconv.Itoa(ix)+" ", "", func(out, err io.Writer) error {
+				mu.Lock()
+				ran = append(ran, ix)
+				mu.Unlock()
+				out.Write([]byte("run\n"))
+				if fail && ix == 2 {
+					return errors.New("failed")
+				}
+				return nil
+			})
+		}
+		grp.Run()
+		err = grp.Wait()
+		slices.Sort(ran)
+
+		return ran, stdout.String(), err
+	}
+
+	ran, _, err := run(true)
+	if len(ran) != 3 || err == nil {
+		t.Error("First run should run everything and fail", ran, err)
+	}
+	os.WriteFile(filepath.Join(dir, "3", "status.json"), []byte("{"), 0o644) // Corrupt
+
+	ran, out, err := run(false)
+	if !slices.Equal(ran, []int{2, 3}) || err != nil {
+		t.Error("Second run should only run failed and corrupt runners", ran, err)
+	}
+	if out != "2 run\n3 run\n" {
+		t.Errorf("Skipped runner should produce no output %q", out)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "1", "stdout")); string(b) != "run\n" {
+		t.Error("Skipped runner directory should be untouched", string(b))
+	}
+
+	ran, _, _ = run(false)
+	if len(ran) != 0 {
+		t.Error("Third run should run nothing", ran)
+	}
+}
+
+// Test that runners skipped by SkipExistingResults, including those at the end, leave no
+// separators.
+func TestSkipExistingResultsSeparators(t *testing.T) {
+	dir := t.TempDir()
+	var stdout bytes.Buffer
+	run := func() {
+		stdout.Reset()
+		grp, err := NewGroup(WithStdout(&stdout), WithResultsDir(dir),
+			SkipExistingResults(true), WithStdoutSeparator("--\n"), LimitActiveRunners(1))
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		for ix := 1; ix <= 4; ix++ {
+			grp.AddErr(strconv.Itoa(ix)+" ", "", func(out, err io.Writer) error {
+				out.Write([]byte("x\n"))
+				if ix%2 == 1 {
+					return errors.New("failed")
+				}
+				return nil
+			})
+		}
+		grp.Run()
+		grp.Wait()
+	}
+
+	run()
+	run()
+	if actual, expect := stdout.String(), "1 x\n--\n3 x\n"; actual != expect {
+		t.Errorf("Expected %q, got %q", expect, actual)
+	}
+}
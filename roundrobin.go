@@ -0,0 +1,188 @@
+package parallel
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// The Round Robin Pipeline consists of head, lane, tagger, tail and
+// Group.stdout/Group.stderr. Each lane accumulates complete lines from one stream of one
+// runner and the Group rotor rotates between the lanes with pending lines, writing a line,
+// or a time-slice worth of lines, from each lane in turn. All active runners thus appear
+// to make progress concurrently while every line remains intact and attributable by its
+// tag. Any WithPipelineWriter middleware precedes the lane.
+func (rnr *runner) buildRoundRobinPipeline(grp *Group) {
+	var stdout, stderr writer
+	stdout = grp.newTail(grp.stdout, &grp.outputMu)
+	stderr = grp.newTail(grp.stderr, &grp.outputMu)
+	if grp.combined {
+		stderr = stdout
+	}
+	outTag, errTag := grp.tags(rnr)
+	if len(outTag) > 0 {
+		stdout = newTagger(stdout, outTag, grp.delim)
+	}
+	if len(errTag) > 0 {
+		stderr = newTagger(stderr, errTag, grp.delim)
+	}
+	if grp.mergeStderr {
+		stderr = stdout
+	}
+	stdout, stderr = newLane(stdout, grp.rotor, grp.delim), newLane(stderr, grp.rotor, grp.delim)
+	stdout, stderr = rnr.addMiddleware(grp, stdout, stderr)
+
+	rnr.buildHeads(grp, stdout, stderr)
+}
+
+// rotor serialises the output of all lanes. writeMu is held while writing downstream so
+// that a closing lane cannot overtake a line already taken by the rotor.
+type rotor struct {
+	writeMu sync.Mutex
+	mu      sync.Mutex // Protects everything below
+	cond    *sync.Cond
+	ready   []*lane // Lanes with pending lines in rotation order
+	next    int     // Index in ready of the next lane to be served
+	slice   time.Duration
+	clock   Clock
+	done    bool
+	exited  chan struct{}
+}
+
+func newRotor(slice time.Duration, clock Clock) *rotor {
+	rot := &rotor{slice: slice, clock: clock, exited: make(chan struct{})}
+	rot.cond = sync.NewCond(&rot.mu)
+
+	return rot
+}
+
+// run serves the lanes until finish is called.
+func (rot *rotor) run() {
+	defer close(rot.exited)
+	for {
+		rot.mu.Lock()
+		for len(rot.ready) == 0 && !rot.done {
+			rot.cond.Wait()
+		}
+		if len(rot.ready) == 0 { // Must be done
+			rot.mu.Unlock()
+			return
+		}
+		rot.mu.Unlock()
+		rot.serve()
+	}
+}
+
+// serve writes a line, or a time-slice worth of lines, from the next lane in rotation.
+func (rot *rotor) serve() {
+	rot.writeMu.Lock()
+	defer rot.writeMu.Unlock()
+	rot.mu.Lock()
+	defer rot.mu.Unlock()
+
+	if len(rot.ready) == 0 { // A closing lane may have emptied itself
+		return
+	}
+	rot.next %= len(rot.ready)
+	ln := rot.ready[rot.next]
+	deadline := rot.clock.Now().Add(rot.slice)
+	for len(ln.lines) > 0 {
+		line := ln.pop()
+		rot.mu.Unlock()
+		ln.out.Write(line)
+		rot.mu.Lock()
+		if !rot.clock.Now().Before(deadline) {
+			break
+		}
+	}
+	if len(ln.lines) > 0 {
+		rot.next++ // Otherwise pop removed ln from ready so next is the following lane
+	}
+}
+
+// finish causes run to return once all lanes are empty and waits for it to do so.
+func (rot *rotor) finish() {
+	rot.mu.Lock()
+	rot.done = true
+	rot.cond.Signal()
+	rot.mu.Unlock()
+	<-rot.exited
+}
+
+// lane is a writer which queues complete lines for the rotor. A partial line is held until
+// it is completed or the lane is closed.
+type lane struct {
+	commonWriter
+	rot     *rotor
+	delim   byte     // Line delimiter - see WithLineDelimiter
+	partial []byte   // Protected by rot.mu
+	lines   [][]byte // Protected by rot.mu
+}
+
+func newLane(out writer, rot *rotor, delim byte) *lane {
+	ln := &lane{rot: rot, delim: delim}
+	ln.setNext(out)
+
+	return ln
+}
+
+// Write never returns a downstream error as lines are written asynchronously.
+func (ln *lane) Write(p []byte) (int, error) {
+	ln.rot.mu.Lock()
+	defer ln.rot.mu.Unlock()
+
+	ln.partial = append(ln.partial, p...)
+	wasEmpty := len(ln.lines) == 0
+	for {
+		ix := bytes.IndexByte(ln.partial, ln.delim)
+		if ix < 0 {
+			break
+		}
+		ln.lines = append(ln.lines, ln.partial[:ix+1:ix+1])
+		ln.partial = ln.partial[ix+1:]
+	}
+	if wasEmpty && len(ln.lines) > 0 {
+		ln.rot.ready = append(ln.rot.ready, ln)
+		ln.rot.cond.Signal()
+	}
+
+	return len(p), nil
+}
+
+// pop removes and returns the oldest line, removing the lane from rotation if it has no
+// more lines. Caller must hold rot.mu.
+func (ln *lane) pop() []byte {
+	line := ln.lines[0]
+	ln.lines = ln.lines[1:]
+	if len(ln.lines) == 0 {
+		for ix, r := range ln.rot.ready {
+			if r == ln {
+				ln.rot.ready = append(ln.rot.ready[:ix], ln.rot.ready[ix+1:]...)
+				break
+			}
+		}
+	}
+
+	return line
+}
+
+// close writes all remaining output directly, bypassing the rotation, so that all output
+// has left the lane by the time the runner is flushed.
+func (ln *lane) close() {
+	ln.rot.writeMu.Lock()
+	ln.rot.mu.Lock()
+	var remaining [][]byte
+	for len(ln.lines) > 0 {
+		remaining = append(remaining, ln.pop())
+	}
+	if len(ln.partial) > 0 {
+		remaining = append(remaining, ln.partial)
+		ln.partial = nil
+	}
+	ln.rot.mu.Unlock()
+	for _, line := range remaining {
+		ln.out.Write(line)
+	}
+	ln.rot.writeMu.Unlock()
+	ln.out.close() // Pass it on
+}
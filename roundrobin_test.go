@@ -0,0 +1,104 @@
+package parallel
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoundRobin(t *testing.T) {
+	var stdout testLockedBuffer
+	grp, err := NewGroup(WithStdout(&stdout), OrderRunners(false), WithRoundRobin(0))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+
+	// Each runner only proceeds once the other runner's line has been written, which is
+	// only possible if the output of both is interleaved.
+	grp.Add("a: ", "", func(out, err io.Writer) {
+		out.Write([]byte("a1\na"))
+		waitFor(t, &stdout, "b: b1\n")
+		out.Write([]byte("2\n"))
+		waitFor(t, &stdout, "b: b2\n")
+		out.Write([]byte("partial")) // Written when the runner completes
+	})
+	grp.Add("b: ", "", func(out, err io.Writer) {
+		waitFor(t, &stdout, "a: a1\n")
+		out.Write([]byte("b1\n"))
+		waitFor(t, &stdout, "a: a2\n")
+		out.Write([]byte("b2\n"))
+	})
+	grp.Run()
+	grp.Wait()
+
+	exp := "a: a1\nb: b1\na: a2\nb: b2\na: partial"
+	if got := stdout.String(); got != exp {
+		t.Errorf("Wrong output. Got %q Expected %q", got, exp)
+	}
+}
+
+func TestRoundRobinSlice(t *testing.T) {
+	var stdout, stderr testLockedBuffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), OrderRunners(false),
+		WithRoundRobin(time.Millisecond))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	for _, tag := range []string{"x", "y", "z"} {
+		grp.Add(tag+":", tag+"!", func(out, err io.Writer) {
+			for range 100 {
+				out.Write([]byte("o\n"))
+				err.Write([]byte("e\n"))
+			}
+		})
+	}
+	grp.Run()
+	grp.Wait()
+
+	// Lines must remain intact and attributable regardless of rotation
+	for _, tag := range []string{"x", "y", "z"} {
+		if got := strings.Count(stdout.String(), tag+":o\n"); got != 100 {
+			t.Error(tag, "Wrong stdout line count", got)
+		}
+		if got := strings.Count(stderr.String(), tag+"!e\n"); got != 100 {
+			t.Error(tag, "Wrong stderr line count", got)
+		}
+	}
+}
+
+func TestRoundRobinConflicts(t *testing.T) {
+	if _, err := NewGroup(WithRoundRobin(0)); err == nil {
+		t.Error("Expected error with default OrderRunners(true)")
+	}
+	if _, err := NewGroup(OrderRunners(false), WithRoundRobin(-1)); err == nil {
+		t.Error("Expected error with negative slice")
+	}
+	for _, opt := range []Option{Passthru(true), Ungroup(true), WithJSONOutput(true),
+		WithFramedOutput(true), WithCompression(1), OrderStderr(true),
+		WithReport(ReportMarkdown), WithSectionHeaders("%s")} {
+		_, err := NewGroup(OrderRunners(false), WithRoundRobin(0), opt)
+		if err == nil || !strings.Contains(err.Error(), "WithRoundRobin") {
+			t.Error("Expected WithRoundRobin conflict, got", err)
+		}
+	}
+}
+
+// Output is grouped as usual when stdout is not a terminal in TTYAuto mode.
+func TestRoundRobinMode(t *testing.T) {
+	var stdout testLockedBuffer
+	grp, err := NewGroup(WithStdout(&stdout), OrderRunners(false), WithRoundRobin(0),
+		WithRoundRobinMode(TTYAuto))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("a: ", "", func(out, err io.Writer) { out.Write([]byte("a1\npartial")) })
+	grp.Run()
+	grp.Wait()
+	if grp.rotor != nil {
+		t.Error("Round robin should not be active for a non-terminal")
+	}
+	if got := stdout.String(); got != "a: a1\na: partial" {
+		t.Errorf("Wrong output %q", got)
+	}
+}
@@ -1,78 +1,301 @@
 package parallel
 
 import (
-	"container/list"
+	"context"
+	"os/exec"
+	"runtime/debug"
 	"sync"
+	"time"
 )
 
 // runner manages the life-cycle and pipeline of each RunFunc.
 type runner struct {
-	rFunc          RunFunc // Function started as a goroutine by Run()
-	outTag, errTag []byte  // Prepended to each output line
+	rFunc          runFunc       // Function started as a goroutine by Run()
+	index          int           // Order of addition to the Group, starting at zero
+	outTag, errTag []byte        // Prepended to each output line
+	err            error         // Returned by rFunc - only valid after completion
+	skipped        bool          // rFunc was never called - only valid after completion
+	omitted        bool          // Skipped without trace - see WithResume and WithShard
+	resultsDone    bool          // Results directory shows a prior success
+	queued         time.Time     // When the runner became eligible to start
+	started        time.Time     // When rFunc was called - only valid after completion
+	duration       time.Duration // How long rFunc ran - only valid after completion
+	capture        *capture      // Only set if CaptureOutput is set
+	results        *runnerDir    // Only set if WithResultsDir is set
+	collapse       *capture      // Only set if WithCollapse is set
+	discardOut     bool          // DiscardStdout or RunnerDiscardStdout
+	discardErr     bool          // DiscardStderr or RunnerDiscardStderr
+	span           Span          // Only set if WithTracer is set
+	detached       bool          // Abandoned by the Group - see WithDetach
+	cost           int64         // Expected cost supplied by RunnerCost
+	name           string        // Supplied by RunnerName
+	cmd            *exec.Cmd     // Only set by AddCommand
+	pty            bool          // WithCommandPTY or RunnerPTY
+	tagSuffix      string        // WithTagSuffix or RunnerTagSuffix
+	suffixTags     bool          // If tagSuffix is appended to the tags
+	slot           int           // Job slot while running - see Slot()
+	writeErr       error         // *WriteError if the queue could not be drained
+	dedupKey       string        // Supplied by the DedupRunners function
+	dedupOrig      *runner       // Only set for a duplicate - see DedupRunners
+	dedupCap       *capture      // Only set for an original replayed to duplicates
+	dedupDone      chan struct{} // Closed once an original replayed to duplicates is done
+
+	notify     chan<- RunnerResult // Supplied by RunnerNotify
+	newDecoder func() Transformer  // WithSourceEncoding or RunnerSourceEncoding
 
 	sync.RWMutex          // Protects everything below here
 	stdout, stderr writer // Immutable "head" supplied to Run()
 	queue          *queue // Remember queue so we can flush() it
 	canClose       bool   // If Wait() has read this runner from completed channel
+	removed        bool   // If Wait() has closed and printed this runner
+	fed            bool   // If feeder() has passed this runner to the workers
 }
 
 // newRunner constructs a skeletal runner with an empty pipeline.
-func newRunner(outTag, errTag string, rFunc RunFunc) *runner {
+func newRunner(outTag, errTag string, rFunc runFunc) *runner {
 	return &runner{outTag: []byte(outTag), errTag: []byte(errTag), rFunc: rFunc}
 }
 
 // The Queue Pipeline consists of head, queue tagger, tail and Group.stdout/Group.stderr
 // built in reverse order as it's stored as a singly linked list. A Queue Pipeline starts
-// out in background mode.
+// out in background mode. Any WithPipelineWriter middleware sits between the queue and
+// the tagger. Any CollapseProgress collapser precedes the queue so that overwritten
+// progress lines are never buffered.
 func (rnr *runner) buildQueuePipeline(grp *Group) {
-	var stdout, stderr writer
-	stdout = newTail(grp.stdout, &grp.outputMu)
-	stderr = newTail(grp.stderr, &grp.outputMu)
-
-	// Tagging is optional, so leave them out if not set
-	if len(rnr.outTag) > 0 {
-		stdout = newTagger(stdout, rnr.outTag)
-	}
-	if len(rnr.errTag) > 0 {
-		stderr = newTagger(stderr, rnr.errTag)
-	}
+	stdout, stderr := rnr.buildTaggedTails(grp, &grp.outputMu)
+	stdout, stderr = rnr.addMiddleware(grp, stdout, stderr)
 
 	// Queue creates two writers which share an output buffer for sequencing and
 	// background storage purposes. We remember one of the Queue writers so that we
 	// can switch it to foreground at a later time.
 
 	rnr.queue, stderr = newQueue(grp.orderStderr, grp.limitMemory, stdout, stderr)
+	rnr.queue.cq.buf.spillDir = grp.spillDir
+	rnr.queue.cq.buf.coalesce = grp.coalesce
+	rnr.queue.cq.buf.compress = grp.compressBuffers
+	rnr.queue.cq.buf.expected = grp.expectedOutput
+	if grp.blocked != nil || grp.logger != nil || grp.onBlocked != nil {
+		rnr.queue.cq.onBlock = func(used uint64) { grp.onBlock(rnr, used) }
+	}
+	if grp.softMemory > 0 {
+		rnr.queue.cq.soft, rnr.queue.cq.maxDelay = grp.softMemory, grp.throttleDelay
+		rnr.queue.cq.clock = grp.clock
+		rnr.queue.cq.onThrottle = func(used uint64) { grp.onThrottle(rnr, used) }
+	}
 	stdout = rnr.queue
+	if grp.collapseCR {
+		stdout = newCRCollapser(stdout, rnr.queue.isForeground, grp.delim)
+		stderr = newCRCollapser(stderr, rnr.queue.isForeground, grp.delim)
+	}
 
-	stdout = newHead(stdout)
-	stderr = newHead(stderr)
-
-	rnr.stdout = stdout
-	rnr.stderr = stderr
+	rnr.buildHeads(grp, stdout, stderr)
 }
 
-// The Passthru Pipeline consists of head, tail and Group.stdout/Group.stderr which
-// eliminates all writers with state but still retains concurrency protection for the
-// Group io.Writers. So, not strictly a fully transparent passthru, but as close as we can
-// get while still protecting Group outputs.
+// The Passthru Pipeline consists of head, tagger, tail and Group.stdout/Group.stderr which
+// eliminates all writers with output state but still retains concurrency protection for
+// the Group io.Writers. So, not strictly a fully transparent passthru, but as close as we
+// can get while still protecting Group outputs and identifying which runner produced
+// which line. Any WithPipelineWriter middleware precedes the tagger.
 func (rnr *runner) buildPassthruPipeline(grp *Group) {
-	rnr.stdout = newHead(newTail(grp.stdout, &grp.outputMu))
-	rnr.stderr = newHead(newTail(grp.stderr, &grp.outputMu))
+	var stdout, stderr writer
+	stdout = grp.newTail(grp.stdout, &grp.outputMu)
+	stderr = grp.newTail(grp.stderr, &grp.outputMu)
+	if grp.combined {
+		stderr = stdout
+	}
+	outTag, errTag := grp.tags(rnr)
+	if len(outTag) > 0 {
+		stdout = newTagger(stdout, outTag, grp.delim)
+	}
+	if len(errTag) > 0 {
+		stderr = newTagger(stderr, errTag, grp.delim)
+	}
+	if grp.mergeStderr {
+		stderr = stdout
+	}
+	stdout, stderr = rnr.addMiddleware(grp, stdout, stderr)
+
+	rnr.buildHeads(grp, stdout, stderr)
+}
+
+// The Ungroup Pipeline consists of head, serialiser, tagger, tail and
+// Group.stdout/Group.stderr. It has no queue so all output is written immediately. The
+// serialiser holds the Group output mutex for the duration of each Write so that all the
+// tagged lines resulting from a single Write are contiguous, thus the tail does not need
+// to lock.
+func (rnr *runner) buildUngroupPipeline(grp *Group) {
+	stdout, stderr := rnr.buildTaggedTails(grp, nil)
+	stdout, stderr = rnr.addMiddleware(grp, stdout, stderr)
+
+	rnr.buildHeads(grp, newSerialiser(stdout, &grp.outputMu),
+		newSerialiser(stderr, &grp.outputMu))
+}
+
+// buildHeads completes the front of every pipeline with the heads, preceded by the
+// capture writers if CaptureOutput is set so that the RunFunc output is captured exactly
+// as written, and by the results and record writers if WithResultsDir and WithRecorder
+// are set. A discarded stream
+// bypasses the rest of the pipeline entirely.
+func (rnr *runner) buildHeads(grp *Group, stdout, stderr writer) {
+	if rnr.discardOut { // Short-circuit the rest of the pipeline
+		stdout = discard{}
+	}
+	if rnr.discardErr {
+		stderr = discard{}
+	}
+	if grp.dedupReplay && len(rnr.dedupKey) > 0 { // Even if discarded
+		stdout = newDedupWriter(stdout, rnr, toStdout)
+		stderr = newDedupWriter(stderr, rnr, toStderr)
+	}
+	if grp.captureOutput {
+		rnr.capture = &capture{limit: grp.limitMemory}
+		stdout = newCaptureWriter(stdout, rnr.capture, toStdout)
+		stderr = newCaptureWriter(stderr, rnr.capture, toStderr)
+	}
+
+	// Even if discarded, but runners which are skipped without trace have no directory
+	if len(grp.resultsDir) > 0 && grp.inShard(rnr) && !grp.resumable(rnr) {
+		rr := newRunnerDir(grp.resultsDir, rnr)
+		if grp.resultsSkip && rr.succeeded() {
+			rnr.resultsDone = true // Leave the directory as is
+		} else {
+			rnr.results = rr
+			stdout = newResultsWriter(stdout, rr, resultsStdout)
+			stderr = newResultsWriter(stderr, rr, resultsStderr)
+		}
+	}
+	if grp.recorder != nil { // Records even discarded output
+		stdout = newRecordWriter(stdout, grp.recorder, rnr.index, Stdout)
+		stderr = newRecordWriter(stderr, grp.recorder, rnr.index, Stderr)
+	}
+
+	rnr.stdout = newHead(stdout)
+	rnr.stderr = newHead(stderr)
+}
+
+// buildTaggedTails constructs the tail end of the Queue and Ungroup pipelines which
+// consists of the optional taggers and the tails. With WithJSONOutput or
+// WithFramedOutput, the taggers are replaced with encoders and both streams are written to
+// Group.stdout. Any SuppressRepeats repeater sits after the tagger, any WithReport
+// reporters and WithSectionHeaders header writers sit before the tails and any
+// WithCompression compressor sits immediately before the tails. With MergeStderr, the stderr writers are
+// the stdout writers.
+func (rnr *runner) buildTaggedTails(grp *Group, outputMu *sync.Mutex) (stdout, stderr writer) {
+	errOut := grp.stderr
+	if grp.jsonOutput || grp.framedOutput || grp.report != ReportNone { // All to stdout
+		errOut = grp.stdout
+	}
+	stdout = grp.newTail(grp.stdout, outputMu)
+	stderr = grp.newTail(errOut, outputMu)
+	if grp.compressor != nil {
+		stdout = newCompressor(stdout, grp.compressor)
+		stderr = newCompressor(stderr, grp.compressor)
+	}
+	if grp.combined { // A single tail (and compressor) serves both streams
+		stderr = stdout
+	}
+	if len(grp.sectionFormat) > 0 {
+		sh := newSectionHeader(stdout, grp.sectionFormat, rnr.sectionName())
+		stdout, stderr = newHeaderWriter(stdout, sh), newHeaderWriter(stderr, sh)
+	}
+	if grp.report != ReportNone {
+		sec := newReportSection(stdout, grp.report, rnr)
+		stdout, stderr = newReporter(stdout, sec), newReporter(stderr, sec)
+	}
+
+	switch {
+	case grp.jsonOutput:
+		stdout = newJSONLines(stdout, rnr, Stdout, grp.delim)
+		stderr = newJSONLines(stderr, rnr, Stderr, grp.delim)
+	case grp.framedOutput:
+		stdout, stderr = newFramer(stdout, rnr, Stdout), newFramer(stderr, rnr, Stderr)
+	default: // Tagging is optional, so leave them out if not set
+		outTag, errTag := grp.tags(rnr)
+		if grp.suppressRepeats {
+			stdout = newRepeater(stdout, outTag, grp.delim)
+			stderr = newRepeater(stderr, errTag, grp.delim)
+		}
+		if len(outTag) > 0 {
+			stdout = newTagger(stdout, outTag, grp.delim)
+		}
+		if len(errTag) > 0 {
+			stderr = newTagger(stderr, errTag, grp.delim)
+		}
+	}
+
+	if grp.mergeStderr { // 2>&1 - both streams share the stdout writers
+		stderr = stdout
+	}
+
+	return
 }
 
 // switchToForeground is called when the runner is allowed to write directly to the Group
 // io.Writers. The queue writer manages the transition by releasing its queue of pending
-// writes and unblocking any blocked callers.
-func (rnr *runner) switchToForeground() {
-	rnr.queue.foreground()
+// writes and unblocking any blocked callers. It returns true if the runner was switched
+// by this call.
+func (rnr *runner) switchToForeground() bool {
+	if rnr.queue != nil {
+		return rnr.queue.foreground()
+	}
+
+	return false
+}
+
+// drainErr returns the first downstream error detected while draining the queue, if any.
+// It is only valid once the runner has been closed.
+func (rnr *runner) drainErr() error {
+	if rnr.queue == nil {
+		return nil
+	}
+	rnr.queue.cq.Lock()
+	defer rnr.queue.cq.Unlock()
+
+	return rnr.queue.cq.drainErr
+}
+
+// run the RunFunc. This function is called by the worker goroutine which then notifies
+// [Group.Wait] of completion. Each RunFunc is given its own context derived from the Group
+// context which is cancelled as soon as the RunFunc returns. The returned error is saved
+// prior to notifying completion so that [Group.Wait] can safely access it.
+//
+// A panicking RunFunc is recovered and recorded as a *PanicError. Completion is notified
+// as normal so that buffered output is flushed and the Group continues to progress.
+func (rnr *runner) run(grpCtx context.Context, clock Clock) {
+	ctx, cancel := context.WithCancel(grpCtx)
+	rnr.started = clock.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			rnr.err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+		rnr.duration = since(clock, rnr.started)
+		cancel()
+	}()
+
+	rnr.err = rnr.rFunc(ctx, rnr.stdout, rnr.stderr)
+}
+
+// skip records that the RunFunc was never run.
+func (rnr *runner) skip(err error) {
+	rnr.err = err
+	rnr.skipped = true
+}
+
+// written returns the number of bytes written by the RunFunc to stdout and stderr. It is
+// concurrency safe and returns zero if the pipeline has not yet been built.
+func (rnr *runner) written() (stdout, stderr int64) {
+	if rnr.stdout == nil {
+		return 0, 0
+	}
+
+	return rnr.stdout.(*head).written.Load(), rnr.stderr.(*head).written.Load()
 }
 
-// run the RunFunc and notify completion to [Group.Wait]. This function is called by the
-// RunFunc goroutine so nothing is stalled by potentially blocking on the completion
-// channel.
-func (rnr *runner) run(e *list.Element, completed chan *list.Element) {
-	rnr.rFunc(rnr.stdout, rnr.stderr)
-	completed <- e
+// omit records that the RunFunc was skipped without trace because it previously
+// succeeded or because it belongs to another shard.
+func (rnr *runner) omit() {
+	rnr.skip(nil)
+	rnr.omitted = true
 }
 
 // Flush all pending output
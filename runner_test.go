@@ -1,7 +1,10 @@
 package parallel
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"slices"
 	"testing"
 )
@@ -53,3 +56,61 @@ func testGetWriters(rnr *runner) (outWriters, errWriters []string) {
 
 	return
 }
+
+// Test that a panicking RunFunc is recovered, its output retained and the panic recorded.
+func TestRunnerPanic(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), LimitActiveRunners(1))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	grp.Add("", "", func(out, err io.Writer) {
+		out.Write([]byte("before panic\n"))
+		panic("oops")
+	})
+	grp.Add("", "", func(out, err io.Writer) {
+		out.Write([]byte("after panic\n"))
+	})
+	grp.Run()
+	err = grp.Wait()
+
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatal("Expected Wait to return a PanicError, not", err)
+	}
+	if pe.Value != "oops" {
+		t.Error("PanicError should record panic value, not", pe.Value)
+	}
+	if len(pe.Stack) == 0 {
+		t.Error("PanicError should record a stack trace")
+	}
+	if grp.Errors()[1] != nil {
+		t.Error("Second runner should not have an error", grp.Errors()[1])
+	}
+
+	actual := stdout.String()
+	expect := "before panic\nafter panic\n"
+	if actual != expect {
+		t.Error("Output mismatch.\nExpect:\n", expect, "\nActual\n", actual)
+	}
+}
+
+func TestRunnerBuildUngroup(t *testing.T) {
+	grp, err := NewGroup(Ungroup(true), OrderRunners(false))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	rnr := newRunner("out", "err", nil)
+	rnr.buildUngroupPipeline(grp)
+
+	ow, ew := testGetWriters(rnr)
+	expect := []string{"*parallel.head", "*parallel.serialiser", "*parallel.tagger",
+		"*parallel.tail"}
+	if slices.Compare(expect, ow) != 0 {
+		t.Error("Ungroup Pipeline stdout mismatch got", ow, "expect", expect)
+	}
+	if slices.Compare(expect, ew) != 0 {
+		t.Error("Ungroup Pipeline stderr mismatch got", ew, "expect", expect)
+	}
+}
@@ -0,0 +1,94 @@
+package parallel
+
+import (
+	"io"
+)
+
+// RunnerOption functions configure an individual runner when supplied to any of the Add
+// variants, such as [Group.Add]. Where a RunnerOption has a Group-wide [Option]
+// equivalent, the RunnerOption overrides the Group-wide setting for that runner only.
+type RunnerOption interface {
+	applyRunner(rnr *runner)
+}
+
+type runnerOption func(*runner)
+
+func (o runnerOption) applyRunner(rnr *runner) {
+	o(rnr)
+}
+
+// RunnerDiscardStdout overrides [DiscardStdout] for a single runner.
+func RunnerDiscardStdout(on bool) RunnerOption {
+	return runnerOption(func(rnr *runner) { rnr.discardOut = on })
+}
+
+// RunnerDiscardStderr overrides [DiscardStderr] for a single runner.
+func RunnerDiscardStderr(on bool) RunnerOption {
+	return runnerOption(func(rnr *runner) { rnr.discardErr = on })
+}
+
+// RunnerCost supplies an expected cost for a single runner, such as the size of the file
+// it processes. The cost is passed to a [Scheduler] as [ScheduledRunner].Cost and is used
+// by [SJFScheduler] to dispatch the cheapest runners first. The units are arbitrary but
+// should be consistent across all runners in the Group. Runners without a RunnerCost
+// have a cost of zero.
+func RunnerCost(cost int64) RunnerOption {
+	return runnerOption(func(rnr *runner) { rnr.cost = cost })
+}
+
+// RunnerName gives a runner a name which is used in place of the OutTag by
+// [WithSectionHeaders] and which is reported in [RunnerInfo].
+func RunnerName(name string) RunnerOption {
+	return runnerOption(func(rnr *runner) { rnr.name = name })
+}
+
+// RunnerPTY overrides [WithCommandPTY] for a single runner. It has no effect on runners
+// not created by [Group.AddCommand].
+func RunnerPTY(on bool) RunnerOption {
+	return runnerOption(func(rnr *runner) { rnr.pty = on })
+}
+
+// RunnerTagSuffix overrides [WithTagSuffix] for a single runner. The suffix is appended
+// to the non-empty tags of the runner even if WithTagSuffix is not set.
+func RunnerTagSuffix(suffix string) RunnerOption {
+	return runnerOption(func(rnr *runner) { rnr.tagSuffix, rnr.suffixTags = suffix, true })
+}
+
+// RunnerSourceEncoding overrides [WithSourceEncoding] for a single runner, such as the one
+// legacy tool in a Group. A nil newDecoder disables conversion for the runner.
+func RunnerSourceEncoding(newDecoder func() Transformer) RunnerOption {
+	return runnerOption(func(rnr *runner) { rnr.newDecoder = newDecoder })
+}
+
+// RunnerNotify sends the [RunnerResult] of a single runner on ch once all of its output
+// has been written to the Group io.Writers. The send blocks the Group until it is
+// received, so ch should normally be buffered. A common pattern is to give each runner
+// of interest its own channel with a capacity of one and use it as a completion handle:
+//
+//	done := make(chan parallel.RunnerResult, 1)
+//	group.Add("", "", rFunc, parallel.RunnerNotify(done))
+//	group.Run()
+//	group.Done() // Progress the Group in the background
+//	res := <-done
+//
+// As output is written by [Group.Wait], or by [Group.Done] in the background, the result
+// is only sent while one of them is progressing the Group. ch is never closed by the
+// Group. See [WithNotify] for Group-wide notification.
+func RunnerNotify(ch chan<- RunnerResult) RunnerOption {
+	return runnerOption(func(rnr *runner) { rnr.notify = ch })
+}
+
+// discard is a terminal writer which discards everything, much like io.Discard.
+type discard struct{}
+
+func (discard) getNext() writer { return nil }
+func (discard) setNext(writer)  {}
+func (discard) close()          {}
+
+func (discard) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (discard) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(io.Discard, r)
+}
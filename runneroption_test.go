@@ -0,0 +1,37 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestDiscard(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), DiscardStderr(true),
+		LimitMemoryPerRunner(4), LimitActiveRunners(2))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	write := func(out, err io.Writer) {
+		out.Write([]byte("out\n"))
+		err.Write([]byte("a lot of discarded stderr which exceeds the memory limit\n"))
+	}
+	grp.Add("a: ", "ae: ", write)
+	grp.Add("b: ", "be: ", write, RunnerDiscardStdout(true))
+	grp.Add("c: ", "ce: ", write, RunnerDiscardStderr(false))
+	grp.Run()
+	grp.Wait()
+
+	if stdout.String() != "a: out\nc: out\n" {
+		t.Errorf("Wrong stdout %q", stdout.String())
+	}
+	if stderr.String() != "ce: a lot of discarded stderr which exceeds the memory limit\n" {
+		t.Errorf("Wrong stderr %q", stderr.String())
+	}
+
+	// Discarded output still counts as written
+	if _, e := grp.all[0].written(); e == 0 {
+		t.Error("Discarded bytes should still be counted")
+	}
+}
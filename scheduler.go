@@ -0,0 +1,145 @@
+package parallel
+
+// ScheduledRunner describes a runner offered to a [Scheduler].
+type ScheduledRunner struct {
+	Index    int    // Order in which the runner was added, starting at zero
+	OutTag   string // As supplied to Add
+	ErrTag   string // As supplied to Add
+	Cost     int64  // As supplied by RunnerCost
+	Buffered int64  // Output bytes buffered on behalf of the RunFunc - zero if pending
+	Blocked  bool   // A Write is stalled by LimitMemoryPerRunner
+}
+
+// Scheduler decides which pending runner is dispatched to a worker next and, with
+// OrderRunners(false), which active runner is promoted to foreground. A Scheduler is
+// supplied with [WithScheduler]. The default Scheduler is [FIFOScheduler].
+//
+// Scheduler methods are called while the Group is locked so they must return promptly
+// and must not call any Group methods. Slices passed to a Scheduler are only valid for
+// the duration of the call.
+type Scheduler interface {
+	// Next returns the index in pending of the runner to dispatch next. pending is
+	// never empty and is in creation order, or [OrderBy] order if that is set. An out
+	// of range index selects the first pending runner. Next is called each time a
+	// worker accepts the previously dispatched runner, so runners added to an
+	// [OpenEnded] Group are considered from the subsequent call.
+	Next(pending []ScheduledRunner) int
+
+	// Foreground returns the index in active of the runner to promote to foreground, or
+	// -1 to promote none. It is only called with OrderRunners(false) when no runner is
+	// in foreground, such as when a runner starts, completes or blocks on its
+	// [LimitMemoryPerRunner] limit. A promoted runner writes directly to the Group
+	// io.Writers until it completes. Runners which complete in the meantime have their
+	// output deferred until the promoted runner completes. active is in creation order.
+	Foreground(active []ScheduledRunner) int
+}
+
+// FIFOScheduler is the default [Scheduler]. It dispatches runners in the order in which
+// they were added. With OrderRunners(false), it only promotes a runner to foreground when
+// every active runner might otherwise stall on its [LimitMemoryPerRunner] limit, in which
+// case it chooses the blocked runner with the most buffered output.
+type FIFOScheduler struct{}
+
+// Next always returns the oldest pending runner.
+func (FIFOScheduler) Next(pending []ScheduledRunner) int {
+	return 0
+}
+
+// Foreground returns the blocked runner with the most buffered output, if any.
+func (FIFOScheduler) Foreground(active []ScheduledRunner) int {
+	best := -1
+	for ix, sr := range active {
+		if sr.Blocked && (best == -1 || sr.Buffered > active[best].Buffered) {
+			best = ix
+		}
+	}
+
+	return best
+}
+
+// SJFScheduler is a shortest-job-first [Scheduler] which dispatches the pending runner with
+// the lowest [RunnerCost] next, with ties dispatched in the order they were added. It is
+// intended for OrderRunners(false) with [LimitActiveRunners] as it improves perceived
+// latency since the output of cheap runners appears quickly. With OrderRunners(true) the
+// output of cheap runners is still held back until all earlier runners have completed.
+// Foreground promotion is the same as [FIFOScheduler].
+type SJFScheduler struct {
+	FIFOScheduler
+}
+
+// Next returns the oldest pending runner with the lowest cost.
+func (SJFScheduler) Next(pending []ScheduledRunner) int {
+	best := 0
+	for ix, sr := range pending {
+		if sr.Cost < pending[best].Cost {
+			best = ix
+		}
+	}
+
+	return best
+}
+
+// scheduled returns the ScheduledRunner describing rnr. Caller must hold grp.mu.
+func (rnr *runner) scheduled() ScheduledRunner {
+	sr := ScheduledRunner{Index: rnr.index, OutTag: string(rnr.outTag),
+		ErrTag: string(rnr.errTag), Cost: rnr.cost}
+	if rnr.fed && rnr.queue != nil {
+		used, blocked := rnr.queue.cq.usage()
+		sr.Buffered, sr.Blocked = int64(used), blocked
+	}
+
+	return sr
+}
+
+// nextRunner returns the next runner for the feeder to dispatch, or nil if none are
+// pending. Without a Scheduler this is always the oldest pending runner, otherwise every
+// pending runner is offered to the Scheduler. Caller must hold grp.mu.
+func (grp *Group) nextRunner() *runner {
+	for grp.nextFeed < len(grp.all) && grp.all[grp.nextFeed].fed {
+		grp.nextFeed++
+	}
+	if grp.nextFeed == len(grp.all) {
+		return nil
+	}
+
+	rnr := grp.all[grp.nextFeed]
+	if grp.scheduler != nil {
+		var pending []*runner
+		var infos []ScheduledRunner
+		for _, p := range grp.all[grp.nextFeed:] {
+			if !p.fed {
+				pending = append(pending, p)
+				infos = append(infos, p.scheduled())
+			}
+		}
+		if ix := grp.scheduler.Next(infos); ix >= 0 && ix < len(pending) {
+			rnr = pending[ix]
+		}
+	}
+	rnr.fed = true
+	grp.dedupRunner(rnr)
+
+	return rnr
+}
+
+// scheduleForeground asks the Scheduler which active runner to promote to foreground.
+// It returns nil if the Scheduler declines. Caller must hold grp.mu.
+func (grp *Group) scheduleForeground() *runner {
+	var active []*runner
+	var infos []ScheduledRunner
+	for ix := grp.front; ix < len(grp.all); ix++ {
+		rnr := grp.all[ix]
+		if rnr.fed && !rnr.removed && !rnr.canClose && rnr.queue != nil {
+			active = append(active, rnr)
+			infos = append(infos, rnr.scheduled())
+		}
+	}
+	if len(active) == 0 {
+		return nil
+	}
+	if ix := grp.scheduler.Foreground(infos); ix >= 0 && ix < len(active) {
+		return active[ix]
+	}
+
+	return nil
+}
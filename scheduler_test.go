@@ -0,0 +1,148 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testLIFOScheduler struct{ FIFOScheduler }
+
+func (testLIFOScheduler) Next(pending []ScheduledRunner) int {
+	return len(pending) - 1
+}
+
+type testPromoteScheduler struct {
+	FIFOScheduler
+	index int
+}
+
+func (s testPromoteScheduler) Foreground(active []ScheduledRunner) int {
+	for ix, sr := range active {
+		if sr.Index == s.index {
+			return ix
+		}
+	}
+
+	return -1
+}
+
+func TestSchedulerNext(t *testing.T) {
+	grp, err := NewGroup(WithStdout(io.Discard), LimitActiveRunners(1),
+		WithScheduler(testLIFOScheduler{}))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	var order []int // Serialised by LimitActiveRunners(1)
+	for ix := range 4 {
+		grp.Add("", "", func(out, err io.Writer) { order = append(order, ix) })
+	}
+	grp.Run()
+	grp.Wait()
+	if len(order) != 4 || order[0] != 3 || order[1] != 2 || order[2] != 1 || order[3] != 0 {
+		t.Error("Expected reverse dispatch order, got", order)
+	}
+}
+
+func TestSchedulerForeground(t *testing.T) {
+	var stdout testLockedBuffer
+	grp, err := NewGroup(WithStdout(&stdout), OrderRunners(false),
+		WithScheduler(testPromoteScheduler{index: 1}))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	release := make(chan struct{})
+	grp.Add("", "", func(out, err io.Writer) { <-release; out.Write([]byte("zero\n")) })
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("one\n")); <-release })
+	grp.Run()
+	grp.Done() // Promotion occurs in Wait
+
+	for range 100 { // Promoted runner writes directly to stdout
+		if stdout.String() == "one\n" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := stdout.String(); got != "one\n" {
+		t.Error("Expected promoted runner output, got", got)
+	}
+	close(release)
+	grp.Wait()
+	if got := stdout.String(); got != "one\nzero\n" {
+		t.Error("Wrong final output", got)
+	}
+}
+
+func TestSchedulerFIFO(t *testing.T) {
+	var s FIFOScheduler
+	if s.Next([]ScheduledRunner{{Index: 3}, {Index: 4}}) != 0 {
+		t.Error("FIFO should dispatch the oldest runner")
+	}
+	active := []ScheduledRunner{{Buffered: 10}, {Buffered: 5, Blocked: true},
+		{Buffered: 7, Blocked: true}}
+	if got := s.Foreground(active); got != 2 {
+		t.Error("Expected blocked runner with most buffered, got", got)
+	}
+	if got := s.Foreground(active[:1]); got != -1 {
+		t.Error("Expected no promotion without a blocked runner, got", got)
+	}
+}
+
+func TestSchedulerNil(t *testing.T) {
+	_, err := NewGroup(WithScheduler(nil))
+	if err == nil || !strings.Contains(err.Error(), "nil Scheduler") {
+		t.Error("Expected nil Scheduler error, got", err)
+	}
+}
+
+func TestSchedulerSJF(t *testing.T) {
+	grp, err := NewGroup(WithStdout(io.Discard), LimitActiveRunners(1), OrderRunners(false),
+		WithScheduler(SJFScheduler{}))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	var order []int // Serialised by LimitActiveRunners(1)
+	for ix, cost := range []int64{30, 10, 20, 10} {
+		grp.Add("", "", func(out, err io.Writer) { order = append(order, ix) },
+			RunnerCost(cost))
+	}
+	grp.Run()
+	grp.Wait()
+	if len(order) != 4 || order[0] != 1 || order[1] != 3 || order[2] != 2 || order[3] != 0 {
+		t.Error("Expected cheapest first dispatch order, got", order)
+	}
+}
+
+// A Scheduler which dispatches ahead of the foreground runner could fill every slot with
+// blocked background runners, so the combination is rejected.
+func TestSchedulerMemoryLimit(t *testing.T) {
+	addRunners := func(grp *Group) {
+		grp.Add("", "", func(out, err io.Writer) { out.Write(make([]byte, 100)) },
+			RunnerCost(100))
+		grp.Add("", "", func(out, err io.Writer) { out.Write(make([]byte, 100)) },
+			RunnerCost(1))
+	}
+	opts := []Option{WithStdout(io.Discard), WithScheduler(SJFScheduler{}),
+		LimitActiveRunners(1), LimitMemoryPerRunner(10)}
+	if _, err := NewGroup(opts...); !errors.Is(err, ErrSchedulerWithMemoryLimit) {
+		t.Error("Expected ErrSchedulerWithMemoryLimit, got", err)
+	}
+
+	for _, extra := range []Option{OrderRunners(false), WithSpillDir(t.TempDir()),
+		WithScheduler(FIFOScheduler{})} {
+		grp, err := NewGroup(append(opts, extra)...)
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		addRunners(grp)
+		grp.Run()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := grp.WaitContext(ctx); err != nil {
+			t.Error("Group should complete, got", err)
+		}
+		cancel()
+	}
+}
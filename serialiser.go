@@ -0,0 +1,32 @@
+package parallel
+
+import (
+	"sync"
+)
+
+// serialiser is a writer which holds the group-wide output mutex for the duration of each
+// Write so that any multiple downstream Writes which result, such as those from a tagger,
+// are not intermingled with the output of other runners. Downstream tails must not try
+// to acquire the same mutex.
+type serialiser struct {
+	commonWriter
+	outputMu *sync.Mutex
+}
+
+func newSerialiser(out writer, outputMu *sync.Mutex) *serialiser {
+	wtr := &serialiser{outputMu: outputMu}
+	wtr.setNext(out)
+
+	return wtr
+}
+
+func (wtr *serialiser) Write(p []byte) (n int, err error) {
+	wtr.outputMu.Lock()
+	defer wtr.outputMu.Unlock()
+
+	return wtr.out.Write(p)
+}
+
+func (wtr *serialiser) close() {
+	wtr.out.close() // Pass it on
+}
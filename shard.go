@@ -0,0 +1,11 @@
+package parallel
+
+// inShard returns true if rnr belongs to the shard run by this Group. Every runner
+// belongs to the shard if WithShard is not set.
+func (grp *Group) inShard(rnr *runner) bool {
+	if grp.shardTotal == 0 {
+		return true
+	}
+
+	return rnr.index%grp.shardTotal == grp.shardIndex
+}
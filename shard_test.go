@@ -0,0 +1,109 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWithShard(t *testing.T) {
+	for _, tc := range [][2]int{{0, 0}, {-1, 2}, {2, 2}} {
+		if _, err := NewGroup(WithShard(tc[0], tc[1])); err == nil {
+			t.Error("Expected error with", tc)
+		}
+	}
+
+	const runners, total = 30, 3
+	seen := make([]int, runners)
+	for shard := 0; shard < total; shard++ {
+		var stdout bytes.Buffer
+		grp, err := NewGroup(WithStdout(&stdout), WithStderr(io.Discard),
+			WithShard(shard, total))
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		for ix := 0; ix < runners; ix++ {
+			grp.Add("", "", func(out, err io.Writer) {
+				seen[ix]++
+				out.Write([]byte(strconv.Itoa(ix) + "\n"))
+			})
+		}
+		grp.Run()
+		grp.Wait()
+
+		lines := strings.Count(stdout.String(), "\n")
+		if lines != runners/total {
+			t.Error(shard, "Shard should run", runners/total, "RunFuncs, not", lines)
+		}
+		skipped := 0
+		for ix := 0; ix < runners; ix++ {
+			if grp.RunnerResult(ix).Outcome == Skipped {
+				skipped++
+			}
+		}
+		if skipped+lines != runners {
+			t.Error(shard, "Expected", runners-lines, "skipped runners, not", skipped)
+		}
+	}
+	for ix, count := range seen {
+		if count != 1 {
+			t.Error(ix, "RunFunc should run in exactly one shard, not", count)
+		}
+	}
+
+	// Shard sizes differ by at most one when runners don't divide evenly
+	for shard, size := range []int{3, 2, 2} {
+		grp, _ := NewGroup(WithStdout(io.Discard), WithShard(shard, total))
+		for ix := 0; ix < 7; ix++ {
+			grp.Add("", "", func(out, err io.Writer) {})
+		}
+		grp.Run()
+		grp.Wait()
+		ran := 0
+		for ix := 0; ix < 7; ix++ {
+			if grp.RunnerResult(ix).Outcome != Skipped {
+				ran++
+			}
+		}
+		if ran != size {
+			t.Error(shard, "Expected shard size", size, "not", ran)
+		}
+	}
+}
+
+// Test that separators only appear between the RunFuncs which run in a shard, even when
+// the skipped RunFuncs come last.
+func TestWithShardSeparators(t *testing.T) {
+	const runners, total = 6, 3
+	for shard := 0; shard < total; shard++ {
+		var stdout bytes.Buffer
+		grp, err := NewGroup(WithStdout(&stdout), WithStderr(io.Discard),
+			WithShard(shard, total), WithStdoutSeparator("--\n"))
+		if err != nil {
+			t.Fatal("Unexpected setup error", err)
+		}
+		for ix := 0; ix < runners; ix++ {
+			grp.Add("", "", func(out, err io.Writer) {
+				out.Write([]byte(strconv.Itoa(ix) + "\n"))
+			})
+		}
+		grp.Run()
+		grp.Wait()
+
+		var ran []string
+		for ix := 0; ix < runners; ix++ {
+			if grp.RunnerResult(ix).Outcome != Skipped {
+				ran = append(ran, strconv.Itoa(ix)+"\n")
+			}
+		}
+		if grp.RunnerResult(runners-1).Outcome != Skipped {
+			continue // Only interested in shards where the last runner is skipped
+		}
+		expect := strings.Join(ran, "--\n")
+		if stdout.String() != expect {
+			t.Errorf("%d: Expected %q, got %q", shard, expect, stdout.String())
+		}
+	}
+}
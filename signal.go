@@ -0,0 +1,68 @@
+package parallel
+
+import (
+	"os"
+	"os/signal"
+	"sync/atomic"
+)
+
+// signalHandler implements WithSignalHandling. The first signal stops dispatch so that
+// active runners finish and their output is flushed normally. The second signal cancels
+// the context of all active runners, detaches all unfinished runners so that Wait returns
+// immediately, even if some RunFuncs ignore their context, and restores default signal
+// handling so that any further signal takes its default action, which is normally to
+// terminate the program.
+type signalHandler struct {
+	sigs        chan os.Signal
+	done        chan struct{}
+	finished    chan struct{}
+	interrupted atomic.Bool
+}
+
+func newSignalHandler(sigs []os.Signal) *signalHandler {
+	sh := &signalHandler{sigs: make(chan os.Signal, 2), done: make(chan struct{}),
+		finished: make(chan struct{})}
+	signal.Notify(sh.sigs, sigs...)
+
+	return sh
+}
+
+// run is the signal handling goroutine. It exits once finish is called.
+func (sh *signalHandler) run(grp *Group) {
+	defer close(sh.finished)
+	defer signal.Stop(sh.sigs)
+
+	select {
+	case <-sh.sigs:
+	case <-sh.done:
+		return
+	}
+	sh.interrupted.Store(true)
+	grp.stop(ErrInterrupted) // Stop dispatching new runners
+
+	select {
+	case <-sh.sigs:
+	case <-sh.done:
+		return
+	}
+	grp.cancel(ErrInterrupted) // Abort active runners
+	grp.mu.Lock()
+	grp.detach() // And stop waiting for them
+	grp.mu.Unlock()
+}
+
+// finish stops signal handling and waits for the run goroutine to exit.
+func (sh *signalHandler) finish() {
+	close(sh.done)
+	<-sh.finished
+}
+
+// err returns ErrInterrupted if a signal was received. It is safe to call with a nil
+// receiver.
+func (sh *signalHandler) err() error {
+	if sh != nil && sh.interrupted.Load() {
+		return ErrInterrupted
+	}
+
+	return nil
+}
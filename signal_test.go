@@ -0,0 +1,113 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestSignalHandling(t *testing.T) {
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), LimitActiveRunners(1),
+		WithSignalHandling(os.Interrupt))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	first := make(chan struct{})
+	second := make(chan struct{})
+	grp.Add("", "", func(out, err io.Writer) {
+		out.Write([]byte("one\n"))
+		close(first)
+		<-second
+	})
+	grp.Add("", "", func(out, err io.Writer) { out.Write([]byte("two\n")) })
+	grp.Add("", "", func(out, err io.Writer) {})
+	grp.Run()
+
+	<-first
+	grp.signals.sigs <- os.Interrupt // Simulate signal delivery
+	<-grp.dispatch.Done()
+	close(second)
+	err = grp.Wait()
+	if !errors.Is(err, ErrInterrupted) {
+		t.Error("Expected ErrInterrupted from Wait, got", err)
+	}
+	if stdout.String() != "one\n" {
+		t.Errorf("Expected only first runner output, got %q", stdout.String())
+	}
+	errs := grp.Errors()
+	if errs[0] != nil || errs[1] != ErrInterrupted || errs[2] != ErrInterrupted {
+		t.Error("Unexpected runner errors", errs)
+	}
+
+	// Second signal cancels active runners
+	grp, _ = NewGroup(WithStdout(&stdout), WithSignalHandling(os.Interrupt))
+	cause := make(chan error, 1) // Detached so Wait does not wait for the RunFunc
+	started := make(chan struct{})
+	grp.AddContext("", "", func(ctx context.Context, out, err io.Writer) {
+		close(started)
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+	})
+	grp.Run()
+	<-started
+	grp.signals.sigs <- os.Interrupt
+	grp.signals.sigs <- os.Interrupt
+	err = grp.Wait()
+	if c := <-cause; !errors.Is(err, ErrInterrupted) || c != ErrInterrupted {
+		t.Error("Expected ErrInterrupted from Wait and context, got", err, c)
+	}
+
+	// Second signal detaches runners which ignore their context so Wait returns
+	var leaked bytes.Buffer
+	stdout.Reset()
+	release := make(chan struct{})
+	defer close(release)
+	ignorer := func(text string, started chan struct{}) RunFunc {
+		return func(out, err io.Writer) {
+			out.Write([]byte(text))
+			close(started)
+			<-release // Ignores everything
+			out.Write([]byte("late\n"))
+		}
+	}
+	for _, leak := range []io.Writer{&leaked, nil} {
+		stdout.Reset()
+		opts := []Option{WithStdout(&stdout), WithSignalHandling(os.Interrupt),
+			LimitActiveRunners(2)}
+		if leak != nil {
+			opts = append(opts, WithDetach(leak))
+		}
+		grp, _ = NewGroup(opts...)
+		front := make(chan struct{})
+		back := make(chan struct{})
+		grp.Add("", "", ignorer("front\n", front))
+		grp.Add("", "", ignorer("buffered\n", back))
+		grp.Run()
+		<-front
+		<-back
+		grp.signals.sigs <- os.Interrupt
+		grp.signals.sigs <- os.Interrupt
+		err = grp.Wait()
+		if !errors.Is(err, ErrInterrupted) {
+			t.Error("Expected ErrInterrupted from Wait, got", err)
+		}
+		if errs := grp.Errors(); errs[0] != ErrDetached || errs[1] != ErrDetached {
+			t.Error("Expected ErrDetached for ignoring runners, got", errs)
+		}
+		if stdout.String() != "front\n" {
+			t.Errorf("Expected only foreground output, got %q", stdout.String())
+		}
+	}
+	if leaked.String() != "buffered\n" {
+		t.Errorf("Expected buffered output leaked, got %q", leaked.String())
+	}
+
+	_, err = NewGroup(WithSignalHandling())
+	if err == nil {
+		t.Error("Expected error from WithSignalHandling with no signals")
+	}
+}
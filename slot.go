@@ -0,0 +1,50 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+)
+
+// slots allocates job slot numbers, much like the GNU parallel “{%}” replacement string.
+// Each active RunFunc holds the lowest numbered slot which was free when it started, so
+// with [LimitActiveRunners] set to N, slots are always in the range 1 to N. RunFuncs can
+// use their slot to partition shared resources, such as a scratch directory or a port.
+type slots struct {
+	mu    sync.Mutex
+	inUse []bool // Index zero is slot 1
+}
+
+func (s *slots) acquire() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ix, used := range s.inUse {
+		if !used {
+			s.inUse[ix] = true
+			return ix + 1
+		}
+	}
+	s.inUse = append(s.inUse, true)
+
+	return len(s.inUse)
+}
+
+func (s *slots) release(slot int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inUse[slot-1] = false
+}
+
+type slotKey struct{}
+
+// Slot returns the job slot of the RunFunc which was passed ctx, or zero if ctx was not
+// supplied by a Group. Job slots start at 1 and each active RunFunc has a distinct slot,
+// with the lowest free slot allocated as each RunFunc starts. Commands run by
+// [Group.AddCommand] also have their slot set in the PARALLEL_SLOT environment variable.
+// The slot is also reported as [RunnerInfo].Slot.
+func Slot(ctx context.Context) int {
+	slot, _ := ctx.Value(slotKey{}).(int)
+
+	return slot
+}
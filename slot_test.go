@@ -0,0 +1,69 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestSlot(t *testing.T) {
+	grp, err := NewGroup(WithStdout(io.Discard), LimitActiveRunners(2))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	var mu sync.Mutex
+	active := make(map[int]bool)
+	for range 10 {
+		grp.AddContext("", "", func(ctx context.Context, out, err io.Writer) {
+			slot := Slot(ctx)
+			mu.Lock()
+			if slot < 1 || slot > 2 || active[slot] {
+				t.Error("Bad or duplicate slot", slot)
+			}
+			active[slot] = true
+			mu.Unlock()
+			runtime.Gosched()
+			mu.Lock()
+			active[slot] = false
+			mu.Unlock()
+		})
+	}
+	grp.Run()
+	grp.Wait()
+
+	if Slot(context.Background()) != 0 {
+		t.Error("Expected zero slot for a foreign context")
+	}
+}
+
+func TestSlotsAllocator(t *testing.T) {
+	var s slots
+	if s.acquire() != 1 || s.acquire() != 2 || s.acquire() != 3 {
+		t.Error("Expected ascending slots")
+	}
+	s.release(2)
+	if got := s.acquire(); got != 2 {
+		t.Error("Expected lowest free slot of 2, got", got)
+	}
+}
+
+func TestSlotCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Requires a POSIX shell")
+	}
+	var stdout bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), LimitActiveRunners(1))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.AddCommand("", "", exec.Command("sh", "-c", "echo $PARALLEL_SLOT"))
+	grp.Run()
+	grp.Wait()
+	if got := stdout.String(); got != "1\n" {
+		t.Errorf("Wrong PARALLEL_SLOT %q", got)
+	}
+}
@@ -0,0 +1,213 @@
+package sshlogin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/markdingo/parallel"
+)
+
+// connectFailure is the ssh exit status when the connection fails. It is also used by a
+// remote command which exits with 255, which is indistinguishable.
+const connectFailure = 255
+
+// Cluster runs commands on a set of remote hosts. The exported fields can be changed
+// after construction but must not be changed once commands are running.
+type Cluster struct {
+	Program string   // The ssh program. The default is "ssh"
+	Options []string // Additional ssh options, such as "-o", "BatchMode=yes"
+	Retries int      // Attempts to retry a command which fails to connect
+	Pool    bool     // Multiplex commands over one connection per host
+
+	mu         sync.Mutex
+	hosts      []*host
+	wake       chan struct{} // Closed and replaced whenever a slot is released
+	controlDir string        // Only set once Pool is used
+}
+
+// host tracks the use of one Login. Protected by Cluster.mu.
+type host struct {
+	Login
+	busy   int
+	pooled bool // A connection may have been multiplexed via the control socket
+}
+
+// New constructs a Cluster of the supplied logins.
+func New(logins ...Login) (*Cluster, error) {
+	if len(logins) == 0 {
+		return nil, errors.New("sshlogin: Cannot construct a Cluster without logins")
+	}
+	c := &Cluster{Program: "ssh", wake: make(chan struct{})}
+	for _, l := range logins {
+		if len(l.Dest) == 0 {
+			return nil, errors.New("sshlogin: Cannot supply a Login without a Dest")
+		}
+		c.hosts = append(c.hosts, &host{Login: l})
+	}
+
+	return c, nil
+}
+
+// Add adds a runner to grp which runs command on the first host with a free slot at the
+// time the runner starts. The runner error is the error returned by the ssh program, which
+// is an [*exec.ExitError] if the command exits with a non-zero status.
+func (c *Cluster) Add(grp *parallel.Group, outTag, errTag, command string,
+	opts ...parallel.RunnerOption) {
+	grp.AddContextErr(outTag, errTag,
+		func(ctx context.Context, stdout, stderr io.Writer) error {
+			return c.run(ctx, nil, command, stdout, stderr)
+		}, opts...)
+}
+
+// AddAll adds one runner per host to grp, each of which runs command on its host. The
+// stdout and stderr of each runner are tagged with the Tag of the host Login.
+func (c *Cluster) AddAll(grp *parallel.Group, command string, opts ...parallel.RunnerOption) {
+	for _, h := range c.hosts {
+		grp.AddContextErr(h.tag(), h.tag(),
+			func(ctx context.Context, stdout, stderr io.Writer) error {
+				return c.run(ctx, h, command, stdout, stderr)
+			}, opts...)
+	}
+}
+
+// Close releases any pooled connections. A Cluster can be used again after Close.
+func (c *Cluster) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.controlDir) == 0 {
+		return nil
+	}
+	for _, h := range c.hosts {
+		if h.pooled {
+			exec.Command(c.program(), c.args(h, "-O", "exit")...).Run()
+			h.pooled = false
+		}
+	}
+	err := os.RemoveAll(c.controlDir)
+	c.controlDir = ""
+
+	return err
+}
+
+// run runs command on a host, retrying connection failures up to Retries times. If only
+// is set, the command is only ever run on that host.
+func (c *Cluster) run(ctx context.Context, only *host, command string,
+	stdout, stderr io.Writer) (err error) {
+	var last *host
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		h := only
+		if h == nil {
+			h = last // Prefer a different host for retries
+		}
+		var args []string
+		h, args, err = c.acquire(ctx, h, only != nil)
+		if err != nil {
+			return err
+		}
+		cmd := exec.CommandContext(ctx, c.program(), append(args, command)...)
+		cmd.Stdout, cmd.Stderr = stdout, stderr
+		err = cmd.Run()
+		c.release(h)
+
+		var ee *exec.ExitError
+		if !errors.As(err, &ee) || ee.ExitCode() != connectFailure || ctx.Err() != nil {
+			return err
+		}
+		last = h
+	}
+
+	return err
+}
+
+// acquire waits for a free slot and returns the chosen host along with the ssh arguments
+// to reach it. If mustUse is set, only h is considered, otherwise the least busy host with
+// a free slot is chosen, preferring any host other than h.
+func (c *Cluster) acquire(ctx context.Context, h *host, mustUse bool) (*host, []string,
+	error) {
+	for {
+		c.mu.Lock()
+		var best *host
+		for _, cand := range c.hosts {
+			if cand.busy >= cand.slots() || (mustUse && cand != h) {
+				continue
+			}
+			if best == nil || (best == h && cand != h) ||
+				(cand != h && cand.busy*best.slots() < best.busy*cand.slots()) {
+				best = cand
+			}
+		}
+		if best != nil {
+			best.busy++
+			if c.Pool && !best.pooled {
+				if err := c.makeControlDir(); err != nil {
+					best.busy--
+					c.mu.Unlock()
+					return nil, nil, err
+				}
+				best.pooled = true
+			}
+			args := c.args(best)
+			c.mu.Unlock()
+			return best, args, nil
+		}
+		wake := c.wake
+		c.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return nil, nil, context.Cause(ctx)
+		}
+	}
+}
+
+func (c *Cluster) release(h *host) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h.busy--
+	close(c.wake)
+	c.wake = make(chan struct{})
+}
+
+// makeControlDir creates the directory holding the control sockets of pooled
+// connections. Caller must hold c.mu.
+func (c *Cluster) makeControlDir() (err error) {
+	if len(c.controlDir) == 0 {
+		c.controlDir, err = os.MkdirTemp("", "sshlogin")
+	}
+
+	return
+}
+
+func (c *Cluster) program() string {
+	if len(c.Program) > 0 {
+		return c.Program
+	}
+
+	return "ssh"
+}
+
+// args returns the ssh arguments up to and including the destination. Caller must hold
+// c.mu.
+func (c *Cluster) args(h *host, extra ...string) []string {
+	args := append([]string{}, c.Options...)
+	if h.Port > 0 {
+		args = append(args, "-p", strconv.Itoa(h.Port))
+	}
+	if h.pooled {
+		args = append(args, "-o", "ControlMaster=auto",
+			"-o", "ControlPath="+filepath.Join(c.controlDir, "%C"),
+			"-o", "ControlPersist=60")
+	}
+	args = append(args, extra...)
+
+	return append(args, h.Dest)
+}
@@ -0,0 +1,135 @@
+package sshlogin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/markdingo/parallel"
+)
+
+// fakeSSH writes a script which mimics ssh by running the command locally. A destination
+// of "down" fails as if the connection failed.
+func fakeSSH(t *testing.T) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("Requires a POSIX shell")
+	}
+	script := filepath.Join(t.TempDir(), "ssh")
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+while [ $# -gt 2 ]; do shift; done
+if [ "$1" = down ]; then
+	echo "ssh: connect to host down: Connection refused" >&2
+	exit 255
+fi
+exec sh -c "$2"
+`), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return script
+}
+
+func TestClusterAdd(t *testing.T) {
+	logins, _ := ParseLogins("2/host1", "host2")
+	c, err := New(logins...)
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	c.Program = fakeSSH(t)
+	var stdout bytes.Buffer
+	grp, _ := parallel.NewGroup(parallel.WithStdout(&stdout))
+	c.Add(grp, "a ", "", "echo one")
+	c.Add(grp, "b ", "", "exit 3")
+	grp.Run()
+	err = grp.Wait()
+
+	var ee *exec.ExitError
+	if !errors.As(err, &ee) || ee.ExitCode() != 3 {
+		t.Error("Expected exit code 3, got", err)
+	}
+	if got := stdout.String(); got != "a one\n" {
+		t.Error("Wrong output", got)
+	}
+}
+
+func TestClusterAddAll(t *testing.T) {
+	logins, _ := ParseLogins("host1", "host2")
+	logins[1].Tag = "two: "
+	c, _ := New(logins...)
+	c.Program = fakeSSH(t)
+	var stdout bytes.Buffer
+	grp, _ := parallel.NewGroup(parallel.WithStdout(&stdout))
+	c.AddAll(grp, "echo up")
+	grp.Run()
+	if err := grp.Wait(); err != nil {
+		t.Error("Unexpected error", err)
+	}
+	if got := stdout.String(); got != "host1\tup\ntwo: up\n" {
+		t.Errorf("Wrong output %q", got)
+	}
+}
+
+func TestClusterRetry(t *testing.T) {
+	logins, _ := ParseLogins("down", "host2")
+	c, _ := New(logins...)
+	c.Program = fakeSSH(t)
+	c.Retries = 1
+	var stdout, stderr bytes.Buffer
+	grp, _ := parallel.NewGroup(parallel.WithStdout(&stdout), parallel.WithStderr(&stderr),
+		parallel.LimitActiveRunners(1))
+	for range 2 { // One of which is first attempted on "down"
+		c.Add(grp, "", "", "echo ok")
+	}
+	grp.Run()
+	if err := grp.Wait(); err != nil {
+		t.Error("Expected retries to succeed, got", err)
+	}
+	if got := stdout.String(); got != "ok\nok\n" {
+		t.Errorf("Wrong output %q", got)
+	}
+
+	c.Retries = 0
+	grp, _ = parallel.NewGroup(parallel.WithStdout(&stdout), parallel.WithStderr(&stderr))
+	c.AddAll(grp, "echo ok")
+	grp.Run()
+	var ee *exec.ExitError
+	if err := grp.Wait(); !errors.As(err, &ee) || ee.ExitCode() != connectFailure {
+		t.Error("Expected connection failure from AddAll, got", err)
+	}
+	if !strings.Contains(stderr.String(), "Connection refused") {
+		t.Error("Expected ssh error on stderr", stderr.String())
+	}
+}
+
+func TestClusterPool(t *testing.T) {
+	c, _ := New(Login{Dest: "host1", Port: 2222})
+	c.Pool = true
+	c.Program = "true" // Only used by Close
+	h, args, err := c.acquire(context.Background(), nil, false)
+	if err != nil {
+		t.Fatal("Unexpected acquire error", err)
+	}
+	c.release(h)
+	joined := strings.Join(args, " ")
+	if !strings.Contains(joined, "-p 2222") || !strings.Contains(joined, "ControlMaster=auto") ||
+		!strings.HasSuffix(joined, " host1") {
+		t.Error("Wrong pooled args", joined)
+	}
+	dir := c.controlDir
+	if _, err := os.Stat(dir); err != nil {
+		t.Error("Control directory not created", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Error("Unexpected Close error", err)
+	}
+	if _, err := os.Stat(dir); err == nil {
+		t.Error("Control directory not removed by Close")
+	}
+}
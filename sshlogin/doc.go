@@ -0,0 +1,34 @@
+/*
+Package sshlogin runs commands on remote hosts over SSH and feeds their output through the
+pipelines of a [parallel.Group]. It covers the GNU parallel “--sshlogin” and “--nonall”
+use cases for Go tools.
+
+Rather than implementing the SSH protocol, sshlogin runs the system ssh program, much as
+GNU parallel does, so all of the usual ssh configuration, such as keys, agents and
+~/.ssh/config, applies unchanged. Each remote command is passed to ssh as a single argument
+and is thus interpreted by the remote shell.
+
+A [Cluster] is constructed from a list of [Login]s, each of which limits how many commands
+run concurrently on that host. [Cluster.Add] adds a runner which runs its command on
+whichever host has a free slot when the runner starts, while [Cluster.AddAll] adds one
+runner per host, each tagged with the host name:
+
+	logins, _ := sshlogin.ParseLogins("4/build1", "2/deploy@build2:2222")
+	cluster, err := sshlogin.New(logins...)
+	if err != nil {
+		return err
+	}
+	defer cluster.Close()
+	grp, _ := parallel.NewGroup()
+	for _, file := range files {
+		cluster.Add(grp, file+"\t", file+"\t", "wc -l "+file)
+	}
+	grp.Run()
+	grp.Wait()
+
+With [Cluster.Pool] set, all commands to the same host are multiplexed over a single
+connection with the OpenSSH “ControlMaster” facility, which avoids the cost of
+establishing a new connection for each command. With [Cluster.Retries] set, a command
+which fails to connect is retried, preferably on a different host.
+*/
+package sshlogin
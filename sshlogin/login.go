@@ -0,0 +1,69 @@
+package sshlogin
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Login describes a remote host and how many commands can be run on it concurrently.
+type Login struct {
+	Dest  string // [user@]host as passed to ssh
+	Port  int    // Zero means the ssh default
+	Slots int    // Maximum concurrent commands. Zero means one
+	Tag   string // Tag used by Cluster.AddAll. Empty means Dest followed by a tab
+}
+
+// ParseLogin parses a login in the GNU parallel “--sshlogin” format of
+// “[slots/][user@]host[:port]”, such as “8/admin@build1:2222”.
+func ParseLogin(s string) (Login, error) {
+	var l Login
+	if slots, rest, found := strings.Cut(s, "/"); found {
+		n, err := strconv.Atoi(slots)
+		if err != nil || n < 1 {
+			return l, errors.New("sshlogin: invalid slot count in " + strconv.Quote(s))
+		}
+		l.Slots = n
+		s = rest
+	}
+	if host, port, found := strings.Cut(s, ":"); found {
+		n, err := strconv.Atoi(port)
+		if err != nil || n < 1 || n > 65535 {
+			return l, errors.New("sshlogin: invalid port in " + strconv.Quote(s))
+		}
+		l.Port = n
+		s = host
+	}
+	if len(s) == 0 || strings.HasSuffix(s, "@") {
+		return l, errors.New("sshlogin: missing host in login")
+	}
+	l.Dest = s
+
+	return l, nil
+}
+
+// ParseLogins calls ParseLogin for each of s and returns the first error, if any.
+func ParseLogins(s ...string) ([]Login, error) {
+	logins := make([]Login, 0, len(s))
+	for _, ls := range s {
+		l, err := ParseLogin(ls)
+		if err != nil {
+			return nil, err
+		}
+		logins = append(logins, l)
+	}
+
+	return logins, nil
+}
+
+func (l Login) slots() int {
+	return max(1, l.Slots)
+}
+
+func (l Login) tag() string {
+	if len(l.Tag) > 0 {
+		return l.Tag
+	}
+
+	return l.Dest + "\t"
+}
@@ -0,0 +1,47 @@
+package sshlogin
+
+import (
+	"testing"
+)
+
+func TestParseLogin(t *testing.T) {
+	testCases := []struct {
+		in    string
+		login Login
+		err   bool
+	}{
+		{"host1", Login{Dest: "host1"}, false},
+		{"4/admin@host1:2222", Login{Dest: "admin@host1", Port: 2222, Slots: 4}, false},
+		{"2/host1", Login{Dest: "host1", Slots: 2}, false},
+		{"x/host1", Login{}, true},
+		{"0/host1", Login{}, true},
+		{"host1:0", Login{}, true},
+		{"host1:port", Login{}, true},
+		{"admin@", Login{}, true},
+		{"", Login{}, true},
+	}
+
+	for ix, tc := range testCases {
+		l, err := ParseLogin(tc.in)
+		if tc.err {
+			if err == nil {
+				t.Error(ix, "Expected error for", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error(ix, "Unexpected error", err)
+			continue
+		}
+		if l != tc.login {
+			t.Error(ix, "Wrong login", l, "expected", tc.login)
+		}
+	}
+
+	if _, err := ParseLogins("host1", "x/host2"); err == nil {
+		t.Error("Expected error from ParseLogins")
+	}
+	if l, _ := ParseLogin("host1"); l.tag() != "host1\t" || l.slots() != 1 {
+		t.Error("Wrong defaults", l.tag(), l.slots())
+	}
+}
@@ -0,0 +1,91 @@
+package parallel
+
+import (
+	"io"
+	"sync"
+)
+
+// Stage is the public equivalent of the internal pipeline writer. A Stage accepts output
+// from the previous Stage in a pipeline, transforms it in some way and writes the result
+// to the next Stage. Close is called once the RunFunc output is complete. Close must
+// write any held back data then Close the next Stage so that the close propagates all
+// the way down the pipeline.
+//
+// Reusable Stages can be constructed by third parties and added to every runner pipeline
+// with [WithStage]. The [NewHeadStage], [NewTaggerStage] and [NewTailStage] constructors
+// expose the standard writers so that Stages can be composed and tested independently of
+// a [Group].
+type Stage interface {
+	io.Writer
+	Close() error
+}
+
+// StageFunc constructs a [Stage] which writes to next. It is called for each of stdout
+// and stderr as each runner pipeline is constructed. See [WithStage].
+type StageFunc func(next Stage, info RunnerInfo) Stage
+
+// NewHeadStage returns the Stage which normally sits at the front of a pipeline, as seen
+// by the RunFunc.
+func NewHeadStage(next Stage) Stage {
+	return &stageAdapter{newHead(toWriter(next))}
+}
+
+// NewTaggerStage returns a Stage which prepends tag to each line written to next.
+func NewTaggerStage(next Stage, tag string) Stage {
+	return &stageAdapter{newTagger(toWriter(next), []byte(tag), '\n')}
+}
+
+// NewTailStage returns the Stage which normally sits at the end of a pipeline. It writes
+// to out while holding mu, if mu is non-nil. Closing a tail does not close out.
+func NewTailStage(out io.Writer, mu *sync.Mutex) Stage {
+	return &stageAdapter{newTail(out, mu)}
+}
+
+// stageAdapter presents an internal writer as a Stage.
+type stageAdapter struct {
+	wtr writer
+}
+
+func (sa *stageAdapter) Write(p []byte) (int, error) {
+	return sa.wtr.Write(p)
+}
+
+func (sa *stageAdapter) Close() error {
+	sa.wtr.close()
+
+	return nil
+}
+
+// stageWriter presents a Stage as an internal writer. The Stage is responsible for
+// closing its own next Stage so close is not passed on.
+type stageWriter struct {
+	commonWriter
+	stage Stage
+}
+
+func (wtr *stageWriter) Write(p []byte) (int, error) {
+	return wtr.stage.Write(p)
+}
+
+func (wtr *stageWriter) close() {
+	wtr.stage.Close()
+}
+
+// toWriter converts a Stage to an internal writer, avoiding a double wrap if the Stage is
+// merely an adapted internal writer.
+func toWriter(s Stage) writer {
+	if sa, ok := s.(*stageAdapter); ok {
+		return sa.wtr
+	}
+
+	return &stageWriter{stage: s}
+}
+
+// toStage is the inverse of toWriter.
+func toStage(w writer) Stage {
+	if sw, ok := w.(*stageWriter); ok {
+		return sw.stage
+	}
+
+	return &stageAdapter{w}
+}
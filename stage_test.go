@@ -0,0 +1,78 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// bracketStage wraps all output in brackets, writing the closing bracket on Close.
+type bracketStage struct {
+	next    Stage
+	started bool
+}
+
+func (bs *bracketStage) Write(p []byte) (int, error) {
+	if !bs.started {
+		bs.started = true
+		bs.next.Write([]byte("["))
+	}
+	bs.next.Write(p)
+
+	return len(p), nil
+}
+
+func (bs *bracketStage) Close() error {
+	if bs.started {
+		bs.next.Write([]byte("]"))
+	}
+
+	return bs.next.Close()
+}
+
+func TestStageStandalone(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewHeadStage(&bracketStage{next: NewTaggerStage(NewTailStage(&buf, nil), "x: ")})
+	s.Write([]byte("a\nb\n"))
+	s.Close()
+
+	expect := "x: [a\nx: b\nx: ]"
+	if buf.String() != expect {
+		t.Errorf("Expected %q, got %q", expect, buf.String())
+	}
+}
+
+func TestStageGroup(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr),
+		WithStage(func(next Stage, info RunnerInfo) Stage {
+			return &bracketStage{next: next}
+		}),
+		WithStage(func(next Stage, info RunnerInfo) Stage {
+			if info.Stream == Stderr {
+				return next // Stages can opt out
+			}
+			return NewTaggerStage(next, "<")
+		}))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("t: ", "e: ", func(out, err io.Writer) {
+		out.Write([]byte("a\n"))
+		err.Write([]byte("b\n"))
+	})
+	grp.Run()
+	grp.Wait()
+
+	if stdout.String() != "t: <[a\nt: <]" {
+		t.Errorf("Wrong stdout %q", stdout.String())
+	}
+	if stderr.String() != "e: [b\ne: ]" {
+		t.Errorf("Wrong stderr %q", stderr.String())
+	}
+
+	_, err = NewGroup(WithStage(nil))
+	if err == nil {
+		t.Error("Expected error from WithStage(nil)")
+	}
+}
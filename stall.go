@@ -0,0 +1,98 @@
+package parallel
+
+import "time"
+
+// StallInfo is supplied to the [WithStallWarning] function when no RunFunc has completed
+// for the stall duration.
+type StallInfo struct {
+	Since   time.Duration   // Time since a RunFunc last completed, or since Wait was called
+	Runners []StalledRunner // Runners whose RunFunc is still running, in creation order
+}
+
+// StalledRunner describes a runner whose RunFunc was still running when a stall was
+// detected.
+type StalledRunner struct {
+	Index    int    // Order in which the runner was added, starting at zero
+	OutTag   string // As supplied to Add
+	ErrTag   string // As supplied to Add
+	Buffered int64  // Output bytes buffered on behalf of the RunFunc
+	Blocked  bool   // A Write is stalled by LimitMemoryPerRunner
+}
+
+// stallDetector tracks the time since the last runner completion for WithStallWarning. A
+// nil stallDetector is valid and never fires.
+type stallDetector struct {
+	after time.Duration
+	clock Clock
+	timer Timer
+	last  time.Time
+}
+
+func newStallDetector(after time.Duration, clock Clock) *stallDetector {
+	if after <= 0 {
+		return nil
+	}
+
+	return &stallDetector{after: after, clock: clock, timer: clock.NewTimer(after),
+		last: clock.Now()}
+}
+
+// c returns the channel which fires when a stall is detected.
+func (sd *stallDetector) c() <-chan time.Time {
+	if sd == nil {
+		return nil
+	}
+
+	return sd.timer.C()
+}
+
+// completed restarts stall detection as a runner has completed.
+func (sd *stallDetector) completed() {
+	if sd != nil {
+		sd.last = sd.clock.Now()
+		sd.timer.Reset(sd.after)
+	}
+}
+
+// rearm restarts the timer after a stall has been reported so that continuing stalls are
+// reported periodically.
+func (sd *stallDetector) rearm() {
+	sd.timer.Reset(sd.after)
+}
+
+func (sd *stallDetector) stop() {
+	if sd != nil {
+		sd.timer.Stop()
+	}
+}
+
+// stallInfo assembles the StallInfo of all running runners. Caller must hold grp.mu.
+func (grp *Group) stallInfo(since time.Duration) StallInfo {
+	info := StallInfo{Since: since}
+	for ix := grp.front; ix < len(grp.all); ix++ {
+		rnr := grp.all[ix]
+		if !rnr.fed || rnr.removed || rnr.canClose {
+			continue
+		}
+		sr := StalledRunner{Index: rnr.index, OutTag: string(rnr.outTag),
+			ErrTag: string(rnr.errTag)}
+		if rnr.queue != nil {
+			outLen, errLen := rnr.queue.cq.len()
+			sr.Buffered = int64(outLen + errLen)
+			_, sr.Blocked = rnr.queue.cq.usage()
+		}
+		info.Runners = append(info.Runners, sr)
+	}
+
+	return info
+}
+
+// reportStall calls the WithStallWarning function without holding grp.mu so that the
+// function can safely call Group methods such as Metrics. Caller must hold grp.mu.
+func (grp *Group) reportStall(sd *stallDetector) {
+	info := grp.stallInfo(since(sd.clock, sd.last))
+	grp.mu.Unlock()
+	grp.stallFunc(info)
+	grp.mu.Lock()
+	sd.rearm()
+}
@@ -0,0 +1,74 @@
+package parallel
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStallWarning(t *testing.T) {
+	var mu sync.Mutex
+	var infos []StallInfo
+	release := make(chan struct{})
+	fn := func(info StallInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		infos = append(infos, info)
+		if len(infos) == 2 {
+			close(release)
+		}
+	}
+	clock := NewTestClock(time.Now())
+	grp, err := NewGroup(WithStdout(io.Discard), LimitActiveRunners(2), OrderRunners(false),
+		WithStallWarning(20*time.Millisecond, fn), WithClock(clock))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("quick", "", func(out, err io.Writer) {})
+	grp.Add("stuck", "", func(out, err io.Writer) {
+		out.Write([]byte("partial"))
+		<-release
+	})
+	grp.Run()
+	done := make(chan struct{})
+	go func() {
+		grp.Wait()
+		close(done)
+	}()
+
+	// Time only passes when advanced, so advance until both warnings are released
+	for stalled := false; !stalled; {
+		select {
+		case <-done:
+			stalled = true
+		case <-time.After(time.Millisecond):
+			clock.Advance(10 * time.Millisecond)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(infos) != 2 {
+		t.Fatal("Expected two stall warnings, got", len(infos))
+	}
+	for _, info := range infos {
+		if info.Since < 20*time.Millisecond || len(info.Runners) != 1 {
+			t.Fatal("Wrong StallInfo", info)
+		}
+		sr := info.Runners[0]
+		if sr.Index != 1 || sr.OutTag != "stuck" || sr.Buffered != 7 || sr.Blocked {
+			t.Error("Wrong StalledRunner", sr)
+		}
+	}
+	if infos[1].Since <= infos[0].Since {
+		t.Error("Expected Since to increase", infos[0].Since, infos[1].Since)
+	}
+
+	for _, opt := range []Option{WithStallWarning(0, fn), WithStallWarning(time.Second, nil)} {
+		_, err = NewGroup(opt)
+		if err == nil {
+			t.Error("Expected error from invalid WithStallWarning")
+		}
+	}
+}
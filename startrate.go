@@ -0,0 +1,51 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// startLimiter is a token bucket which constrains the rate at which workers start
+// runners. The bucket starts full so the first burst of runners start immediately. The
+// mutex is held while waiting for a token so that workers queue up behind each other
+// rather than all waking at once.
+type startLimiter struct {
+	sync.Mutex
+	interval time.Duration // Time to accrue one token
+	burst    float64       // Maximum tokens in the bucket
+	tokens   float64
+	clock    Clock
+	last     time.Time // When tokens was last calculated
+}
+
+func newStartLimiter(interval time.Duration, burst int, clock Clock) *startLimiter {
+	return &startLimiter{interval: interval, burst: float64(burst), tokens: float64(burst),
+		clock: clock, last: clock.Now()}
+}
+
+// wait consumes a token, waiting until one is available or ctx is done.
+func (sl *startLimiter) wait(ctx context.Context) {
+	sl.Lock()
+	defer sl.Unlock()
+
+	now := sl.clock.Now()
+	sl.tokens += float64(now.Sub(sl.last)) / float64(sl.interval)
+	if sl.tokens > sl.burst {
+		sl.tokens = sl.burst
+	}
+	sl.last = now
+	if sl.tokens >= 1 {
+		sl.tokens--
+		return
+	}
+
+	timer := sl.clock.NewTimer(time.Duration((1 - sl.tokens) * float64(sl.interval)))
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		sl.tokens = 0
+		sl.last = sl.clock.Now()
+	case <-ctx.Done(): // The runner will be skipped so it doesn't consume a token
+	}
+}
@@ -0,0 +1,71 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func startTimes(t *testing.T, count int, opts ...Option) []time.Duration {
+	var stdout bytes.Buffer
+	grp, err := NewGroup(append(opts, WithStdout(&stdout))...)
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	var mu sync.Mutex
+	var starts []time.Duration
+	begin := time.Now()
+	for ix := 0; ix < count; ix++ {
+		grp.Add("", "", func(out, err io.Writer) {
+			mu.Lock()
+			starts = append(starts, time.Since(begin))
+			mu.Unlock()
+		})
+	}
+	grp.Run()
+	grp.Wait()
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+
+	return starts
+}
+
+func TestStartDelay(t *testing.T) {
+	delay := time.Millisecond * 20
+	starts := startTimes(t, 4, WithStartDelay(delay))
+	for ix := 1; ix < len(starts); ix++ {
+		if gap := starts[ix] - starts[ix-1]; gap < delay*9/10 {
+			t.Error("Start gap too small", ix, gap)
+		}
+	}
+}
+
+func TestStartRate(t *testing.T) {
+	period := time.Millisecond * 40
+	starts := startTimes(t, 4, WithStartRate(2, period))
+	if starts[1] > period/4 {
+		t.Error("Burst of two should start immediately", starts)
+	}
+	if starts[2] < period*4/10 { // Third start needs a fresh token (period/2)
+		t.Error("Third start was not delayed", starts)
+	}
+
+	for _, opt := range []Option{WithStartDelay(-1), WithStartRate(0, time.Second),
+		WithStartRate(1, 0)} {
+		_, err := NewGroup(opt)
+		if err == nil {
+			t.Error("Expected error from invalid start limit option")
+		}
+	}
+}
+
+func TestStartLimiterCancel(t *testing.T) {
+	sl := newStartLimiter(time.Hour, 1, systemClock{})
+	sl.wait(context.Background()) // Consume the only token
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sl.wait(ctx) // Should return immediately
+}
@@ -0,0 +1,45 @@
+package parallel
+
+import "time"
+
+// RunnerStats contains the statistics recorded for each runner as returned by
+// [Group.Stats].
+type RunnerStats struct {
+	Index     int           // Order in which the runner was added, starting at zero
+	OutTag    string        // As supplied to Add
+	ErrTag    string        // As supplied to Add
+	Skipped   bool          // The RunFunc was never called
+	Started   time.Time     // When the RunFunc was called - zero if Skipped
+	QueueWait time.Duration // Time between Run (or a later Add) and the RunFunc starting
+	Duration  time.Duration // Wall-clock time the RunFunc ran for
+	Stdout    int64         // Bytes written by the RunFunc to stdout
+	Stderr    int64         // Bytes written by the RunFunc to stderr
+}
+
+// Stats returns the statistics of each runner in the order in which they were added to
+// the Group. Stats can only be called after [Group.Wait] has returned. A common use is to
+// sort the results by Duration to report the slowest runners.
+func (grp *Group) Stats() []RunnerStats {
+	grp.checkState(groupIsDone)
+	stats := make([]RunnerStats, 0, len(grp.all))
+	for _, rnr := range grp.runners() {
+		stats = append(stats, rnr.stats())
+	}
+
+	return stats
+}
+
+func (rnr *runner) stats() RunnerStats {
+	if rnr.detached { // Remaining fields may still be changing
+		return RunnerStats{Index: rnr.index, OutTag: string(rnr.outTag),
+			ErrTag: string(rnr.errTag)}
+	}
+	rs := RunnerStats{Index: rnr.index, OutTag: string(rnr.outTag), ErrTag: string(rnr.errTag),
+		Skipped: rnr.skipped, Started: rnr.started, Duration: rnr.duration}
+	rs.Stdout, rs.Stderr = rnr.written()
+	if !rnr.skipped {
+		rs.QueueWait = rnr.started.Sub(rnr.queued)
+	}
+
+	return rs
+}
@@ -0,0 +1,43 @@
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr), LimitActiveRunners(1))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("a", "ae", func(out, err io.Writer) {
+		out.Write([]byte("hello\n"))
+		time.Sleep(time.Millisecond * 20)
+	})
+	grp.Add("b", "be", func(out, err io.Writer) {
+		err.Write([]byte("oops\n"))
+	})
+	grp.Run()
+	grp.Wait()
+
+	stats := grp.Stats()
+	if len(stats) != 2 {
+		t.Fatal("Expected two stats, got", len(stats))
+	}
+	a, b := stats[0], stats[1]
+	if a.Index != 0 || a.OutTag != "a" || a.ErrTag != "ae" || a.Stdout != 6 || a.Stderr != 0 {
+		t.Error("Wrong stats for a", a)
+	}
+	if b.Index != 1 || b.Stdout != 0 || b.Stderr != 5 {
+		t.Error("Wrong stats for b", b)
+	}
+	if a.Duration < time.Millisecond*20 || a.Started.IsZero() {
+		t.Error("a should have run for at least 20ms", a.Duration)
+	}
+	if b.QueueWait < time.Millisecond*20 { // Had to wait for a with LimitActiveRunners(1)
+		t.Error("b should have queued for at least 20ms", b.QueueWait)
+	}
+}
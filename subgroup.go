@@ -0,0 +1,61 @@
+package parallel
+
+import (
+	"errors"
+	"io"
+)
+
+// NewSubGroup creates a child Group which writes to the stdout and stderr io.Writers
+// supplied to a RunFunc of this Group. It formalises the pattern of nesting Groups within
+// RunFuncs as shown in _examples/nested.go:
+//
+//	group.Add("", "", func(out, err io.Writer) {
+//		child, _ := group.NewSubGroup(out, err)
+//		...
+//		child.Run()
+//		child.Wait()
+//	})
+//
+// The child inherits those options of this Group which govern how runners are scheduled
+// and buffered: [OrderRunners], [OrderStderr], [Passthru], [Ungroup], [MergeStderr],
+// [LimitActiveRunners], [LimitActiveRunnersPerCPU], [LimitActiveRunnersAuto],
+// [LimitMemoryPerRunner], [SoftLimitMemoryPerRunner], [WithSpillDir],
+// [WithCoalesceLimit], [WithCompressedBuffering], [WithExpectedOutputSize],
+// [WithLineDelimiter], [WithLogger], [WithTracer] and [WithClock].
+//
+// No other options are inherited. Options which decorate or encode output, such as tag
+// colors, separators, footers, section headers, line filters, JSON, framing and
+// compression, are already applied to the output of the parent runner. Options which
+// report on or control the Group as a whole, such as progress, job logs, hooks, halt
+// policies, signal handling, results directories and resumption, apply to the parent
+// Group only. Any opts are applied after the inherited options so they can override them.
+//
+// NewSubGroup can be called at any time, but is normally called from within a RunFunc.
+func (grp *Group) NewSubGroup(stdout, stderr io.Writer, opts ...Option) (*Group, error) {
+	if stdout == nil || stderr == nil {
+		return nil, errors.New("Cannot supply nil io.Writer to NewSubGroup")
+	}
+	cfg := newConfig()
+	cfg.stdout, cfg.stderr = stdout, stderr
+	cfg.orderRunners = grp.orderRunners
+	cfg.orderStderr = grp.orderStderr
+	cfg.passthru = grp.passthru
+	cfg.ungroup = grp.ungroup
+	cfg.mergeStderr = grp.mergeStderr
+	cfg.limitRunners = grp.limitRunners
+	cfg.autoRunners = grp.autoRunners
+	cfg.cpuFactor = grp.cpuFactor
+	cfg.limitMemory = grp.limitMemory
+	cfg.softMemory = grp.softMemory
+	cfg.throttleDelay = grp.throttleDelay
+	cfg.spillDir = grp.spillDir
+	cfg.coalesce = grp.coalesce
+	cfg.compressBuffers = grp.compressBuffers
+	cfg.expectedOutput = grp.expectedOutput
+	cfg.delim = grp.delim
+	cfg.logger = grp.logger
+	cfg.tracer = grp.tracer
+	cfg.clock = grp.clock
+
+	return newGroup(cfg, opts...)
+}
@@ -0,0 +1,55 @@
+package parallel
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNewSubGroup(t *testing.T) {
+	var stdout testLockedBuffer
+	grp, err := NewGroup(WithStdout(&stdout), LimitActiveRunners(3),
+		LimitMemoryPerRunner(100), WithLineDelimiter(';'), MergeStderr(true),
+		WithStdoutSeparator("--\n"))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	var child *Group
+	grp.Add("p:", "", func(out, err io.Writer) {
+		var e error
+		child, e = grp.NewSubGroup(out, err, OrderRunners(false))
+		if e != nil {
+			t.Error("Unexpected NewSubGroup error", e)
+			return
+		}
+		for _, tag := range []string{"a", "b"} {
+			child.Add(tag+":", "", func(out, err io.Writer) { out.Write([]byte("x;")) })
+		}
+		child.Run()
+		child.Wait()
+	})
+	grp.Run()
+	grp.Wait()
+
+	if child == nil {
+		t.Fatal("Child Group not created")
+	}
+	if child.limitRunners != 3 || child.limitMemory != 100 || child.delim != ';' ||
+		!child.mergeStderr {
+		t.Error("Options not inherited", child.limitRunners, child.limitMemory, child.delim)
+	}
+	if child.orderRunners {
+		t.Error("Override option not applied")
+	}
+	if len(child.outSep) != 0 {
+		t.Error("Separator should not be inherited")
+	}
+	got := stdout.String()
+	if !strings.Contains(got, "p:a:x;") || !strings.Contains(got, "p:b:x;") {
+		t.Errorf("Child output not written thru parent runner %q", got)
+	}
+
+	if _, err := grp.NewSubGroup(nil, io.Discard); err == nil {
+		t.Error("Expected error with nil io.Writer")
+	}
+}
@@ -5,25 +5,24 @@ import (
 	"sync"
 )
 
-// tagger is a writer which prepends the tag string to each line terminated with "\n" and
-// writes it to the next writer in the pipeline. No data is buffered in this writer, only
+// tagger is a writer which prepends the tag string to each line terminated with delim,
+// normally "\n", and writes it to the next writer in the pipeline. No data is buffered in this writer, only
 // state information pertaining to tag insertion is tracked.
 type tagger struct {
 	mu sync.Mutex
 	commonWriter
 	tag        []byte
+	delim      []byte // Line delimiter - see WithLineDelimiter
 	tagPending bool
 }
 
-func newTagger(out writer, tag []byte) *tagger {
-	wtr := &tagger{tag: tag, tagPending: true}
+func newTagger(out writer, tag []byte, delim byte) *tagger {
+	wtr := &tagger{tag: tag, delim: []byte{delim}, tagPending: true}
 	wtr.setNext(out)
 
 	return wtr
 }
 
-var nl = []byte{'\n'}
-
 // Write prepends tag to each output line. The tag is prepended as soon as a non-empty
 // line is known to exist, even if it does not yet have a trailing "\n".
 //
@@ -53,7 +52,17 @@ func (wtr *tagger) Write(p []byte) (n int, err error) {
 	wtr.mu.Lock() // Protect our local writer state
 	defer wtr.mu.Unlock()
 
-	lines := bytes.Split(p, nl)
+	// If the next writer supports vectored writes, gather the tags, lines and
+	// delimiters so that they are written with a single call rather than 3+ per line.
+	if vw, ok := wtr.out.(vectorWriter); ok {
+		_, err = vw.writeVec(wtr.gather(nil, p))
+		if err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	lines := bytes.Split(p, wtr.delim)
 	for ix := 0; ix < len(lines)-1; ix++ { // Process allbut the last line
 		if wtr.tagPending {
 			_, e := wtr.out.Write(wtr.tag) // W2: Bytes not returned for tag
@@ -70,8 +79,8 @@ func (wtr *tagger) Write(p []byte) (n int, err error) {
 		}
 		n += b // Bytes written is always returned for user data
 
-		b, e = wtr.out.Write(nl)    // W4: NL
-		if e != nil && err == nil { // First error is always returned
+		b, e = wtr.out.Write(wtr.delim) // W4: NL
+		if e != nil && err == nil {     // First error is always returned
 			err = e
 		}
 		n += b // Bytes written is always returned for user data
@@ -106,6 +115,67 @@ func (wtr *tagger) Write(p []byte) (n int, err error) {
 	return
 }
 
+// gather appends the tags, lines and delimiters which would otherwise be written
+// individually by Write to bufs and returns the extended slice. Tag state is updated as if
+// the data has been written. Caller must hold wtr.mu.
+func (wtr *tagger) gather(bufs [][]byte, p []byte) [][]byte {
+	lines := bytes.Split(p, wtr.delim)
+	for ix := 0; ix < len(lines)-1; ix++ {
+		if wtr.tagPending {
+			bufs = append(bufs, wtr.tag)
+		}
+		wtr.tagPending = true
+		bufs = append(bufs, lines[ix], wtr.delim)
+	}
+
+	if ln := lines[len(lines)-1]; len(ln) > 0 { // Same last line logic as Write
+		if wtr.tagPending {
+			bufs = append(bufs, wtr.tag)
+		}
+		bufs = append(bufs, ln)
+		wtr.tagPending = false
+	} else {
+		wtr.tagPending = true
+	}
+
+	return bufs
+}
+
+// writeVec tags all bufs and passes them on as a single vectored write if the next writer
+// supports it, otherwise each buffer is written in turn.
+func (wtr *tagger) writeVec(bufs [][]byte) (n int64, err error) {
+	vw, ok := wtr.out.(vectorWriter)
+	if !ok {
+		for _, p := range bufs {
+			b, e := wtr.Write(p)
+			n += int64(b)
+			if e != nil {
+				return n, e
+			}
+		}
+		return
+	}
+	if len(wtr.tag) == 0 {
+		return vw.writeVec(bufs)
+	}
+
+	wtr.mu.Lock()
+	defer wtr.mu.Unlock()
+
+	var tagged [][]byte
+	for _, p := range bufs {
+		if len(p) > 0 {
+			tagged = wtr.gather(tagged, p)
+			n += int64(len(p))
+		}
+	}
+	if _, err = vw.writeVec(tagged); err != nil {
+		return 0, err
+	}
+
+	return
+}
+
 func (wtr *tagger) close() {
 	wtr.out.close() // pass it on
 }
@@ -8,7 +8,7 @@ import (
 // Test that an unconfigured tagger writer does not modify the data stream
 func TestTaggerEmpty(t *testing.T) {
 	var buf testBufWriter
-	wtr := newTagger(&buf, []byte{})
+	wtr := newTagger(&buf, []byte{}, '\n')
 
 	exp := "Line 1\nLine 2\nLine 3\n"
 	b, e := wtr.Write([]byte(exp))
@@ -28,7 +28,7 @@ func TestTaggerEmpty(t *testing.T) {
 // Prepend with a whole line data
 func TestTaggerSimple(t *testing.T) {
 	var buf testBufWriter
-	wtr := newTagger(&buf, []byte("host1: "))
+	wtr := newTagger(&buf, []byte("host1: "), '\n')
 
 	before := "Line 1\nLine 2\n"
 	exp := "host1: Line 1\nhost1: Line 2\n"
@@ -49,7 +49,7 @@ func TestTaggerSimple(t *testing.T) {
 // Test tagger prepend logic with no trailing newline
 func TestTaggerNoTrailingNL(t *testing.T) {
 	var buf testBufWriter
-	wtr := newTagger(&buf, []byte("host1: "))
+	wtr := newTagger(&buf, []byte("host1: "), '\n')
 
 	before := "Line 1\nXX"
 	exp := "host1: Line 1\nhost1: XX"
@@ -70,7 +70,7 @@ func TestTaggerNoTrailingNL(t *testing.T) {
 // Prepend with partial lines
 func TestTaggerPartialWrites(t *testing.T) {
 	var buf testBufWriter
-	wtr := newTagger(&buf, []byte("host1: "))
+	wtr := newTagger(&buf, []byte("host1: "), '\n')
 
 	before := []byte("Line 1\nLine2 \nLine 3\nLine 4\n")
 	exp := "host1: Line 1\nhost1: Line2 \nhost1: Line 3\nhost1: Line 4\n"
@@ -93,7 +93,7 @@ func TestTaggerPartialWrites(t *testing.T) {
 // Zero-length writes
 func TestTaggerWriteZero(t *testing.T) {
 	var buf testBufWriter
-	wtr := newTagger(&buf, []byte("host1: "))
+	wtr := newTagger(&buf, []byte("host1: "), '\n')
 
 	before := []byte("")
 	exp := ""
@@ -114,7 +114,7 @@ func TestTaggerWriteZero(t *testing.T) {
 // Data is zero length
 func TestTaggerIOErrorsW0(t *testing.T) {
 	buf := &testTruncateWriter{}
-	wtr := newTagger(buf, []byte(""))
+	wtr := newTagger(buf, []byte(""), '\n')
 	b, err := wtr.Write([]byte{})
 	if b != 0 || err != nil {
 		t.Error("Expected zero and nil, not", b, err)
@@ -125,7 +125,7 @@ func TestTaggerIOErrorsW0(t *testing.T) {
 func TestTaggerIOErrorsW1(t *testing.T) {
 	buf := &testTruncateWriter{}
 	buf.append("Error on first write", -1, errors.New("W1 error"))
-	wtr := newTagger(buf, []byte(""))
+	wtr := newTagger(buf, []byte(""), '\n')
 	b, err := wtr.Write([]byte{'a'})
 	if b != 1 || err == nil {
 		t.Error("Expected one and error, not", b)
@@ -135,7 +135,7 @@ func TestTaggerIOErrorsW1(t *testing.T) {
 // Error with tag pending on first line
 func TestTaggerIOErrorsW2(t *testing.T) {
 	buf := &testTruncateWriter{}
-	wtr := newTagger(buf, []byte("W2Tag"))
+	wtr := newTagger(buf, []byte("W2Tag"), '\n')
 	buf.append("Error on first write", 0, errors.New("Tag Error"))
 	b, err := wtr.Write([]byte{'a', '\n', 'b', '\n'})
 	if b != 4 { // User bytes written is unaffected
@@ -149,7 +149,7 @@ func TestTaggerIOErrorsW2(t *testing.T) {
 // Error on writing of first line
 func TestTaggerIOErrorsW3(t *testing.T) {
 	buf := &testTruncateWriter{}
-	wtr := newTagger(buf, []byte("W3Tag"))
+	wtr := newTagger(buf, []byte("W3Tag"), '\n')
 	buf.append("W1 is good", -1, nil)
 	buf.append("W3 is error", -1, errors.New("W3 error"))
 
@@ -166,7 +166,7 @@ func TestTaggerIOErrorsW3(t *testing.T) {
 // Error on writing NL between split lines
 func TestTaggerIOErrorsW4(t *testing.T) {
 	buf := &testTruncateWriter{}
-	wtr := newTagger(buf, []byte("W4Tag"))
+	wtr := newTagger(buf, []byte("W4Tag"), '\n')
 	buf.append("W2Tag", -1, nil)
 	buf.append("W3Line 1 'abcd'", -1, nil)
 	buf.append("W4 NL fails", 0, errors.New("W4 NL failed"))
@@ -184,7 +184,7 @@ func TestTaggerIOErrorsW4(t *testing.T) {
 // Error on writing tag on last line
 func TestTaggerIOErrorsW5(t *testing.T) {
 	buf := &testTruncateWriter{}
-	wtr := newTagger(buf, []byte("W5Tag"))
+	wtr := newTagger(buf, []byte("W5Tag"), '\n')
 	buf.append("W5 Tag fails", 2, errors.New("W5 Tag failed"))
 
 	data := []byte{'a', 'b', 'c'} // Last line
@@ -200,7 +200,7 @@ func TestTaggerIOErrorsW5(t *testing.T) {
 // Error on writing data on last line
 func TestTaggerIOErrorsW6(t *testing.T) {
 	buf := &testTruncateWriter{}
-	wtr := newTagger(buf, []byte("W5Tag"))
+	wtr := newTagger(buf, []byte("W5Tag"), '\n')
 	buf.append("W5 Tag ok", -1, nil)
 	buf.append("W6 LL fails", 2, errors.New("W6 LL failed"))
 
@@ -11,10 +11,14 @@ import (
 // has a "next" writer so getting, setting and closing functions are all no-ops.
 //
 // Most importantly, tail protects the Group output writers from concurrent access by all
-// runners within the Group via a group-wide mutex.
+// runners within the Group via a group-wide mutex. If outputMu is nil, the caller has
+// arranged for the mutex to be held further up the pipeline (see serialiser).
 type tail struct {
 	out      io.Writer
 	outputMu *sync.Mutex
+	onErr    func(error)  // Optionally called with each Write error
+	prelude  func()       // Optionally called with outputMu held prior to writing output
+	limit    *rateLimiter // Only set if WithOutputRateLimit is set
 }
 
 func newTail(out io.Writer, outputMu *sync.Mutex) *tail {
@@ -26,7 +30,50 @@ func (wtr *tail) setNext(writer)  {}
 func (wtr *tail) close()          {}
 
 func (wtr *tail) Write(p []byte) (n int, err error) {
-	wtr.outputMu.Lock()
-	defer wtr.outputMu.Unlock()
-	return wtr.out.Write(p)
+	if wtr.outputMu != nil {
+		wtr.outputMu.Lock()
+		defer wtr.outputMu.Unlock()
+	}
+	if len(p) > 0 && wtr.prelude != nil {
+		wtr.prelude()
+	}
+	if wtr.limit != nil {
+		n, err = wtr.limit.write(wtr.out.Write, p)
+	} else {
+		n, err = wtr.out.Write(p)
+	}
+	if err != nil && wtr.onErr != nil {
+		wtr.onErr(err)
+	}
+
+	return
+}
+
+// writeVec writes all bufs to the Group io.Writer with a single vectored write where
+// possible - see writeBuffers - unless the output is rate limited.
+func (wtr *tail) writeVec(bufs [][]byte) (n int64, err error) {
+	if wtr.outputMu != nil {
+		wtr.outputMu.Lock()
+		defer wtr.outputMu.Unlock()
+	}
+	if wtr.prelude != nil && len(bufs) > 0 {
+		wtr.prelude()
+	}
+	if wtr.limit != nil { // Syscall efficiency is moot once rate limited
+		for _, b := range bufs {
+			var c int
+			c, err = wtr.limit.write(wtr.out.Write, b)
+			n += int64(c)
+			if err != nil {
+				break
+			}
+		}
+	} else {
+		n, err = writeBuffers(wtr.out, bufs)
+	}
+	if err != nil && wtr.onErr != nil {
+		wtr.onErr(err)
+	}
+
+	return
 }
@@ -0,0 +1,153 @@
+package parallel
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// TeeError records a failure to write to a [WithStdoutTee] or [WithStderrTee] sink. Once
+// a sink fails it is written no more, but the Group io.Writer and all other sinks continue
+// to be written. Each TeeError is included in the error returned by [Group.Wait].
+type TeeError struct {
+	Stream Stream // Of the Group io.Writer the sink duplicates
+	Sink   int    // Index of the sink as supplied to the option, starting at zero
+	Err    error  // As returned by the sink
+}
+
+func (te *TeeError) Error() string {
+	return fmt.Sprintf("parallel: %s tee sink %d failed: %v", te.Stream, te.Sink, te.Err)
+}
+
+func (te *TeeError) Unwrap() error {
+	return te.Err
+}
+
+// teeSink is a single sink of a teeWriter along with its sticky error.
+type teeSink struct {
+	w   io.Writer
+	err *TeeError
+}
+
+// teeWriter replaces a Group io.Writer for WithStdoutTee and WithStderrTee. Each Write is
+// made to the Group io.Writer, whose results are returned, and then duplicated to each
+// sink which has not yet failed. teeWriter is only called under the protection of the
+// Group output mutex or by the WithAsyncOutput goroutine, but it has its own mutex as
+// errs may be called concurrently by Wait.
+type teeWriter struct {
+	mu    sync.Mutex
+	out   io.Writer
+	sinks []*teeSink
+}
+
+func newTeeWriter(out io.Writer, stream Stream, sinks ...io.Writer) *teeWriter {
+	tw := &teeWriter{out: out}
+	for ix, w := range sinks {
+		tw.sinks = append(tw.sinks, &teeSink{w: w, err: &TeeError{Stream: stream, Sink: ix}})
+	}
+
+	return tw
+}
+
+func (tw *teeWriter) Write(p []byte) (int, error) {
+	n, err := tw.out.Write(p)
+
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	for _, s := range tw.sinks {
+		if s.err.Err == nil {
+			m, e := s.w.Write(p[:n]) // Only what the Group io.Writer accepted
+			if e == nil && m < n {
+				e = io.ErrShortWrite
+			}
+			s.err.Err = e
+		}
+	}
+
+	return n, err
+}
+
+// writeVec retains the vectored write to the Group io.Writer and duplicates it to each
+// sink. writeBuffers consumes its argument so each destination is given its own copy.
+func (tw *teeWriter) writeVec(bufs [][]byte) (int64, error) {
+	n, err := writeBuffers(tw.out, append([][]byte(nil), bufs...))
+
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	for _, s := range tw.sinks {
+		if s.err.Err == nil {
+			_, s.err.Err = writeBuffers(s.w, append([][]byte(nil), bufs...))
+		}
+	}
+
+	return n, err
+}
+
+// Flush flushes the Group io.Writer and any sinks which buffer their output so that
+// WithFlushInterval still reaches them.
+func (tw *teeWriter) Flush() (err error) {
+	if f, ok := tw.out.(flusher); ok {
+		err = f.Flush()
+	}
+
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	for _, s := range tw.sinks {
+		if f, ok := s.w.(flusher); ok && s.err.Err == nil {
+			s.err.Err = f.Flush()
+		}
+	}
+
+	return
+}
+
+// errs returns the errors of all failed sinks.
+func (tw *teeWriter) errs() (errs []error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	for _, s := range tw.sinks {
+		if s.err.Err != nil {
+			errs = append(errs, s.err)
+		}
+	}
+
+	return
+}
+
+// teeWriters replaces the Group io.Writers with teeWriters for WithStdoutTee and
+// WithStderrTee. If stdout and stderr are the same io.Writer, all sinks receive the one
+// combined stream. Caller must hold grp.mu.
+func (grp *Group) teeWriters() {
+	grp.unbuffered = grp.writers()
+	if len(grp.unbuffered) == 1 {
+		tw := newTeeWriter(grp.stdout, Stdout, grp.stdoutTee...)
+		for ix, w := range grp.stderrTee {
+			tw.sinks = append(tw.sinks, &teeSink{w: w, err: &TeeError{Stream: Stderr,
+				Sink: ix}})
+		}
+		grp.tees = append(grp.tees, tw)
+		grp.stdout, grp.stderr = tw, tw
+		return
+	}
+	if len(grp.stdoutTee) > 0 {
+		tw := newTeeWriter(grp.stdout, Stdout, grp.stdoutTee...)
+		grp.tees = append(grp.tees, tw)
+		grp.stdout = tw
+	}
+	if len(grp.stderrTee) > 0 {
+		tw := newTeeWriter(grp.stderr, Stderr, grp.stderrTee...)
+		grp.tees = append(grp.tees, tw)
+		grp.stderr = tw
+	}
+}
+
+// teeErr returns the errors of all failed tee sinks.
+func (grp *Group) teeErr() error {
+	var errs []error
+	for _, tw := range grp.tees {
+		errs = append(errs, tw.errs()...)
+	}
+
+	return errors.Join(errs...)
+}
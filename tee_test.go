@@ -0,0 +1,72 @@
+package parallel
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestTee(t *testing.T) {
+	for _, opt := range []Option{WithStdoutTee(nil), WithStderrTee(&bytes.Buffer{}, nil)} {
+		if _, err := NewGroup(opt); err == nil {
+			t.Error("Expected error with nil sink")
+		}
+	}
+
+	var stdout, stderr, outLog, errLog bytes.Buffer
+	failing := &testTruncateWriter{}
+	failing.append("fail", 0, errors.New("sink gone"))
+	grp, err := NewGroup(WithStdout(&stdout), WithStderr(&stderr),
+		WithStdoutTee(&outLog, failing), WithStderrTee(&errLog),
+		WithStdoutSeparator("--\n"))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	for _, tag := range []string{"a: ", "b: "} {
+		grp.Add(tag, tag, func(out, err io.Writer) {
+			out.Write([]byte("out\n"))
+			err.Write([]byte("err\n"))
+		})
+	}
+	grp.Run()
+	err = grp.Wait()
+
+	if stdout.String() != outLog.String() || stderr.String() != errLog.String() {
+		t.Errorf("Tee differs\nGot %q %q\nExp %q %q", outLog.String(), errLog.String(),
+			stdout.String(), stderr.String())
+	}
+	if stdout.String() != "a: out\n--\nb: out\n" {
+		t.Errorf("Wrong stdout %q", stdout.String())
+	}
+	if failing.String() != "" || failing.index != 1 {
+		t.Error("A failed sink should be written no more", failing.index)
+	}
+	var te *TeeError
+	if !errors.As(err, &te) || te.Stream != Stdout || te.Sink != 1 ||
+		te.Err.Error() != "sink gone" {
+		t.Error("Expected stdout TeeError for sink 1, got", err)
+	}
+}
+
+// When stdout and stderr are the same io.Writer, all sinks see the combined stream.
+func TestTeeCombined(t *testing.T) {
+	var out, outLog, errLog bytes.Buffer
+	grp, err := NewGroup(WithStdout(&out), WithStderr(&out), WithStdoutTee(&outLog),
+		WithStderrTee(&errLog), WithBufferedOutput(64))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	grp.Add("o ", "e ", func(stdout, stderr io.Writer) {
+		stdout.Write([]byte("1\n"))
+		stderr.Write([]byte("2\n"))
+	})
+	grp.Run()
+	if err := grp.Wait(); err != nil {
+		t.Error("Unexpected Wait error", err)
+	}
+	exp := "o 1\ne 2\n"
+	if out.String() != exp || outLog.String() != exp || errLog.String() != exp {
+		t.Errorf("Wrong output %q %q %q", out.String(), outLog.String(), errLog.String())
+	}
+}
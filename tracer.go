@@ -0,0 +1,65 @@
+package parallel
+
+import (
+	"context"
+	"strings"
+)
+
+// Tracer creates a span for each runner when set with [WithTracer]. It is deliberately
+// minimal so that this package has no dependency on any particular tracing library. For
+// example, an OpenTelemetry trace.Tracer is adapted with:
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) StartSpan(ctx context.Context, name string) (context.Context, parallel.Span) {
+//	    ctx, span := t.Start(ctx, name)
+//	    return ctx, otelSpan{span}
+//	}
+//
+//	type otelSpan struct{ span trace.Span }
+//
+//	func (s otelSpan) SetAttribute(key string, value any) {
+//	    s.span.SetAttributes(attribute.String(key, fmt.Sprint(value)))
+//	}
+//
+//	func (s otelSpan) End() { s.span.End() }
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single runner span created by a [Tracer].
+type Span interface {
+	SetAttribute(key string, value any)
+	End()
+}
+
+// spanName is the name of every runner span.
+const spanName = "parallel.runner"
+
+// startSpan starts the runner span as a child of ctx and returns the span context which
+// becomes the parent of the RunFunc context so that any spans created by a [RunFuncCtx]
+// nest within the runner span.
+func (rnr *runner) startSpan(tracer Tracer, ctx context.Context) context.Context {
+	ctx, rnr.span = tracer.StartSpan(ctx, spanName)
+	rnr.span.SetAttribute("parallel.index", rnr.index)
+	rnr.span.SetAttribute("parallel.tag", strings.TrimSpace(string(rnr.outTag)))
+
+	return ctx
+}
+
+// endSpan records the outcome of the runner and ends its span, if any. It is called once
+// all output has been flushed.
+func (rnr *runner) endSpan() {
+	if rnr.span == nil {
+		return
+	}
+	res := rnr.result()
+	stdout, stderr := rnr.written()
+	rnr.span.SetAttribute("parallel.stdout_bytes", stdout)
+	rnr.span.SetAttribute("parallel.stderr_bytes", stderr)
+	rnr.span.SetAttribute("parallel.outcome", res.Outcome.String())
+	if res.Err != nil {
+		rnr.span.SetAttribute("parallel.error", res.Err.Error())
+	}
+	rnr.span.End()
+}
@@ -0,0 +1,73 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+type testSpanKey struct{}
+
+type testSpan struct {
+	name  string
+	attrs map[string]any
+	ended bool
+}
+
+func (ts *testSpan) SetAttribute(key string, value any) { ts.attrs[key] = value }
+func (ts *testSpan) End()                               { ts.ended = true }
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (tt *testTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	ts := &testSpan{name: name, attrs: make(map[string]any)}
+	tt.spans = append(tt.spans, ts)
+
+	return context.WithValue(ctx, testSpanKey{}, ts), ts
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &testTracer{}
+	grp, err := NewGroup(WithStdout(io.Discard), WithTracer(tracer), LimitActiveRunners(1))
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	var parent any
+	grp.AddContextErr(" a ", "", func(ctx context.Context, out, err io.Writer) error {
+		parent = ctx.Value(testSpanKey{})
+		out.Write([]byte("hello\n"))
+		return errors.New("failed")
+	})
+	grp.Run()
+	grp.Wait()
+
+	if len(tracer.spans) != 1 {
+		t.Fatal("Expected one span, got", len(tracer.spans))
+	}
+	ts := tracer.spans[0]
+	if !ts.ended || ts.name != spanName {
+		t.Error("Span not ended or wrong name", ts.name)
+	}
+	if parent != ts {
+		t.Error("RunFunc context is not a child of the runner span")
+	}
+	for key, value := range map[string]any{"parallel.index": 0, "parallel.tag": "a",
+		"parallel.stdout_bytes": int64(6), "parallel.stderr_bytes": int64(0),
+		"parallel.outcome": "completed", "parallel.error": "failed"} {
+		if ts.attrs[key] != value {
+			t.Error("Attribute", key, "expected", value, "got", ts.attrs[key])
+		}
+	}
+
+	_, err = NewGroup(WithTracer(nil))
+	if err == nil {
+		t.Error("Expected error from WithTracer(nil)")
+	}
+}
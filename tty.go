@@ -0,0 +1,42 @@
+package parallel
+
+import (
+	"io"
+	"os"
+)
+
+// TTYMode determines whether a presentation option, such as [WithTagColors], is active
+// depending on whether its destination is a terminal, much like the “--color=auto”
+// option of many commands.
+type TTYMode int
+
+const (
+	TTYAuto   TTYMode = iota // Active only if the destination is a terminal
+	TTYAlways                // Always active
+	TTYNever                 // Never active
+)
+
+// enabled returns true if the mode allows a presentation option writing to w.
+func (mode TTYMode) enabled(w io.Writer) bool {
+	switch mode {
+	case TTYAlways:
+		return true
+	case TTYNever:
+		return false
+	}
+
+	return IsTerminal(w)
+}
+
+// IsTerminal returns true if the io.Writer is an *os.File connected to a terminal. Any
+// other type of io.Writer is assumed not to be a terminal. This is the test used by
+// [TTYAuto] and is exported so that callers can make consistent decisions about their own
+// presentation.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || f == nil {
+		return false
+	}
+
+	return isTerminalFile(f)
+}
@@ -0,0 +1,26 @@
+package parallel
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// isTerminalFile uses the same technique as isatty(3) - a terminal is any file
+// descriptor which responds to a request for its termios settings. SyscallConn is used
+// rather than Fd as the latter has the side-effect of setting the file to blocking mode.
+func isTerminalFile(f *os.File) bool {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	var errno syscall.Errno
+	err = rc.Control(func(fd uintptr) {
+		var termios syscall.Termios
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCGETS,
+			uintptr(unsafe.Pointer(&termios)))
+	})
+
+	return err == nil && errno == 0
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package parallel
+
+import (
+	"os"
+)
+
+// isTerminalFile approximates isatty(3) on platforms without a Linux-style TCGETS ioctl
+// by checking whether the file is a character device. This can give false positives for
+// devices such as /dev/null.
+func isTerminalFile(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,33 @@
+package parallel
+
+import (
+	"io"
+	"net"
+	"os"
+)
+
+// vectorWriter is implemented by writers which can write multiple buffers with a single
+// call, such as by way of writev(2). Upstream writers which would otherwise issue
+// multiple small Writes for one inbound Write, such as tagger, gather their buffers and
+// use writeVec when their downstream writer supports it.
+type vectorWriter interface {
+	writeVec(bufs [][]byte) (int64, error)
+}
+
+// writeBuffers writes all of bufs to w with as few system calls as possible. An *os.File
+// is written with writev(2) where supported, a vectorWriter is passed bufs as is and
+// other io.Writers are written via
+// net.Buffers which uses vectored writes for network connections.
+func writeBuffers(w io.Writer, bufs [][]byte) (int64, error) {
+	if vw, ok := w.(vectorWriter); ok {
+		return vw.writeVec(bufs)
+	}
+	if f, ok := w.(*os.File); ok {
+		if n, err, ok := writevFile(f, bufs); ok {
+			return n, err
+		}
+	}
+	nb := net.Buffers(bufs)
+
+	return nb.WriteTo(w)
+}
@@ -0,0 +1,80 @@
+package parallel
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// maxIovecs is the smallest IOV_MAX on Linux. Longer vectors are written in batches.
+const maxIovecs = 1024
+
+// writevFile writes bufs to f with writev(2), handling short writes and non-blocking
+// files. It returns false if f cannot be written this way, in which case nothing has
+// been written.
+func writevFile(f *os.File, bufs [][]byte) (n int64, err error, ok bool) {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return 0, nil, false
+	}
+
+	iovecs := make([]syscall.Iovec, 0, min(len(bufs), maxIovecs))
+	werr := rc.Write(func(fd uintptr) bool {
+		for {
+			iovecs = iovecs[:0]
+			for _, b := range bufs {
+				if len(b) == 0 {
+					continue
+				}
+				if len(iovecs) == maxIovecs {
+					break
+				}
+				iov := syscall.Iovec{Base: &b[0]}
+				iov.SetLen(len(b))
+				iovecs = append(iovecs, iov)
+			}
+			if len(iovecs) == 0 {
+				return true
+			}
+			r, _, errno := syscall.Syscall(syscall.SYS_WRITEV, fd,
+				uintptr(unsafe.Pointer(&iovecs[0])), uintptr(len(iovecs)))
+			switch errno {
+			case 0:
+			case syscall.EINTR:
+				continue
+			case syscall.EAGAIN:
+				return false // Wait until writable and try again
+			default:
+				err = errno
+				return true
+			}
+			n += int64(r)
+			bufs = consume(bufs, int64(r))
+		}
+	})
+	runtime.KeepAlive(bufs)
+	if err == nil {
+		err = werr
+	}
+	if err != nil {
+		err = &os.PathError{Op: "writev", Path: f.Name(), Err: err}
+	}
+
+	return n, err, true
+}
+
+// consume removes the first n bytes from bufs.
+func consume(bufs [][]byte, n int64) [][]byte {
+	for len(bufs) > 0 {
+		l := int64(len(bufs[0]))
+		if l > n {
+			bufs[0] = bufs[0][n:]
+			break
+		}
+		n -= l
+		bufs = bufs[1:]
+	}
+
+	return bufs
+}
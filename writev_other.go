@@ -0,0 +1,13 @@
+//go:build !linux
+
+package parallel
+
+import (
+	"os"
+)
+
+// writevFile always returns false on platforms without writev(2) support so that the
+// caller falls back to sequential writes.
+func writevFile(f *os.File, bufs [][]byte) (n int64, err error, ok bool) {
+	return 0, nil, false
+}
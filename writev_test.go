@@ -0,0 +1,94 @@
+package parallel
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// A writer which records each vectored write as a single string
+type testVecWriter struct {
+	testBufWriter
+	vecs []string
+	err  error
+}
+
+func (tvw *testVecWriter) writeVec(bufs [][]byte) (int64, error) {
+	if tvw.err != nil {
+		return 0, tvw.err
+	}
+	p := bytes.Join(bufs, nil)
+	tvw.vecs = append(tvw.vecs, string(p))
+	n, err := tvw.Write(p)
+
+	return int64(n), err
+}
+
+// writev must cope with empty buffers and vectors longer than IOV_MAX
+func TestWriteBuffersFile(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal("Unexpected setup error", err)
+	}
+	defer r.Close()
+
+	var bufs [][]byte
+	var exp strings.Builder
+	for ix := range 3000 {
+		s := strings.Repeat("x", ix%7) + "\n"
+		bufs = append(bufs, []byte(s), nil)
+		exp.WriteString(s)
+	}
+	done := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		done <- string(b)
+	}()
+	n, err := writeBuffers(w, bufs)
+	w.Close()
+	if err != nil {
+		t.Error("Unexpected error", err)
+	}
+	if n != int64(exp.Len()) {
+		t.Error("Wrong byte count", n, exp.Len())
+	}
+	if got := <-done; got != exp.String() {
+		t.Error("Pipe content differs from buffers", len(got), exp.Len())
+	}
+}
+
+// A tagger should issue a single vectored write per Write when the next writer allows it
+func TestTaggerVector(t *testing.T) {
+	var vw testVecWriter
+	wtr := newTagger(&vw, []byte("t: "), '\n')
+	wtr.Write([]byte("a\nb\nc"))
+	wtr.Write([]byte("d\n"))
+	if len(vw.vecs) != 2 || vw.vecs[0] != "t: a\nt: b\nt: c" || vw.vecs[1] != "d\n" {
+		t.Errorf("Wrong vectored writes %q", vw.vecs)
+	}
+
+	vw.err = errors.New("Vector failed")
+	if n, err := wtr.Write([]byte("e\n")); n != 0 || err == nil {
+		t.Error("Expected vector error to be returned", n, err)
+	}
+}
+
+// Draining should gather consecutive chunks for the same destination into one write
+func TestQueueTransferVector(t *testing.T) {
+	ob := &testVecWriter{}
+	outQ, errQ := newQueue(false, 0, ob, ob)
+	outQ.cq.buf.coalesce = 2
+	outQ.Write([]byte("ab"))
+	outQ.Write([]byte("cd"))
+	errQ.Write([]byte("E"))
+	outQ.Write([]byte("ef"))
+	outQ.foreground()
+
+	exp := []string{"abcd", "E", "ef"}
+	if strings.Join(ob.vecs, ",") != strings.Join(exp, ",") {
+		t.Errorf("Wrong vectored writes %q", ob.vecs)
+	}
+}